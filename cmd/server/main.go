@@ -3,16 +3,22 @@ package main
 import (
 	"context"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"pr-reviewer-service/internal/chat"
 	"pr-reviewer-service/internal/config"
+	"pr-reviewer-service/internal/domain"
 	httpapi "pr-reviewer-service/internal/http"
 	"pr-reviewer-service/internal/logging"
+	"pr-reviewer-service/internal/mail"
+	"pr-reviewer-service/internal/oidc"
 	"pr-reviewer-service/internal/random"
 	"pr-reviewer-service/internal/repository/postgres"
+	"pr-reviewer-service/internal/scheduler"
 	"pr-reviewer-service/internal/server"
 	"pr-reviewer-service/internal/service"
 	"pr-reviewer-service/internal/storage"
@@ -54,18 +60,153 @@ func main() {
 	teamRepo := postgres.NewTeamRepository(db)
 	userRepo := postgres.NewUserRepository(db)
 	prRepo := postgres.NewPullRequestRepository(db)
+	absenceRepo := postgres.NewAbsenceRepository(db)
+	ruleRepo := postgres.NewReviewRuleRepository(db)
+	eventRepo := postgres.NewEventRepository(db)
+	chatRepo := postgres.NewChatRepository(db)
+	keyRepo := postgres.NewAPIKeyRepository(db)
+	orgRepo := postgres.NewOrganizationRepository(db)
+	repoRepo := postgres.NewRepoRepository(db)
+	idemRepo := postgres.NewIdempotencyRepository(db)
 
 	// Random source
 	randSource := random.NewCryptoRand()
 
 	// Services
-	teamSvc := service.NewTeamService(teamRepo, userRepo)
-	userSvc := service.NewUserService(userRepo, prRepo)
-	prSvc := service.NewPullRequestService(prRepo, userRepo, teamRepo, randSource)
-	statsSvc := service.NewStatsService(prRepo)
+	teamSvc := service.NewTeamService(teamRepo, userRepo, ruleRepo)
+	userSvc := service.NewUserService(userRepo, prRepo, teamRepo)
+	prSvc := service.NewPullRequestService(prRepo, userRepo, teamRepo, ruleRepo, repoRepo, randSource)
+	statsSvc := service.NewStatsService(prRepo, userRepo)
+	absenceSvc := service.NewAbsenceService(absenceRepo, userRepo)
+	eventSvc := service.NewEventService(eventRepo, userRepo)
+	staleSvc := service.NewStaleReviewService(prRepo, teamRepo, eventRepo, prSvc)
+	chatOpsSvc := service.NewChatOpsService(userRepo, userSvc, prSvc, absenceSvc)
+	keySvc := service.NewAPIKeyService(keyRepo, userRepo, orgRepo, cfg.Auth.BootstrapAdminKey)
+	orgSvc := service.NewOrganizationService(orgRepo)
+	repoSvc := service.NewRepoService(repoRepo, teamRepo, userRepo)
+	idemSvc := service.NewIdempotencyService(idemRepo, cfg.Idempotency.Retention, cfg.Idempotency.Lease)
+
+	if !cfg.Auth.Enabled {
+		logger.Warn("API authentication is disabled, all requests run as ADMIN")
+	}
+
+	var tokenSvc *service.TokenAuthService
+
+	if oidcCfg := cfg.Auth.OIDC; oidcCfg.Enabled() {
+		portalOrg, err := orgSvc.GetBySlug(context.Background(), oidcCfg.Organization)
+
+		if err != nil {
+			logger.Error("failed to resolve OIDC organization", "org", oidcCfg.Organization, "err", err)
+			os.Exit(1)
+		}
+
+		var keys oidc.KeySource
+
+		if oidcCfg.JWKSFile != "" {
+			fileKeys, err := oidc.LoadJWKSFile(oidcCfg.JWKSFile)
+
+			if err != nil {
+				logger.Error("failed to load JWKS", "err", err)
+				os.Exit(1)
+			}
+
+			keys = fileKeys
+		} else {
+			keys = oidc.NewRemoteKeySource(oidcCfg.JWKSURL, &http.Client{Timeout: 5 * time.Second}, oidcCfg.JWKSRefresh)
+		}
+
+		tokenSvc = service.NewTokenAuthService(oidc.NewVerifier(keys, oidcCfg.Issuer, oidcCfg.Audience, time.Minute),
+			userRepo, service.ClaimMapping{
+				UserClaim:   oidcCfg.UserClaim,
+				RoleClaim:   oidcCfg.RoleClaim,
+				RoleMap:     oidcCfg.RoleMap,
+				DefaultRole: oidcCfg.DefaultRole,
+				OrgID:       portalOrg.ID,
+			})
+	}
+
+	chatSvc := service.NewChatNotificationService(chatRepo, chat.NewWebhookClient(10*time.Second),
+		cfg.Chat.RateLimit, cfg.Chat.RateWindow)
+
+	// Background jobs обходят данные всех организаций
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobs := scheduler.New(postgres.NewJobLocker(db), logger)
+
+	jobs.Add(scheduler.Job{
+		Name:     "stale-reviews",
+		Interval: cfg.Jobs.StaleReviewsInterval,
+		Run: func(ctx context.Context) error {
+			return orgSvc.ForEach(ctx, func(ctx context.Context) error {
+				report, err := staleSvc.Run(ctx, time.Now().UTC())
+
+				if err == nil && report != (service.StaleReviewReport{}) {
+					logger.Info("stale reviews processed", "org", domain.OrganizationFrom(ctx),
+						"reassigned", report.Reassigned, "escalated", report.Escalated, "skipped", report.Skipped)
+				}
+
+				return err
+			})
+		},
+	})
+
+	jobs.Add(scheduler.Job{
+		Name:     "chat-notifications",
+		Interval: cfg.Jobs.ChatNotifyInterval,
+		Run: func(ctx context.Context) error {
+			return orgSvc.ForEach(ctx, func(ctx context.Context) error {
+				sent, err := chatSvc.Run(ctx, time.Now().UTC())
+
+				if sent > 0 {
+					logger.Info("chat notifications sent", "org", domain.OrganizationFrom(ctx), "count", sent)
+				}
+
+				return err
+			})
+		},
+	})
+
+	jobs.Add(scheduler.Job{
+		Name:     "idempotency-cleanup",
+		Interval: cfg.Jobs.IdempotencyCleanupInterval,
+		Run: func(ctx context.Context) error {
+			return orgSvc.ForEach(ctx, func(ctx context.Context) error {
+				deleted, err := idemSvc.Cleanup(ctx, time.Now().UTC())
+
+				if deleted > 0 {
+					logger.Info("expired idempotency keys deleted", "org", domain.OrganizationFrom(ctx), "count", deleted)
+				}
+
+				return err
+			})
+		},
+	})
+
+	// Ежедневная сводка отправляется, только если настроен SMTP
+	if cfg.SMTP.Host != "" {
+		digestSvc := service.NewDigestService(prRepo, userRepo, mail.NewSMTPMailer(cfg.SMTP), cfg.Jobs.DigestHour)
+
+		jobs.Add(scheduler.Job{
+			Name:     "review-digest",
+			Interval: cfg.Jobs.DigestInterval,
+			Run: func(ctx context.Context) error {
+				return orgSvc.ForEach(ctx, func(ctx context.Context) error {
+					sent, err := digestSvc.Run(ctx, time.Now().UTC())
+
+					if sent > 0 {
+						logger.Info("review digests sent", "org", domain.OrganizationFrom(ctx), "count", sent)
+					}
+
+					return err
+				})
+			},
+		})
+	}
+
+	jobs.Start(jobsCtx)
 
 	// HTTP router
-	router := httpapi.NewRouter(teamSvc, userSvc, prSvc, statsSvc, logger)
+	router := httpapi.NewRouter(teamSvc, userSvc, prSvc, repoSvc, statsSvc, absenceSvc, eventSvc,
+		chatOpsSvc, keySvc, orgSvc, idemSvc, tokenSvc, cfg.Auth.Enabled, cfg.Chat.SlackSigningSecret, logger)
 
 	// HTTP server
 	httpServer := server.NewHTTPServer(cfg.HTTP, router, logger)
@@ -85,6 +226,9 @@ func main() {
 	<-stop
 	logger.Info("shutting down...")
 
+	stopJobs()
+	jobs.Wait()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
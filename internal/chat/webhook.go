@@ -0,0 +1,64 @@
+// Package chat отправляет уведомления во входящие вебхуки Slack и Mattermost.
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// WebhookClient реализует domain.ChatSender. Формат тела ({"text", "channel"}) общий
+// для входящих вебхуков Slack и Mattermost.
+type WebhookClient struct {
+	client *http.Client
+}
+
+// NewWebhookClient создаёт клиент с ограничением времени на один запрос.
+func NewWebhookClient(timeout time.Duration) *WebhookClient {
+	return &WebhookClient{client: &http.Client{Timeout: timeout}}
+}
+
+type webhookPayload struct {
+	Text    string `json:"text"`
+	Channel string `json:"channel,omitempty"`
+}
+
+// Post отправляет сообщение в вебхук; ответ не из диапазона 2xx считается ошибкой.
+func (c *WebhookClient) Post(ctx context.Context, webhookURL string, msg domain.ChatMessage) error {
+	body, err := json.Marshal(webhookPayload{Text: msg.Text, Channel: msg.Channel})
+
+	if err != nil {
+		return fmt.Errorf("encode chat message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+
+	if err != nil {
+		return fmt.Errorf("build chat request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+
+	if err != nil {
+		return fmt.Errorf("post chat message: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("post chat message: status %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+
+	return nil
+}
@@ -3,7 +3,11 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"pr-reviewer-service/internal/domain"
 )
 
 // HTTPConfig описывает настройки HTTP-сервера.
@@ -33,11 +37,84 @@ type DBConfig struct {
 	DSN string
 }
 
+// JobsConfig задаёт интервалы фоновых задач (0 — задача отключена).
+type JobsConfig struct {
+	StaleReviewsInterval time.Duration
+	DigestInterval       time.Duration
+	ChatNotifyInterval   time.Duration
+	// IdempotencyCleanupInterval — как часто удаляются ответы с истёкшим сроком хранения.
+	IdempotencyCleanupInterval time.Duration
+	// DigestHour — час по локальному времени пользователя, начиная с которого отправляется дайджест.
+	DigestHour int
+}
+
+// SMTPConfig хранит настройки почтового сервера для дайджестов (пустой Host — отправка отключена).
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// ChatConfig задаёт ограничение частоты уведомлений в чат (не больше RateLimit сообщений
+// в окно RateWindow на чат команды) и секрет подписи slash-команд (пусто — команды отключены).
+type ChatConfig struct {
+	RateLimit          int
+	RateWindow         time.Duration
+	SlackSigningSecret string
+}
+
+// AuthConfig задаёт аутентификацию API. BootstrapAdminKey — ключ администратора из окружения,
+// не хранящийся в базе (нужен, чтобы выпустить первые ключи).
+type AuthConfig struct {
+	Enabled           bool
+	BootstrapAdminKey string
+	OIDC              OIDCConfig
+}
+
+// OIDCConfig задаёт вход по JWT внутреннего портала. Ключи подписи берутся из JWKS по адресу JWKSURL
+// или из файла JWKSFile (оба пусты — вход по JWT отключён). Пустые Issuer и Audience не проверяются.
+type OIDCConfig struct {
+	JWKSURL     string
+	JWKSFile    string
+	JWKSRefresh time.Duration
+	Issuer      string
+	Audience    string
+	// UserClaim и RoleClaim — утверждения с идентификатором пользователя и его ролями (группами).
+	UserClaim string
+	RoleClaim string
+	// RoleMap сопоставляет значения RoleClaim ролям сервиса (OIDC_ROLE_MAP="group=ROLE,...").
+	RoleMap     map[string]domain.Role
+	DefaultRole domain.Role
+	// Organization — короткое имя организации, в которой работают пользователи портала.
+	Organization string
+}
+
+// Enabled сообщает, включён ли вход по JWT.
+func (c OIDCConfig) Enabled() bool {
+	return c.JWKSURL != "" || c.JWKSFile != ""
+}
+
+// IdempotencyConfig задаёт, сколько хранятся ответы на запросы с заголовком Idempotency-Key.
+type IdempotencyConfig struct {
+	Retention time.Duration
+	// Lease — через сколько ключ незавершённого запроса (например, после падения сервера)
+	// считается брошенным и может быть занят повтором.
+	Lease time.Duration
+}
+
 // Config объединяет все настройки сервиса.
 type Config struct {
 	HTTP HTTPConfig
 	DB   DBConfig
+	Jobs JobsConfig
+	SMTP SMTPConfig
+	Chat ChatConfig
+	Auth AuthConfig
 	Env  string
+
+	Idempotency IdempotencyConfig
 }
 
 // Load загружает конфигурацию из переменных окружения.
@@ -51,6 +128,72 @@ func Load() (*Config, error) {
 
 	env := getenv("ENV", "dev")
 
+	staleInterval, err := time.ParseDuration(getenv("STALE_REVIEWS_INTERVAL", "10m"))
+
+	if err != nil {
+		return nil, fmt.Errorf("parse STALE_REVIEWS_INTERVAL: %w", err)
+	}
+
+	digestInterval, err := time.ParseDuration(getenv("DIGEST_INTERVAL", "1h"))
+
+	if err != nil {
+		return nil, fmt.Errorf("parse DIGEST_INTERVAL: %w", err)
+	}
+
+	chatInterval, err := time.ParseDuration(getenv("CHAT_NOTIFY_INTERVAL", "30s"))
+
+	if err != nil {
+		return nil, fmt.Errorf("parse CHAT_NOTIFY_INTERVAL: %w", err)
+	}
+
+	idempotencyCleanupInterval, err := time.ParseDuration(getenv("IDEMPOTENCY_CLEANUP_INTERVAL", "1h"))
+
+	if err != nil {
+		return nil, fmt.Errorf("parse IDEMPOTENCY_CLEANUP_INTERVAL: %w", err)
+	}
+
+	idempotencyRetention, err := time.ParseDuration(getenv("IDEMPOTENCY_RETENTION", "24h"))
+
+	if err != nil || idempotencyRetention <= 0 {
+		return nil, fmt.Errorf("parse IDEMPOTENCY_RETENTION: must be a positive duration")
+	}
+
+	idempotencyLease, err := time.ParseDuration(getenv("IDEMPOTENCY_LEASE", "1m"))
+
+	if err != nil || idempotencyLease <= 0 {
+		return nil, fmt.Errorf("parse IDEMPOTENCY_LEASE: must be a positive duration")
+	}
+
+	chatRateLimit, err := strconv.Atoi(getenv("CHAT_RATE_LIMIT", "10"))
+
+	if err != nil || chatRateLimit < 1 {
+		return nil, fmt.Errorf("parse CHAT_RATE_LIMIT: must be a positive integer")
+	}
+
+	chatRateWindow, err := time.ParseDuration(getenv("CHAT_RATE_WINDOW", "1m"))
+
+	if err != nil {
+		return nil, fmt.Errorf("parse CHAT_RATE_WINDOW: %w", err)
+	}
+
+	authEnabled, err := strconv.ParseBool(getenv("AUTH_ENABLED", "true"))
+
+	if err != nil {
+		return nil, fmt.Errorf("parse AUTH_ENABLED: %w", err)
+	}
+
+	oidcCfg, err := loadOIDC()
+
+	if err != nil {
+		return nil, err
+	}
+
+	digestHour, err := strconv.Atoi(getenv("DIGEST_HOUR", "9"))
+
+	if err != nil || digestHour < 0 || digestHour > 23 {
+		return nil, fmt.Errorf("parse DIGEST_HOUR: must be an hour between 0 and 23")
+	}
+
 	return &Config{
 		HTTP: HTTPConfig{
 			Port:         httpPort,
@@ -61,10 +204,87 @@ func Load() (*Config, error) {
 		DB: DBConfig{
 			DSN: dbDSN,
 		},
+		Jobs: JobsConfig{
+			StaleReviewsInterval: staleInterval,
+			DigestInterval:       digestInterval,
+			ChatNotifyInterval:   chatInterval,
+			DigestHour:           digestHour,
+
+			IdempotencyCleanupInterval: idempotencyCleanupInterval,
+		},
+		SMTP: SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     getenv("SMTP_PORT", "25"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     getenv("SMTP_FROM", "pr-reviewer@localhost"),
+		},
+		Chat: ChatConfig{
+			RateLimit:          chatRateLimit,
+			RateWindow:         chatRateWindow,
+			SlackSigningSecret: os.Getenv("SLACK_SIGNING_SECRET"),
+		},
+		Auth: AuthConfig{
+			Enabled:           authEnabled,
+			BootstrapAdminKey: os.Getenv("ADMIN_API_KEY"),
+			OIDC:              oidcCfg,
+		},
 		Env: env,
+		Idempotency: IdempotencyConfig{
+			Retention: idempotencyRetention,
+			Lease:     idempotencyLease,
+		},
 	}, nil
 }
 
+// loadOIDC читает настройки входа по JWT (переменные OIDC_*).
+func loadOIDC() (OIDCConfig, error) {
+	cfg := OIDCConfig{
+		JWKSURL:      os.Getenv("OIDC_JWKS_URL"),
+		JWKSFile:     os.Getenv("OIDC_JWKS_FILE"),
+		Issuer:       os.Getenv("OIDC_ISSUER"),
+		Audience:     os.Getenv("OIDC_AUDIENCE"),
+		UserClaim:    getenv("OIDC_USER_CLAIM", "sub"),
+		RoleClaim:    getenv("OIDC_ROLE_CLAIM", "roles"),
+		RoleMap:      make(map[string]domain.Role),
+		DefaultRole:  domain.Role(strings.ToUpper(os.Getenv("OIDC_DEFAULT_ROLE"))),
+		Organization: getenv("OIDC_ORG", "default"),
+	}
+
+	if cfg.JWKSURL != "" && cfg.JWKSFile != "" {
+		return OIDCConfig{}, fmt.Errorf("OIDC_JWKS_URL and OIDC_JWKS_FILE are mutually exclusive")
+	}
+
+	refresh, err := time.ParseDuration(getenv("OIDC_JWKS_REFRESH", "1h"))
+
+	if err != nil || refresh <= 0 {
+		return OIDCConfig{}, fmt.Errorf("parse OIDC_JWKS_REFRESH: must be a positive duration")
+	}
+
+	cfg.JWKSRefresh = refresh
+
+	if cfg.DefaultRole != "" && !cfg.DefaultRole.Valid() {
+		return OIDCConfig{}, fmt.Errorf("parse OIDC_DEFAULT_ROLE: unknown role %q", cfg.DefaultRole)
+	}
+
+	for _, pair := range strings.Split(os.Getenv("OIDC_ROLE_MAP"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		value, role, ok := strings.Cut(pair, "=")
+		r := domain.Role(strings.ToUpper(strings.TrimSpace(role)))
+
+		if !ok || strings.TrimSpace(value) == "" || !r.Valid() {
+			return OIDCConfig{}, fmt.Errorf("parse OIDC_ROLE_MAP: invalid entry %q, want value=ROLE", pair)
+		}
+
+		cfg.RoleMap[strings.TrimSpace(value)] = r
+	}
+
+	return cfg, nil
+}
+
 func getenv(key, def string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Role — роль вызывающего API, определяет доступные ему операции.
+type Role string
+
+// Роли вызывающих.
+const (
+	// RoleAdmin — полный доступ, включая управление ключами API.
+	RoleAdmin Role = "ADMIN"
+	// RoleTeamLead — управление командами, пользователями и PR.
+	RoleTeamLead Role = "TEAM_LEAD"
+	// RoleBot — интеграции: операции с PR, отсутствия и уведомления пользователей.
+	RoleBot Role = "BOT"
+	// RoleReader — только чтение.
+	RoleReader Role = "READER"
+	// RoleMember — участник команды, вызывающий сервис из чата: действует от своего имени и только
+	// в своей команде. Ключам API и токенам не выдаётся.
+	RoleMember Role = "MEMBER"
+)
+
+// Valid сообщает, можно ли выдать роль ключу API или токену.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleTeamLead, RoleBot, RoleReader:
+		return true
+	default:
+		return false
+	}
+}
+
+// APIKey — ключ доступа к API. Сам ключ не хранится, только его хеш; Prefix — начало ключа для отображения.
+type APIKey struct {
+	ID int64
+	// OrgID — организация, к данным которой даёт доступ ключ.
+	OrgID int64
+	Name  string
+	Role  Role
+	// UserID — пользователь, от имени которого действует ключ (пусто — служебный ключ).
+	UserID     string
+	Prefix     string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// Caller — аутентифицированный вызывающий API.
+type Caller struct {
+	// KeyID — ключ API, по которому выполнен вызов (0 — вызов не по ключу из базы).
+	KeyID int64
+	// Name — имя ключа или субъекта для журналов.
+	Name string
+	// OrgID — организация, в которой действует вызывающий.
+	OrgID  int64
+	UserID string
+	Role   Role
+	// Platform — оператор платформы (ключ администратора из конфигурации): может заводить
+	// организации и выпускать ключи в любой из них.
+	Platform bool
+}
+
+// HasRole сообщает, есть ли у вызывающего одна из ролей roles.
+func (c Caller) HasRole(roles ...Role) bool {
+	for _, r := range roles {
+		if c.Role == r {
+			return true
+		}
+	}
+
+	return false
+}
+
+type callerKey struct{}
+
+// WithCaller возвращает контекст с вызывающим c.
+func WithCaller(ctx context.Context, c Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+// CallerFrom возвращает вызывающего из контекста (ok == false — вызов без аутентификации,
+// например из фоновой задачи).
+func CallerFrom(ctx context.Context) (Caller, bool) {
+	c, ok := ctx.Value(callerKey{}).(Caller)
+	return c, ok
+}
@@ -12,6 +12,27 @@ const (
 	ErrorCodeNoCandidate = "NO_CANDIDATE"
 	ErrorCodeNotFound    = "NOT_FOUND"
 	ErrorCodeInternal    = "INTERNAL"
+
+	ErrorCodeValidation       = "VALIDATION_ERROR"
+	ErrorCodeCapacityExceeded = "CAPACITY_EXCEEDED"
+
+	ErrorCodeAlreadyAssigned   = "ALREADY_ASSIGNED"
+	ErrorCodeTooManyReviewers  = "TOO_MANY_REVIEWERS"
+	ErrorCodeReviewerIsAuthor  = "REVIEWER_IS_AUTHOR"
+	ErrorCodeReviewerInactive  = "REVIEWER_INACTIVE"
+	ErrorCodeReviewerNotInTeam = "REVIEWER_NOT_IN_TEAM"
+	ErrorCodeReviewerExcluded  = "REVIEWER_EXCLUDED"
+
+	ErrorCodeUnauthorized = "UNAUTHORIZED"
+	ErrorCodeForbidden    = "FORBIDDEN"
+
+	ErrorCodeOrgExists        = "ORG_EXISTS"
+	ErrorCodeRepositoryExists = "REPOSITORY_EXISTS"
+
+	ErrorCodeIdempotencyKeyReused   = "IDEMPOTENCY_KEY_REUSED"
+	ErrorCodeIdempotencyKeyInFlight = "IDEMPOTENCY_KEY_IN_FLIGHT"
+
+	ErrorCodePreconditionFailed = "PRECONDITION_FAILED"
 )
 
 // ErrTeamExists возвращается, когда пытаются создать уже существующую команду.
@@ -23,6 +44,22 @@ var (
 	ErrReviewerNotAssigned = errors.New("reviewer not assigned")
 	ErrNoCandidate         = errors.New("no replacement candidate")
 	ErrNotFound            = errors.New("not found")
+	ErrInvalidInput        = errors.New("invalid input")
+	ErrCapacityExceeded    = errors.New("all candidates reached review capacity")
+	ErrAlreadyAssigned     = errors.New("reviewer already assigned")
+	ErrTooManyReviewers    = errors.New("pull request already has the maximum number of reviewers")
+	ErrReviewerIsAuthor    = errors.New("author cannot review own pull request")
+	ErrReviewerInactive    = errors.New("reviewer is inactive")
+	ErrReviewerNotInTeam   = errors.New("reviewer is not a member of the author's team")
+	ErrReviewerExcluded    = errors.New("reviewer is excluded by team rules")
+	ErrUnauthorized        = errors.New("unauthorized")
+	ErrForbidden           = errors.New("forbidden")
+	ErrOrgExists           = errors.New("organization already exists")
+	ErrRepositoryExists    = errors.New("repository already exists")
+	ErrIdempotencyKeyReuse = errors.New("idempotency key was used with a different request")
+	ErrIdempotencyInFlight = errors.New("request with this idempotency key is still in progress")
+	ErrVersionMismatch     = errors.New("resource was modified: version does not match If-Match")
+	ErrChatHandleTaken     = errors.New("chat handle is already used by another user")
 )
 
 // DomainError оборачивает доменную ошибку с кодом для HTTP-слоя.
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord — запрос, выполненный с ключом идемпотентности, и его ответ.
+type IdempotencyRecord struct {
+	Key string
+	// Caller — владелец ключа (ключ API или пользователь токена): ключи разных вызывающих не пересекаются.
+	Caller string
+	// RequestHash — хеш метода, пути и тела запроса: повтор с другим телом отклоняется.
+	RequestHash string
+	// Completed сообщает, что ответ сохранён; до этого запрос с ключом ещё выполняется.
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	ETag        string
+	Body        []byte
+	// CreatedAt — время, когда ключ занят; по нему завершается именно эта попытка, а не перехватившая ключ.
+	CreatedAt time.Time
+}
@@ -1,21 +1,342 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"sync"
+	"time"
+)
 
 // User описывает пользователя и его состояние в системе.
 type User struct {
-	ID        string
-	Username  string
-	TeamName  string
-	IsActive  bool
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID       string
+	Username string
+	TeamName string
+	IsActive bool
+	// MaxOpenReviews — личный лимит одновременно открытых ревью (nil — берётся значение команды).
+	MaxOpenReviews *int
+	// Timezone — IANA-имя часового пояса пользователя.
+	Timezone string
+	// WorkHours — рабочие часы в часовом поясе пользователя (nil — график не задан).
+	WorkHours *WorkHours
+	// Seniority — уровень пользователя (пусто — не задан).
+	Seniority Seniority
+	// Weight — явный вес при взвешенном выборе (nil — вес по уровню).
+	Weight *int
+	// Email — адрес для уведомлений (пусто — не задан).
+	Email string
+	// DigestEnabled — получает ли пользователь ежедневную сводку по ревью.
+	DigestEnabled bool
+	// DigestLastSentAt — когда пользователю последний раз отправлялась сводка (nil — ни разу).
+	DigestLastSentAt *time.Time
+	// ChatHandle — имя в чате для @-упоминаний (пусто — упоминается по username).
+	ChatHandle string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Seniority — уровень пользователя.
+type Seniority string
+
+// Уровни пользователей.
+const (
+	SeniorityJunior Seniority = "JUNIOR"
+	SeniorityMiddle Seniority = "MIDDLE"
+	SenioritySenior Seniority = "SENIOR"
+	SeniorityLead   Seniority = "LEAD"
+)
+
+// Valid проверяет, что уровень входит в список поддерживаемых.
+func (l Seniority) Valid() bool {
+	switch l {
+	case SeniorityJunior, SeniorityMiddle, SenioritySenior, SeniorityLead:
+		return true
+	}
+
+	return false
+}
+
+// SelectionWeight возвращает вес пользователя при взвешенном выборе ревьюеров.
+// Без явного веса сеньоры и лиды получают больше ревью, джуны — меньше, но не ноль.
+func (u User) SelectionWeight() int {
+	if u.Weight != nil {
+		return *u.Weight
+	}
+
+	switch u.Seniority {
+	case SeniorityJunior:
+		return 1
+	case SenioritySenior, SeniorityLead:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// InWorkingHours сообщает, находится ли пользователь в рабочем времени в момент at.
+// Пользователь без графика считается доступным всегда.
+func (u User) InWorkingHours(at time.Time) bool {
+	if u.WorkHours == nil {
+		return true
+	}
+
+	return u.WorkHours.Contains(at.In(u.Location()))
+}
+
+// Location возвращает часовой пояс пользователя (UTC, если пояс не задан или неизвестен).
+func (u User) Location() *time.Location {
+	loc, err := LoadLocation(u.Timezone)
+
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// locations — разобранные часовые пояса по имени: пояс нужен для каждого кандидата при каждом
+// выборе ревьюеров, а time.LoadLocation каждый раз читает и разбирает tzdata.
+var locations sync.Map
+
+// LoadLocation возвращает часовой пояс по имени IANA, как time.LoadLocation, но разбирает каждый пояс
+// один раз. Неизвестные имена не запоминаются, чтобы произвольный ввод не раздувал кэш.
+func LoadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	locations.Store(name, loc)
+
+	return loc, nil
+}
+
+// WeekdaySet — множество дней недели в виде битовой маски (бит 0 — воскресенье).
+type WeekdaySet uint8
+
+// DefaultWorkDays — рабочие дни по умолчанию (понедельник–пятница).
+const DefaultWorkDays WeekdaySet = 1<<time.Monday | 1<<time.Tuesday | 1<<time.Wednesday |
+	1<<time.Thursday | 1<<time.Friday
+
+// Has проверяет, входит ли день недели в множество.
+func (s WeekdaySet) Has(d time.Weekday) bool {
+	return s&(1<<d) != 0
+}
+
+// WorkHours описывает рабочий интервал дня в минутах от полуночи и рабочие дни.
+// Если StartMinute > EndMinute, смена переходит через полночь и относится к дню начала.
+type WorkHours struct {
+	StartMinute int
+	EndMinute   int
+	Days        WeekdaySet
+}
+
+// Contains проверяет, попадает ли локальное время t в рабочие часы.
+func (w WorkHours) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+
+	if w.StartMinute <= w.EndMinute {
+		return w.Days.Has(t.Weekday()) && minute >= w.StartMinute && minute < w.EndMinute
+	}
+
+	if minute >= w.StartMinute {
+		return w.Days.Has(t.Weekday())
+	}
+
+	// хвост ночной смены, начавшейся накануне
+	return minute < w.EndMinute && w.Days.Has((t.Weekday()+6)%7)
 }
 
 // Team представляет команду и её участников.
 type Team struct {
-	Name    string
-	Members []User
+	Name     string
+	Settings TeamSettings
+	Members  []User
+}
+
+// CapacityPolicy определяет поведение, когда все кандидаты достигли лимита ревью.
+type CapacityPolicy string
+
+// Политики назначения при исчерпании ёмкости ревьюверов.
+const (
+	CapacityPolicyAssignAnyway CapacityPolicy = "ASSIGN_ANYWAY"
+	CapacityPolicyAssignFewer  CapacityPolicy = "ASSIGN_FEWER"
+	CapacityPolicyFail         CapacityPolicy = "FAIL"
+)
+
+// Valid проверяет, что политика входит в список поддерживаемых.
+func (p CapacityPolicy) Valid() bool {
+	switch p {
+	case CapacityPolicyAssignAnyway, CapacityPolicyAssignFewer, CapacityPolicyFail:
+		return true
+	}
+
+	return false
+}
+
+// SelectionStrategy — стратегия выбора ревьюеров команды.
+type SelectionStrategy string
+
+// Стратегии выбора ревьюеров.
+const (
+	SelectionStrategyRandom     SelectionStrategy = "RANDOM"
+	SelectionStrategyWeighted   SelectionStrategy = "WEIGHTED"
+	SelectionStrategyRoundRobin SelectionStrategy = "ROUND_ROBIN"
+)
+
+// Valid проверяет, что стратегия входит в список поддерживаемых.
+func (s SelectionStrategy) Valid() bool {
+	switch s {
+	case SelectionStrategyRandom, SelectionStrategyWeighted, SelectionStrategyRoundRobin:
+		return true
+	}
+
+	return false
+}
+
+// StaleAction — что делать с ревью, по которому ревьювер не отметился в срок SLA.
+type StaleAction string
+
+// Действия при нарушении SLA на ревью.
+const (
+	StaleActionReassign StaleAction = "REASSIGN"
+	StaleActionEscalate StaleAction = "ESCALATE"
+)
+
+// Valid проверяет, что действие входит в список поддерживаемых.
+func (a StaleAction) Valid() bool {
+	switch a {
+	case StaleActionReassign, StaleActionEscalate:
+		return true
+	}
+
+	return false
+}
+
+// TeamSettings содержит настройки назначения ревьюверов в команде.
+type TeamSettings struct {
+	DefaultMaxOpenReviews *int
+	CapacityPolicy        CapacityPolicy
+	// PreferWorkingHours включает режим, при котором сначала выбираются ревьюеры в рабочих часах.
+	PreferWorkingHours bool
+	// PairingWindowDays — за сколько дней учитывается история пар автор–ревьюер (0 — не учитывается).
+	PairingWindowDays int
+	Strategy          SelectionStrategy
+	// MaxReviewers — сколько ревьюеров назначается на PR (по умолчанию 2).
+	MaxReviewers int
+	// ReviewSLAHours — за сколько часов ревьювер должен отметить ревью (0 — SLA не отслеживается).
+	ReviewSLAHours int
+	StaleAction    StaleAction
+	// LeadID — тимлид, которому эскалируются просроченные ревью (пусто — не задан).
+	LeadID string
+	// ChatWebhookURL — входящий вебхук чата для уведомлений о назначениях (пусто — не отправляются).
+	ChatWebhookURL string
+	// ChatChannel — канал, в который пишет вебхук (пусто — канал по умолчанию вебхука).
+	ChatChannel string
+	// Version растёт при каждом изменении настроек команды; отдаётся в ETag.
+	Version int64
+}
+
+// Optional описывает значение, которое в запросе может отсутствовать (Set == false)
+// или быть явно сброшено в null (Set == true, Value == nil).
+type Optional[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// TeamSettingsUpdate — частичное изменение настроек команды: неуказанные (nil/не Set) поля не меняются.
+type TeamSettingsUpdate struct {
+	DefaultMaxOpenReviews Optional[int]
+	CapacityPolicy        *CapacityPolicy
+	PreferWorkingHours    *bool
+	PairingWindowDays     *int
+	Strategy              *SelectionStrategy
+	MaxReviewers          *int
+	ReviewSLAHours        *int
+	StaleAction           *StaleAction
+	LeadID                Optional[string]
+	ChatWebhookURL        Optional[string]
+	ChatChannel           Optional[string]
+}
+
+// Apply возвращает настройки s с применёнными изменениями u.
+func (u TeamSettingsUpdate) Apply(s TeamSettings) TeamSettings {
+	if u.DefaultMaxOpenReviews.Set {
+		s.DefaultMaxOpenReviews = u.DefaultMaxOpenReviews.Value
+	}
+
+	if u.CapacityPolicy != nil {
+		s.CapacityPolicy = *u.CapacityPolicy
+	}
+
+	if u.PreferWorkingHours != nil {
+		s.PreferWorkingHours = *u.PreferWorkingHours
+	}
+
+	if u.PairingWindowDays != nil {
+		s.PairingWindowDays = *u.PairingWindowDays
+	}
+
+	if u.Strategy != nil {
+		s.Strategy = *u.Strategy
+	}
+
+	if u.MaxReviewers != nil {
+		s.MaxReviewers = *u.MaxReviewers
+	}
+
+	if u.ReviewSLAHours != nil {
+		s.ReviewSLAHours = *u.ReviewSLAHours
+	}
+
+	if u.StaleAction != nil {
+		s.StaleAction = *u.StaleAction
+	}
+
+	if u.LeadID.Set {
+		s.LeadID = ""
+
+		if u.LeadID.Value != nil {
+			s.LeadID = *u.LeadID.Value
+		}
+	}
+
+	if u.ChatWebhookURL.Set {
+		s.ChatWebhookURL = ""
+
+		if u.ChatWebhookURL.Value != nil {
+			s.ChatWebhookURL = *u.ChatWebhookURL.Value
+		}
+	}
+
+	if u.ChatChannel.Set {
+		s.ChatChannel = ""
+
+		if u.ChatChannel.Value != nil {
+			s.ChatChannel = *u.ChatChannel.Value
+		}
+	}
+
+	return s
+}
+
+// CapacityFor возвращает эффективный лимит открытых ревью пользователя (nil — без ограничений).
+func (s TeamSettings) CapacityFor(u User) *int {
+	if u.MaxOpenReviews != nil {
+		return u.MaxOpenReviews
+	}
+
+	return s.DefaultMaxOpenReviews
+}
+
+// ReviewLoad описывает текущую загрузку ревьюера относительно его лимита.
+type ReviewLoad struct {
+	OpenReviews    int
+	MaxOpenReviews *int
 }
 
 // PRStatus — статус pull request.
@@ -29,25 +350,382 @@ const (
 
 // PullRequest описывает pull request с назначенными ревьюерами.
 type PullRequest struct {
-	ID                string
-	Name              string
+	ID   string
+	Name string
+	// Repository и Number — репозиторий и номер PR в нём (пусто и 0 — PR без репозитория).
+	Repository        string
+	Number            int
 	AuthorID          string
 	Status            PRStatus
 	AssignedReviewers []string
+	Labels            []string
 	CreatedAt         *time.Time
 	MergedAt          *time.Time
+	// Version растёт при каждом изменении PR (merge, смена ревьюеров); отдаётся в ETag.
+	Version int64
+}
+
+// PullRequestIDFor возвращает идентификатор PR номер number в репозитории repository.
+func PullRequestIDFor(repository string, number int) string {
+	return fmt.Sprintf("%s#%d", repository, number)
+}
+
+// CodeHost — сервис, на котором размещён репозиторий.
+type CodeHost string
+
+// Поддерживаемые сервисы размещения кода.
+const (
+	CodeHostGitHub    CodeHost = "GITHUB"
+	CodeHostGitLab    CodeHost = "GITLAB"
+	CodeHostBitbucket CodeHost = "BITBUCKET"
+	CodeHostOther     CodeHost = "OTHER"
+)
+
+// Valid проверяет, что сервис входит в список поддерживаемых.
+func (h CodeHost) Valid() bool {
+	switch h {
+	case CodeHostGitHub, CodeHostGitLab, CodeHostBitbucket, CodeHostOther:
+		return true
+	}
+
+	return false
+}
+
+// Repo — репозиторий кода, принадлежащий команде. PR репозитория нумеруются в нём,
+// а ревьюверы по умолчанию подбираются из команды автора по её стратегии.
+type Repo struct {
+	ID       int64
+	Name     string
+	CodeHost CodeHost
+	TeamName string
+	// ReviewerTeam переопределяет команду, из которой подбираются ревьюверы (пусто — команда автора).
+	ReviewerTeam string
+	// Strategy переопределяет стратегию выбора команды ревьюверов (пусто — стратегия команды).
+	Strategy  SelectionStrategy
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// RepoOverridesUpdate — частичное изменение переопределений репозитория (null — сброс).
+type RepoOverridesUpdate struct {
+	ReviewerTeam Optional[string]
+	Strategy     Optional[SelectionStrategy]
+}
+
+// Apply возвращает репозиторий r с применёнными изменениями u.
+func (u RepoOverridesUpdate) Apply(r Repo) Repo {
+	if u.ReviewerTeam.Set {
+		r.ReviewerTeam = ""
+
+		if u.ReviewerTeam.Value != nil {
+			r.ReviewerTeam = *u.ReviewerTeam.Value
+		}
+	}
+
+	if u.Strategy.Set {
+		r.Strategy = ""
+
+		if u.Strategy.Value != nil {
+			r.Strategy = *u.Strategy.Value
+		}
+	}
+
+	return r
+}
+
+// StaleAssignment — назначение, по которому ревьювер не отметил ревью в срок SLA команды автора.
+type StaleAssignment struct {
+	PRID       string
+	ReviewerID string
+	// TeamName — команда автора PR, чей SLA нарушен.
+	TeamName   string
+	AssignedAt time.Time
 }
 
 // PullRequestShort — краткая информация о pull request.
 type PullRequestShort struct {
-	ID       string
-	Name     string
-	AuthorID string
-	Status   PRStatus
+	ID        string
+	Name      string
+	AuthorID  string
+	Status    PRStatus
+	CreatedAt *time.Time
+}
+
+// AwaitingReview — открытый PR автора и ревьюверы, которые ещё не отметили ревью.
+type AwaitingReview struct {
+	PR        PullRequestShort
+	Reviewers []string
+}
+
+// ReviewDigest — ежедневная сводка пользователя: ревью, которые ждут его, и его PR, которые ждут других.
+type ReviewDigest struct {
+	User     User
+	At       time.Time
+	Pending  []PullRequestShort
+	Awaiting []AwaitingReview
+}
+
+// Empty сообщает, что в сводке нечего показать.
+func (d ReviewDigest) Empty() bool {
+	return len(d.Pending) == 0 && len(d.Awaiting) == 0
+}
+
+// EmailMessage — письмо с текстовой и HTML-версией.
+type EmailMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// RuleKind — тип правила назначения ревьюверов.
+type RuleKind string
+
+// Типы правил назначения.
+const (
+	RuleKindRequire RuleKind = "REQUIRE"
+	RuleKindExclude RuleKind = "EXCLUDE"
+)
+
+// ReviewRule — правило команды, применяемое до случайного выбора ревьюверов.
+// Условие задаётся автором (AuthorID или AuthorLevel) и меткой PR; пустые поля означают «любой».
+// Целью правила является конкретный пользователь (ReviewerID) либо любой пользователь уровня ReviewerLevel.
+type ReviewRule struct {
+	ID            int64
+	TeamName      string
+	Kind          RuleKind
+	AuthorID      string
+	AuthorLevel   Seniority
+	Label         string
+	ReviewerID    string
+	ReviewerLevel Seniority
+	Description   string
+	CreatedAt     time.Time
+}
+
+// Matches проверяет, выполняется ли условие правила для PR автора author с метками labels.
+func (r ReviewRule) Matches(author User, labels []string) bool {
+	if r.AuthorID != "" && r.AuthorID != author.ID {
+		return false
+	}
+
+	if r.AuthorLevel != "" && r.AuthorLevel != author.Seniority {
+		return false
+	}
+
+	if r.Label == "" {
+		return true
+	}
+
+	for _, l := range labels {
+		if l == r.Label {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Targets проверяет, относится ли правило к пользователю u как к ревьюеру.
+func (r ReviewRule) Targets(u User) bool {
+	if r.ReviewerID != "" {
+		return r.ReviewerID == u.ID
+	}
+
+	return r.ReviewerLevel != "" && r.ReviewerLevel == u.Seniority
+}
+
+// AssignmentReason — почему ревьюер оказался назначен.
+type AssignmentReason string
+
+// Причины назначения ревьюера.
+const (
+	AssignmentReasonRule     AssignmentReason = "RULE"
+	AssignmentReasonSelected AssignmentReason = "SELECTED"
+)
+
+// ReviewerAssignment объясняет назначение одного ревьюера.
+type ReviewerAssignment struct {
+	UserID string
+	Reason AssignmentReason
+	RuleID int64
+}
+
+// RuleOutcome — результат применения правила.
+type RuleOutcome string
+
+// Результаты применения правил.
+const (
+	RuleOutcomeRequired    RuleOutcome = "REQUIRED"
+	RuleOutcomeExcluded    RuleOutcome = "EXCLUDED"
+	RuleOutcomeUnsatisfied RuleOutcome = "UNSATISFIED"
+)
+
+// RuleDecision фиксирует, как сработало правило при назначении.
+type RuleDecision struct {
+	RuleID int64
+	Kind   RuleKind
+	UserID string
+	// ReviewerLevel — уровень, на который ссылается правило (для правил по уровню ревьювера).
+	ReviewerLevel Seniority
+	Outcome       RuleOutcome
+	Description   string
+}
+
+// ExclusionReason — почему участник команды не может быть выбран ревьюером.
+type ExclusionReason string
+
+// Причины исключения кандидата. DECLINED — пользователь уже отказывался от этого PR.
+const (
+	ExclusionAuthor          ExclusionReason = "AUTHOR"
+	ExclusionInactive        ExclusionReason = "INACTIVE"
+	ExclusionAbsent          ExclusionReason = "ABSENT"
+	ExclusionAlreadyAssigned ExclusionReason = "ALREADY_ASSIGNED"
+	ExclusionDeclined        ExclusionReason = "DECLINED"
+	ExclusionRule            ExclusionReason = "RULE"
+	// ExclusionNotInTeam — пользователь не из команды, из которой выбираются ревьюеры
+	// (в объяснении не встречается: там разбираются только участники команды).
+	ExclusionNotInTeam ExclusionReason = "NOT_IN_TEAM"
+	// ExclusionCapacity — кандидат достиг лимита; при ASSIGN_ANYWAY он используется, если свободных не хватило.
+	ExclusionCapacity ExclusionReason = "CAPACITY"
+)
+
+// CandidateScore — данные, по которым стратегия упорядочивает кандидатов.
+type CandidateScore struct {
+	Weight         int
+	Pairings       int
+	OpenReviews    int
+	MaxOpenReviews *int
+	InWorkingHours bool
+	// RotationPosition — место в очереди ROUND_ROBIN среди допустимых кандидатов (0 — для других стратегий).
+	RotationPosition int
+}
+
+// CandidateReport описывает одного участника команды при выборе ревьюеров.
+type CandidateReport struct {
+	UserID string
+	// Excluded — причина исключения (пусто — кандидат допустим).
+	Excluded ExclusionReason
+	RuleID   int64
+	Assigned bool
+	Score    CandidateScore
+}
+
+// AssignmentExplanation объясняет, почему на PR назначены именно эти ревьюеры.
+// Candidates заполняется только по запросу подробного объяснения.
+type AssignmentExplanation struct {
+	Reviewers  []ReviewerAssignment
+	Rules      []RuleDecision
+	Strategy   SelectionStrategy
+	Candidates []CandidateReport
+	// ReevaluatedAt задано, если объяснение построено позже назначения по действующим на этот момент
+	// правилам и составу команды, а не сохранено при выборе ревьюеров.
+	ReevaluatedAt *time.Time
+}
+
+// AssignmentAction — тип события в истории назначений.
+type AssignmentAction string
+
+// События истории назначений.
+const (
+	AssignmentActionAssigned   AssignmentAction = "ASSIGNED"
+	AssignmentActionUnassigned AssignmentAction = "UNASSIGNED"
+)
+
+// AssignmentChange — запись истории назначений вместе с PR и участниками, для уведомлений.
+// Записи с одинаковым TxID сделаны одним действием (например, снятие и назначение при переназначении).
+type AssignmentChange struct {
+	ID       int64
+	TxID     int64
+	PRID     string
+	PRName   string
+	Author   User
+	Reviewer User
+	Action   AssignmentAction
+	// ActorID — кто изменил список ревьюверов (пусто — автоматически).
+	ActorID string
+}
+
+// ChatChannel — чат команды для уведомлений и состояние отправки в него.
+type ChatChannel struct {
+	TeamName   string
+	WebhookURL string
+	Channel    string
+	// Cursor — id последней обработанной записи истории назначений.
+	Cursor int64
+	// WindowStart и WindowCount — начало текущего окна ограничения частоты и число сообщений в нём.
+	WindowStart time.Time
+	WindowCount int
+}
+
+// ChatMessage — сообщение во входящий вебхук чата.
+type ChatMessage struct {
+	Channel string
+	Text    string
 }
 
 // AssignmentStatByUser содержит статистику назначений по пользователю.
+// Declines — сколько раз пользователь отказался от назначенного ревью.
 type AssignmentStatByUser struct {
-	UserID string
-	Count  int64
+	UserID   string
+	Count    int64
+	Declines int64
+}
+
+// ReviewDecline — отказ ревьювера от назначенного ревью.
+// ReplacedBy пуст, если заменить ревьювера было некем.
+type ReviewDecline struct {
+	ID         int64
+	PRID       string
+	ReviewerID string
+	Reason     string
+	ReplacedBy string
+	// ActorID — кто записал отказ: сам ревьювер или ADMIN/BOT по его поручению.
+	ActorID   string
+	CreatedAt time.Time
+}
+
+// AbsenceSource — источник записи об отсутствии.
+type AbsenceSource string
+
+// Источники записей об отсутствии.
+const (
+	AbsenceSourceManual AbsenceSource = "MANUAL"
+	AbsenceSourceICS    AbsenceSource = "ICS"
+)
+
+// Absence описывает плановое отсутствие пользователя в интервале [StartsAt, EndsAt).
+type Absence struct {
+	ID          int64
+	UserID      string
+	StartsAt    time.Time
+	EndsAt      time.Time
+	Reason      string
+	Source      AbsenceSource
+	ExternalUID string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// EventType — тип события для внешних потребителей.
+type EventType string
+
+// Типы событий.
+const (
+	// EventReviewEscalated — просроченное ревью эскалировано тимлиду (UserID — тимлид).
+	EventReviewEscalated EventType = "REVIEW_ESCALATED"
+	// EventStaleReviewReassigned — просроченное ревью передано другому ревьюверу (UserID — новый ревьювер).
+	EventStaleReviewReassigned EventType = "STALE_REVIEW_REASSIGNED"
+)
+
+// Event — событие сервиса, которое читают уведомления и интеграции.
+type Event struct {
+	ID       int64
+	Type     EventType
+	TeamName string
+	PRID     string
+	// UserID — кому адресовано событие.
+	UserID    string
+	Payload   map[string]string
+	CreatedAt time.Time
 }
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultOrganizationID — организация, созданная миграцией для данных, существовавших до разделения
+// на организации; в ней же работают ключ администратора из конфигурации и вход по JWT портала.
+const DefaultOrganizationID int64 = 1
+
+// Organization — компания, данные которой изолированы от других: имена команд, идентификаторы
+// пользователей и PR уникальны только в пределах организации.
+type Organization struct {
+	ID   int64
+	Slug string
+	Name string
+	// ChatTeamID — идентификатор рабочего пространства чата (team_id Slack) для slash-команд.
+	ChatTeamID string
+	CreatedAt  time.Time
+}
+
+type organizationKey struct{}
+
+// WithOrganization возвращает контекст, в котором репозитории работают с данными организации orgID.
+func WithOrganization(ctx context.Context, orgID int64) context.Context {
+	return context.WithValue(ctx, organizationKey{}, orgID)
+}
+
+// OrganizationFrom возвращает организацию из контекста (0 — не задана).
+func OrganizationFrom(ctx context.Context) int64 {
+	id, _ := ctx.Value(organizationKey{}).(int64)
+	return id
+}
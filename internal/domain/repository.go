@@ -11,6 +11,14 @@ type TeamRepository interface {
 	CreateTeam(ctx context.Context, name string, members []User) error
 	GetTeamWithMembers(ctx context.Context, teamName string) (Team, error)
 	TeamExists(ctx context.Context, name string) (bool, error)
+	GetSettings(ctx context.Context, teamName string) (TeamSettings, error)
+	// UpdateSettings сохраняет настройки, только если их версия всё ещё равна settings.Version,
+	// иначе возвращает ErrVersionMismatch.
+	UpdateSettings(ctx context.Context, teamName string, settings TeamSettings) (TeamSettings, error)
+	// AdvanceRoundRobin атомарно читает курсор ротации команды, передаёт его в advance
+	// и сохраняет возвращённый курсор. Конкурентные вызовы для одной команды выполняются по очереди.
+	AdvanceRoundRobin(ctx context.Context, teamName string, advance func(cursor string) (string, error)) error
+	GetRoundRobinCursor(ctx context.Context, teamName string) (string, error)
 }
 
 // UserRepository описывает операции работы с пользователями.
@@ -18,18 +26,162 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id string) (User, error)
 	UpsertUsers(ctx context.Context, teamName string, users []User) error
 	SetIsActive(ctx context.Context, id string, isActive bool) (User, error)
-	GetActiveTeamMembersExcept(ctx context.Context, teamName, excludeUserID string) ([]User, error)
+	SetMaxOpenReviews(ctx context.Context, id string, maxOpenReviews *int) (User, error)
+	SetWorkSchedule(ctx context.Context, id, timezone string, hours *WorkHours) (User, error)
+	SetSeniority(ctx context.Context, id string, seniority Seniority, weight *int) (User, error)
+	GetAvailableTeamMembersExcept(ctx context.Context, teamName, excludeUserID string, at time.Time) ([]User, error)
 	GetTeamByUserID(ctx context.Context, userID string) (string, error)
+	// SetNotifications задаёт адрес и имя в чате для уведомлений (пусто — сброс) и подписку на сводку.
+	SetNotifications(ctx context.Context, id, email, chatHandle string, digestEnabled bool) (User, error)
+	// ListDigestRecipients возвращает активных пользователей с адресом и включённой сводкой.
+	ListDigestRecipients(ctx context.Context) ([]User, error)
+	MarkDigestSent(ctx context.Context, id string, at time.Time) error
+	// GetByChatHandle возвращает пользователя, чьё имя в чате совпадает с одним из handles
+	// (при нескольких совпадениях — с более ранним в списке). Имя в чате уникально в организации.
+	GetByChatHandle(ctx context.Context, handles []string) (User, error)
 }
 
 // PullRequestRepository описывает операции с pull request-ами.
 type PullRequestRepository interface {
+	// Create возвращает ErrPRExists, если PR с таким идентификатором или номером в репозитории уже есть.
 	Create(ctx context.Context, pr PullRequest) error
 	GetByID(ctx context.Context, id string) (PullRequest, error)
+	// MarkMerged и методы, меняющие ревьюеров, увеличивают версию PR и возвращают ErrVersionMismatch,
+	// если она не равна ожидаемой в контексте (WithExpectedVersion).
 	MarkMerged(ctx context.Context, id string, mergedAt time.Time) (PullRequest, error)
 	ReassignReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) (PullRequest, error)
+	// AddReviewer добавляет ревьюера, если на PR меньше maxReviewers ревьюеров (ErrTooManyReviewers).
+	AddReviewer(ctx context.Context, prID, reviewerID, actorID string, maxReviewers int) (PullRequest, error)
+	RemoveReviewer(ctx context.Context, prID, reviewerID, actorID string) (PullRequest, error)
+	// DeclineReview снимает ревьюера по его отказу, назначает замену (если она есть) и сохраняет отказ.
+	DeclineReview(ctx context.Context, decline ReviewDecline) (PullRequest, error)
+	// ListDecliners возвращает пользователей, отказывавшихся от ревью PR.
+	ListDecliners(ctx context.Context, prID string) ([]string, error)
 	ListByReviewer(ctx context.Context, reviewerID string) ([]PullRequestShort, error)
+	// ListAwaitingByAuthor возвращает OPEN PR автора, в которых есть ревьюверы, ещё не отметившие ревью.
+	ListAwaitingByAuthor(ctx context.Context, authorID string) ([]AwaitingReview, error)
+	// MarkReviewed отмечает, что ревьювер взялся за ревью (ErrReviewerNotAssigned, если не назначен).
+	MarkReviewed(ctx context.Context, prID, reviewerID string, at time.Time) error
+	// ListStaleAssignments возвращает назначения в OPEN PR, не отмеченные ревьювером и не эскалированные,
+	// которые старше SLA команды автора на момент at.
+	ListStaleAssignments(ctx context.Context, at time.Time) ([]StaleAssignment, error)
+	MarkEscalated(ctx context.Context, prID, reviewerID string, at time.Time) error
 	PRExists(ctx context.Context, id string) (bool, error)
-	GetAssignmentStatsByUser(ctx context.Context) ([]AssignmentStatByUser, error)
+	// GetAssignmentStatsByUser возвращает статистику по ревьюерам команды teamName (пусто — всех команд).
+	GetAssignmentStatsByUser(ctx context.Context, teamName string) ([]AssignmentStatByUser, error)
+	CountOpenReviews(ctx context.Context, reviewerIDs []string) (map[string]int, error)
+	CountPairings(ctx context.Context, authorID string, reviewerIDs []string, since time.Time) (map[string]int, error)
 	WithTx(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error
 }
+
+// RepoRepository хранит репозитории кода.
+type RepoRepository interface {
+	Create(ctx context.Context, repo Repo) (Repo, error)
+	// GetByName возвращает репозиторий по имени (ErrNotFound, если такого нет).
+	GetByName(ctx context.Context, name string) (Repo, error)
+	// List возвращает репозитории команды teamName (пусто — всех команд).
+	List(ctx context.Context, teamName string) ([]Repo, error)
+	UpdateOverrides(ctx context.Context, repo Repo) (Repo, error)
+}
+
+// AbsenceRepository описывает операции с плановыми отсутствиями пользователей.
+type AbsenceRepository interface {
+	Create(ctx context.Context, a Absence) (Absence, error)
+	GetByID(ctx context.Context, id int64) (Absence, error)
+	Update(ctx context.Context, a Absence) (Absence, error)
+	Delete(ctx context.Context, id int64) (Absence, error)
+	ListByUser(ctx context.Context, userID string) ([]Absence, error)
+	UpsertImported(ctx context.Context, absences []Absence) ([]Absence, error)
+}
+
+// ReviewRuleRepository описывает операции с правилами назначения ревьюверов.
+type ReviewRuleRepository interface {
+	Create(ctx context.Context, rule ReviewRule) (ReviewRule, error)
+	GetByID(ctx context.Context, id int64) (ReviewRule, error)
+	Delete(ctx context.Context, id int64) (ReviewRule, error)
+	ListByTeam(ctx context.Context, teamName string) ([]ReviewRule, error)
+}
+
+// EventRepository хранит события для внешних потребителей.
+type EventRepository interface {
+	Publish(ctx context.Context, e Event) (Event, error)
+	// List возвращает до limit событий с идентификатором больше afterID (teamName пуст — все команды).
+	List(ctx context.Context, teamName string, afterID int64, limit int) ([]Event, error)
+}
+
+// JobLocker не даёт нескольким экземплярам сервиса одновременно выполнять одну фоновую задачу.
+type JobLocker interface {
+	// TryRun выполняет fn, если блокировка name свободна, и сообщает, была ли она получена.
+	TryRun(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error)
+}
+
+// Mailer отправляет письма.
+type Mailer interface {
+	Send(ctx context.Context, msg EmailMessage) error
+}
+
+// ChatRepository хранит состояние отправки уведомлений в чаты команд.
+type ChatRepository interface {
+	// ListChatChannels возвращает команды с настроенным вебхуком.
+	ListChatChannels(ctx context.Context) ([]ChatChannel, error)
+	// ListAssignmentChanges возвращает до limit записей истории назначений по PR авторов команды
+	// с id больше afterID, сделанных не позже before, в порядке id.
+	ListAssignmentChanges(
+		ctx context.Context,
+		teamName string,
+		afterID int64,
+		before time.Time,
+		limit int,
+	) ([]AssignmentChange, error)
+	// SaveChatState сохраняет курсор и окно ограничения частоты, если вебхук команды не сменился.
+	SaveChatState(ctx context.Context, ch ChatChannel) error
+}
+
+// ChatSender отправляет сообщения во входящие вебхуки чатов.
+type ChatSender interface {
+	Post(ctx context.Context, webhookURL string, msg ChatMessage) error
+}
+
+// APIKeyRepository хранит ключи API.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key APIKey, hash string) (APIKey, error)
+	// GetByHash возвращает неотозванный ключ по хешу (ErrNotFound, если такого нет).
+	GetByHash(ctx context.Context, hash string) (APIKey, error)
+	List(ctx context.Context) ([]APIKey, error)
+	// Revoke отзывает ключ; повторный отзыв возвращает ключ без изменений.
+	Revoke(ctx context.Context, id int64, at time.Time) (APIKey, error)
+	// TouchLastUsed запоминает время использования ключа, если прошлое старше at - interval.
+	TouchLastUsed(ctx context.Context, id int64, at time.Time, interval time.Duration) error
+}
+
+// IdempotencyRepository хранит ответы на запросы с ключами идемпотентности.
+type IdempotencyRepository interface {
+	// Reserve занимает ключ rec.Key вызывающего rec.Caller для запроса с хешем rec.RequestHash.
+	// Запись старше expiredBefore, а также незавершённая старше staleBefore (запрос, видимо, прервался)
+	// занимаются заново. Если ключ уже занят, возвращает его запись и reserved == false
+	// (ErrNotFound, если запись исчезла между попытками).
+	Reserve(
+		ctx context.Context,
+		rec IdempotencyRecord,
+		expiredBefore, staleBefore time.Time,
+	) (record IdempotencyRecord, reserved bool, err error)
+	// Complete сохраняет ответ rec, если ключ всё ещё занят этой попыткой (rec.CreatedAt).
+	Complete(ctx context.Context, rec IdempotencyRecord) error
+	// Release освобождает ключ, занятый попыткой rec, если ответ не сохранён.
+	Release(ctx context.Context, rec IdempotencyRecord) error
+	// DeleteExpired удаляет записи старше before и возвращает их число.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// OrganizationRepository хранит организации. В отличие от остальных репозиториев,
+// запросы не ограничены организацией из контекста.
+type OrganizationRepository interface {
+	Create(ctx context.Context, org Organization) (Organization, error)
+	// GetBySlug возвращает организацию по короткому имени (ErrNotFound, если такой нет).
+	GetBySlug(ctx context.Context, slug string) (Organization, error)
+	// GetByChatTeamID возвращает организацию по идентификатору рабочего пространства чата.
+	GetByChatTeamID(ctx context.Context, chatTeamID string) (Organization, error)
+	List(ctx context.Context) ([]Organization, error)
+	// SetChatTeamID меняет рабочее пространство чата организации id (пусто — отвязывает).
+	SetChatTeamID(ctx context.Context, id int64, chatTeamID string) (Organization, error)
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "context"
+
+type expectedVersionKey struct{}
+
+// WithExpectedVersion возвращает контекст, в котором изменение PR или настроек команды
+// выполняется, только если их текущая версия равна version (If-Match).
+func WithExpectedVersion(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, expectedVersionKey{}, version)
+}
+
+// ExpectedVersionFrom возвращает ожидаемую версию из контекста (ok == false — не задана).
+func ExpectedVersionFrom(ctx context.Context) (version int64, ok bool) {
+	version, ok = ctx.Value(expectedVersionKey{}).(int64)
+	return version, ok
+}
+
+// CheckVersion возвращает ErrVersionMismatch, если в контексте ожидается другая версия.
+func CheckVersion(ctx context.Context, version int64) error {
+	if expected, ok := ExpectedVersionFrom(ctx); ok && expected != version {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
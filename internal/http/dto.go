@@ -1,12 +1,20 @@
 package httpapi
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
 
 // TeamMemberRequest описывает участника команды в запросе на создание команды.
 type TeamMemberRequest struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID          string `json:"user_id"`
+	Username        string `json:"username"`
+	IsActive        bool   `json:"is_active"`
+	MaxOpenReviews  *int   `json:"max_open_reviews,omitempty"`
+	Seniority       string `json:"seniority,omitempty"`
+	SelectionWeight *int   `json:"selection_weight,omitempty"`
 }
 
 // TeamRequest — тело запроса на создание/обновление команды.
@@ -17,15 +25,60 @@ type TeamRequest struct {
 
 // TeamMemberDTO — участник команды в ответе API.
 type TeamMemberDTO struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID          string `json:"user_id"`
+	Username        string `json:"username"`
+	IsActive        bool   `json:"is_active"`
+	MaxOpenReviews  *int   `json:"max_open_reviews,omitempty"`
+	Seniority       string `json:"seniority,omitempty"`
+	SelectionWeight *int   `json:"selection_weight,omitempty"`
 }
 
 // TeamDTO — команда в ответах API.
 type TeamDTO struct {
+	TeamName string           `json:"team_name"`
+	Settings *TeamSettingsDTO `json:"settings,omitempty"`
+	Members  []TeamMemberDTO  `json:"members"`
+}
+
+// TeamSettingsDTO — настройки назначения ревьюверов команды.
+type TeamSettingsDTO struct {
+	DefaultMaxOpenReviews *int   `json:"default_max_open_reviews"`
+	CapacityPolicy        string `json:"capacity_policy"`
+	PreferWorkingHours    bool   `json:"prefer_working_hours"`
+	PairingWindowDays     int    `json:"pairing_window_days"`
+	SelectionStrategy     string `json:"selection_strategy"`
+	MaxReviewers          int    `json:"max_reviewers"`
+	ReviewSLAHours        int    `json:"review_sla_hours"`
+	StaleAction           string `json:"stale_action"`
+	LeadID                string `json:"lead_id,omitempty"`
+	// ChatWebhookSet — настроен ли вебхук чата; сам адрес содержит секрет и не возвращается.
+	ChatWebhookSet bool   `json:"chat_webhook_set"`
+	ChatChannel    string `json:"chat_channel,omitempty"`
+	// Version — версия настроек для If-Match в /team/setSettings (в виде ETag "<version>").
+	Version int64 `json:"version"`
+}
+
+// SetTeamSettingsRequest — запрос на частичное изменение настроек команды.
+// Неуказанные поля сохраняют текущее значение.
+type SetTeamSettingsRequest struct {
+	TeamName              string           `json:"team_name"`
+	DefaultMaxOpenReviews nullable[int]    `json:"default_max_open_reviews"`
+	CapacityPolicy        *string          `json:"capacity_policy"`
+	PreferWorkingHours    *bool            `json:"prefer_working_hours"`
+	PairingWindowDays     *int             `json:"pairing_window_days"`
+	SelectionStrategy     *string          `json:"selection_strategy"`
+	MaxReviewers          *int             `json:"max_reviewers"`
+	ReviewSLAHours        *int             `json:"review_sla_hours"`
+	StaleAction           *string          `json:"stale_action"`
+	LeadID                nullable[string] `json:"lead_id"`
+	ChatWebhookURL        nullable[string] `json:"chat_webhook_url"`
+	ChatChannel           nullable[string] `json:"chat_channel"`
+}
+
+// SetTeamSettingsResponse — ответ API после изменения настроек команды.
+type SetTeamSettingsResponse struct {
 	TeamName string          `json:"team_name"`
-	Members  []TeamMemberDTO `json:"members"`
+	Settings TeamSettingsDTO `json:"settings"`
 }
 
 // TeamCreateResponse — ответ API при создании команды.
@@ -33,6 +86,48 @@ type TeamCreateResponse struct {
 	Team TeamDTO `json:"team"`
 }
 
+// ReviewRuleDTO — правило назначения ревьюверов команды.
+type ReviewRuleDTO struct {
+	RuleID        int64  `json:"rule_id"`
+	TeamName      string `json:"team_name"`
+	Kind          string `json:"kind"`
+	AuthorID      string `json:"author_id,omitempty"`
+	AuthorLevel   string `json:"author_level,omitempty"`
+	Label         string `json:"label,omitempty"`
+	ReviewerID    string `json:"reviewer_id,omitempty"`
+	ReviewerLevel string `json:"reviewer_level,omitempty"`
+	Description   string `json:"description,omitempty"`
+}
+
+// AddReviewRuleRequest — запрос на добавление правила.
+// Указывается ровно одно из reviewer_id и reviewer_level.
+type AddReviewRuleRequest struct {
+	TeamName      string `json:"team_name"`
+	Kind          string `json:"kind"`
+	AuthorID      string `json:"author_id"`
+	AuthorLevel   string `json:"author_level"`
+	Label         string `json:"label"`
+	ReviewerID    string `json:"reviewer_id"`
+	ReviewerLevel string `json:"reviewer_level"`
+	Description   string `json:"description"`
+}
+
+// DeleteReviewRuleRequest — запрос на удаление правила.
+type DeleteReviewRuleRequest struct {
+	RuleID int64 `json:"rule_id"`
+}
+
+// ReviewRuleResponse — ответ API с одним правилом.
+type ReviewRuleResponse struct {
+	Rule ReviewRuleDTO `json:"rule"`
+}
+
+// ReviewRuleListResponse — ответ API со списком правил команды.
+type ReviewRuleListResponse struct {
+	TeamName string          `json:"team_name"`
+	Rules    []ReviewRuleDTO `json:"rules"`
+}
+
 // SetIsActiveRequest — запрос на изменение активности пользователя.
 type SetIsActiveRequest struct {
 	UserID   string `json:"user_id"`
@@ -41,10 +136,39 @@ type SetIsActiveRequest struct {
 
 // UserDTO — модель пользователя в HTTP-слое.
 type UserDTO struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID         string        `json:"user_id"`
+	Username       string        `json:"username"`
+	TeamName       string        `json:"team_name"`
+	IsActive       bool          `json:"is_active"`
+	MaxOpenReviews *int          `json:"max_open_reviews,omitempty"`
+	Timezone       string        `json:"timezone,omitempty"`
+	WorkHours      *WorkHoursDTO `json:"work_hours,omitempty"`
+	Seniority      string        `json:"seniority,omitempty"`
+	// SelectionWeight — вес при взвешенном выборе: явный или вычисленный по уровню.
+	SelectionWeight int    `json:"selection_weight"`
+	Email           string `json:"email,omitempty"`
+	DigestEnabled   bool   `json:"digest_enabled"`
+	ChatHandle      string `json:"chat_handle,omitempty"`
+}
+
+// WorkHoursDTO — рабочие часы пользователя в его часовом поясе.
+type WorkHoursDTO struct {
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+	Days  []string `json:"days"`
+}
+
+// SetWorkScheduleRequest — запрос на изменение часового пояса и рабочих часов.
+// Отсутствие work_hours означает, что график не задан и пользователь доступен всегда.
+type SetWorkScheduleRequest struct {
+	UserID    string        `json:"user_id"`
+	Timezone  string        `json:"timezone"`
+	WorkHours *WorkHoursDTO `json:"work_hours"`
+}
+
+// SetWorkScheduleResponse — ответ API после изменения графика.
+type SetWorkScheduleResponse struct {
+	User UserDTO `json:"user"`
 }
 
 // SetIsActiveResponse — ответ API после изменения активности пользователя.
@@ -52,11 +176,53 @@ type SetIsActiveResponse struct {
 	User UserDTO `json:"user"`
 }
 
+// SetSeniorityRequest — запрос на изменение уровня и веса пользователя.
+// null в selection_weight означает вес по уровню.
+type SetSeniorityRequest struct {
+	UserID          string `json:"user_id"`
+	Seniority       string `json:"seniority"`
+	SelectionWeight *int   `json:"selection_weight"`
+}
+
+// SetSeniorityResponse — ответ API после изменения уровня.
+type SetSeniorityResponse struct {
+	User UserDTO `json:"user"`
+}
+
+// SetNotificationsRequest — запрос на изменение адреса, имени в чате и подписки на ежедневную сводку.
+// Отсутствующие поля не меняются; null в email или chat_handle удаляет значение.
+type SetNotificationsRequest struct {
+	UserID        string           `json:"user_id"`
+	Email         nullable[string] `json:"email"`
+	ChatHandle    nullable[string] `json:"chat_handle"`
+	DigestEnabled *bool            `json:"digest_enabled"`
+}
+
+// SetNotificationsResponse — ответ API после изменения настроек уведомлений.
+type SetNotificationsResponse struct {
+	User UserDTO `json:"user"`
+}
+
+// SetMaxOpenReviewsRequest — запрос на изменение личного лимита открытых ревью.
+// null в max_open_reviews сбрасывает лимит к значению команды.
+type SetMaxOpenReviewsRequest struct {
+	UserID         string `json:"user_id"`
+	MaxOpenReviews *int   `json:"max_open_reviews"`
+}
+
+// SetMaxOpenReviewsResponse — ответ API после изменения лимита.
+type SetMaxOpenReviewsResponse struct {
+	User UserDTO `json:"user"`
+}
+
 // CreatePRRequest — запрос на создание pull request.
 type CreatePRRequest struct {
-	PullRequestID   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
-	AuthorID        string `json:"author_id"`
+	PullRequestID   string   `json:"pull_request_id"`
+	PullRequestName string   `json:"pull_request_name"`
+	AuthorID        string   `json:"author_id"`
+	Repository      string   `json:"repository,omitempty"`
+	Number          int      `json:"number,omitempty"`
+	Labels          []string `json:"labels,omitempty"`
 }
 
 // PullRequestDTO — модель pull request в HTTP-слое.
@@ -64,15 +230,81 @@ type PullRequestDTO struct {
 	PullRequestID     string     `json:"pull_request_id"`
 	PullRequestName   string     `json:"pull_request_name"`
 	AuthorID          string     `json:"author_id"`
+	Repository        string     `json:"repository,omitempty"`
+	Number            int        `json:"number,omitempty"`
 	Status            string     `json:"status"`
 	AssignedReviewers []string   `json:"assigned_reviewers"`
+	Labels            []string   `json:"labels,omitempty"`
 	CreatedAt         *time.Time `json:"createdAt,omitempty"`
 	MergedAt          *time.Time `json:"mergedAt,omitempty"`
 }
 
 // CreatePRResponse — ответ API после создания pull request.
 type CreatePRResponse struct {
-	PR PullRequestDTO `json:"pr"`
+	PR         PullRequestDTO `json:"pr"`
+	Assignment AssignmentDTO  `json:"assignment"`
+}
+
+// AssignmentDTO объясняет, почему на PR назначены именно эти ревьюеры.
+type AssignmentDTO struct {
+	Strategy   string                  `json:"strategy,omitempty"`
+	Reviewers  []ReviewerAssignmentDTO `json:"reviewers"`
+	Rules      []RuleDecisionDTO       `json:"rules"`
+	Candidates []CandidateDTO          `json:"candidates,omitempty"`
+	// ReevaluatedAt — момент, на который объяснение построено заново по текущим правилам (только в explainAssignment).
+	ReevaluatedAt *time.Time `json:"reevaluated_at,omitempty"`
+}
+
+// CandidateDTO — участник команды при выборе ревьюеров: исключён ли он и как его оценила стратегия.
+type CandidateDTO struct {
+	UserID   string            `json:"user_id"`
+	Eligible bool              `json:"eligible"`
+	Excluded string            `json:"excluded_reason,omitempty"`
+	RuleID   int64             `json:"rule_id,omitempty"`
+	Assigned bool              `json:"assigned"`
+	Score    CandidateScoreDTO `json:"score"`
+}
+
+// CandidateScoreDTO — данные, по которым стратегия упорядочивает кандидатов.
+type CandidateScoreDTO struct {
+	Weight           int  `json:"weight"`
+	Pairings         int  `json:"pairings"`
+	OpenReviews      int  `json:"open_reviews"`
+	MaxOpenReviews   *int `json:"max_open_reviews"`
+	InWorkingHours   bool `json:"in_working_hours"`
+	RotationPosition int  `json:"rotation_position,omitempty"`
+}
+
+// ExplainAssignmentResponse — ответ /pullRequest/explainAssignment.
+type ExplainAssignmentResponse struct {
+	PR         PullRequestDTO `json:"pr"`
+	Assignment AssignmentDTO  `json:"assignment"`
+}
+
+// ReviewerAssignmentDTO — причина назначения одного ревьюера.
+type ReviewerAssignmentDTO struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+	RuleID int64  `json:"rule_id,omitempty"`
+}
+
+// RuleDecisionDTO — результат применения правила команды.
+type RuleDecisionDTO struct {
+	RuleID        int64  `json:"rule_id"`
+	Kind          string `json:"kind"`
+	UserID        string `json:"user_id,omitempty"`
+	ReviewerLevel string `json:"reviewer_level,omitempty"`
+	Outcome       string `json:"outcome"`
+	Description   string `json:"description,omitempty"`
+}
+
+// PreviewReviewersResponse — ответ /pullRequest/previewReviewers: кто был бы назначен на PR.
+// Запрос совпадает с CreatePRRequest.
+type PreviewReviewersResponse struct {
+	PullRequestID     string        `json:"pull_request_id,omitempty"`
+	AuthorID          string        `json:"author_id"`
+	AssignedReviewers []string      `json:"assigned_reviewers"`
+	Assignment        AssignmentDTO `json:"assignment"`
 }
 
 // MergePRRequest — запрос на пометку pull request как слитого (merged).
@@ -80,15 +312,54 @@ type MergePRRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 }
 
+// PullRequestResponse — ответ API с одним PR.
+type PullRequestResponse struct {
+	PR PullRequestDTO `json:"pr"`
+}
+
 // MergePRResponse — ответ API после успешного merge PR.
 type MergePRResponse struct {
 	PR PullRequestDTO `json:"pr"`
 }
 
+// ChangeReviewerRequest — запрос на ручное добавление или снятие ревьюера.
+// actor_id — кто вносит изменение (записывается в историю назначений).
+type ChangeReviewerRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+	ActorID       string `json:"actor_id"`
+}
+
+// VolunteerRequest — запрос пользователя на добровольное ревью PR.
+type VolunteerRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+}
+
+// ChangeReviewerResponse — ответ API после ручного изменения списка ревьюеров.
+type ChangeReviewerResponse struct {
+	PR PullRequestDTO `json:"pr"`
+}
+
 // ReassignRequest — запрос на переназначение ревьюера.
+// NewUserID необязателен: без него замена выбирается по стратегии команды.
 type ReassignRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_user_id"`
+	NewUserID     string `json:"new_user_id,omitempty"`
+}
+
+// DeclineRequest — запрос ревьюера на отказ от назначенного ревью.
+type DeclineRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+	Reason        string `json:"reason"`
+}
+
+// DeclineResponse — ответ API после отказа; ReplacedBy пуст, если замены не нашлось.
+type DeclineResponse struct {
+	PR         PullRequestDTO `json:"pr"`
+	ReplacedBy string         `json:"replaced_by,omitempty"`
 }
 
 // ReassignResponse — ответ API после переназначения ревьюера.
@@ -107,17 +378,225 @@ type PullRequestShortDTO struct {
 
 // UserReviewResponse — ответ API со списком PR для ревью пользователя.
 type UserReviewResponse struct {
-	UserID       string                `json:"user_id"`
-	PullRequests []PullRequestShortDTO `json:"pull_requests"`
+	UserID         string                `json:"user_id"`
+	PullRequests   []PullRequestShortDTO `json:"pull_requests"`
+	OpenReviews    int                   `json:"open_reviews"`
+	MaxOpenReviews *int                  `json:"max_open_reviews"`
 }
 
 // UserAssignmentStatDTO — статистика назначений на ревью по пользователю.
 type UserAssignmentStatDTO struct {
 	UserID      string `json:"user_id"`
 	Assignments int64  `json:"assignments"`
+	Declines    int64  `json:"declines"`
 }
 
 // StatsAssignmentsResponse — ответ API со статистикой назначений.
 type StatsAssignmentsResponse struct {
 	Stats []UserAssignmentStatDTO `json:"stats"`
 }
+
+// EventDTO — событие сервиса для внешних потребителей.
+type EventDTO struct {
+	EventID       int64             `json:"event_id"`
+	Type          string            `json:"type"`
+	TeamName      string            `json:"team_name"`
+	PullRequestID string            `json:"pull_request_id,omitempty"`
+	UserID        string            `json:"user_id,omitempty"`
+	Payload       map[string]string `json:"payload"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// EventsResponse — ответ API со страницей событий.
+type EventsResponse struct {
+	Events []EventDTO `json:"events"`
+}
+
+// MarkReviewedRequest — отметка ревьювера о том, что он взялся за ревью.
+type MarkReviewedRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+}
+
+// AbsenceDTO — плановое отсутствие пользователя в HTTP-слое.
+type AbsenceDTO struct {
+	AbsenceID   int64     `json:"absence_id"`
+	UserID      string    `json:"user_id"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Reason      string    `json:"reason"`
+	Source      string    `json:"source"`
+	ExternalUID string    `json:"external_uid,omitempty"`
+}
+
+// AddAbsenceRequest — запрос на добавление отсутствия.
+type AddAbsenceRequest struct {
+	UserID   string    `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+// UpdateAbsenceRequest — запрос на изменение отсутствия.
+type UpdateAbsenceRequest struct {
+	AbsenceID int64     `json:"absence_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    string    `json:"reason"`
+}
+
+// DeleteAbsenceRequest — запрос на удаление отсутствия.
+type DeleteAbsenceRequest struct {
+	AbsenceID int64 `json:"absence_id"`
+}
+
+// AbsenceResponse — ответ API с одним отсутствием.
+type AbsenceResponse struct {
+	Absence AbsenceDTO `json:"absence"`
+}
+
+// AbsenceListResponse — ответ API со списком отсутствий пользователя.
+type AbsenceListResponse struct {
+	UserID   string       `json:"user_id"`
+	Absences []AbsenceDTO `json:"absences"`
+}
+
+// nullable различает отсутствующее в JSON поле и явно переданный null.
+type nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// UnmarshalJSON вызывается только для присутствующих полей, поэтому выставляет Set.
+func (n *nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+
+	if string(data) == "null" {
+		n.Value = nil
+		return nil
+	}
+
+	var v T
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	n.Value = &v
+	return nil
+}
+
+func (n nullable[T]) toDomain() domain.Optional[T] {
+	return domain.Optional[T]{Set: n.Set, Value: n.Value}
+}
+
+// APIKeyDTO — ключ API без его значения.
+type APIKeyDTO struct {
+	KeyID      int64      `json:"key_id"`
+	Name       string     `json:"name"`
+	Role       string     `json:"role"`
+	UserID     string     `json:"user_id,omitempty"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyRequest — запрос на выпуск ключа API.
+type CreateAPIKeyRequest struct {
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+	UserID string `json:"user_id"`
+	// Org — короткое имя организации ключа (только для оператора платформы).
+	Org string `json:"org"`
+}
+
+// CreateAPIKeyResponse — выпущенный ключ; его значение (secret) показывается только в этом ответе.
+type CreateAPIKeyResponse struct {
+	Key    APIKeyDTO `json:"key"`
+	Secret string    `json:"secret"`
+}
+
+// APIKeysResponse — список ключей API.
+type APIKeysResponse struct {
+	Keys []APIKeyDTO `json:"keys"`
+}
+
+// RevokeAPIKeyRequest — запрос на отзыв ключа API.
+type RevokeAPIKeyRequest struct {
+	KeyID int64 `json:"key_id"`
+}
+
+// RevokeAPIKeyResponse — ответ API после отзыва ключа.
+type RevokeAPIKeyResponse struct {
+	Key APIKeyDTO `json:"key"`
+}
+
+// OrganizationDTO — организация.
+type OrganizationDTO struct {
+	Slug       string    `json:"slug"`
+	Name       string    `json:"name"`
+	ChatTeamID string    `json:"chat_team_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CreateOrganizationRequest — запрос на создание организации.
+type CreateOrganizationRequest struct {
+	Slug       string `json:"slug"`
+	Name       string `json:"name"`
+	ChatTeamID string `json:"chat_team_id"`
+}
+
+// SetOrganizationChatTeamRequest — запрос на привязку организации к рабочему пространству чата.
+type SetOrganizationChatTeamRequest struct {
+	Slug       string `json:"slug"`
+	ChatTeamID string `json:"chat_team_id"`
+}
+
+// OrganizationResponse — ответ API с организацией.
+type OrganizationResponse struct {
+	Organization OrganizationDTO `json:"organization"`
+}
+
+// OrganizationsResponse — список организаций.
+type OrganizationsResponse struct {
+	Organizations []OrganizationDTO `json:"organizations"`
+}
+
+// RepositoryDTO — репозиторий кода команды.
+type RepositoryDTO struct {
+	Name              string    `json:"name"`
+	CodeHost          string    `json:"code_host"`
+	TeamName          string    `json:"team_name"`
+	ReviewerTeam      string    `json:"reviewer_team,omitempty"`
+	SelectionStrategy string    `json:"selection_strategy,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// CreateRepositoryRequest — запрос на добавление репозитория.
+type CreateRepositoryRequest struct {
+	Name              string `json:"name"`
+	CodeHost          string `json:"code_host"`
+	TeamName          string `json:"team_name"`
+	ReviewerTeam      string `json:"reviewer_team,omitempty"`
+	SelectionStrategy string `json:"selection_strategy,omitempty"`
+}
+
+// SetRepositoryOverridesRequest — частичное изменение переопределений репозитория:
+// отсутствующие поля не меняются, null сбрасывает переопределение.
+type SetRepositoryOverridesRequest struct {
+	Name              string                             `json:"name"`
+	ReviewerTeam      nullable[string]                   `json:"reviewer_team"`
+	SelectionStrategy nullable[domain.SelectionStrategy] `json:"selection_strategy"`
+}
+
+// RepositoryResponse — ответ API с репозиторием.
+type RepositoryResponse struct {
+	Repository RepositoryDTO `json:"repository"`
+}
+
+// RepositoriesResponse — список репозиториев.
+type RepositoriesResponse struct {
+	Repositories []RepositoryDTO `json:"repositories"`
+}
@@ -32,18 +32,41 @@ func WriteError(w http.ResponseWriter, err error) {
 		}
 
 		switch derr.Code {
-		case domain.ErrorCodeTeamExists:
+		case domain.ErrorCodeTeamExists,
+			domain.ErrorCodeValidation:
 			status = http.StatusBadRequest
 
 		case domain.ErrorCodePRExists,
 			domain.ErrorCodePRMerged,
 			domain.ErrorCodeNotAssigned,
-			domain.ErrorCodeNoCandidate:
+			domain.ErrorCodeNoCandidate,
+			domain.ErrorCodeCapacityExceeded,
+			domain.ErrorCodeAlreadyAssigned,
+			domain.ErrorCodeTooManyReviewers,
+			domain.ErrorCodeReviewerIsAuthor,
+			domain.ErrorCodeReviewerInactive,
+			domain.ErrorCodeReviewerNotInTeam,
+			domain.ErrorCodeReviewerExcluded,
+			domain.ErrorCodeOrgExists,
+			domain.ErrorCodeRepositoryExists,
+			domain.ErrorCodeIdempotencyKeyInFlight:
 			status = http.StatusConflict
 
+		case domain.ErrorCodeIdempotencyKeyReused:
+			status = http.StatusUnprocessableEntity
+
+		case domain.ErrorCodePreconditionFailed:
+			status = http.StatusPreconditionFailed
+
 		case domain.ErrorCodeNotFound:
 			status = http.StatusNotFound
 
+		case domain.ErrorCodeUnauthorized:
+			status = http.StatusUnauthorized
+
+		case domain.ErrorCodeForbidden:
+			status = http.StatusForbidden
+
 		default:
 			status = http.StatusInternalServerError
 		}
@@ -0,0 +1,180 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/service"
+)
+
+// maxCalendarSize ограничивает размер загружаемого iCalendar-файла.
+const maxCalendarSize = 1 << 20
+
+// AbsenceHandlers содержит HTTP-обработчики плановых отсутствий пользователей.
+type AbsenceHandlers struct {
+	svc *service.AbsenceService
+}
+
+// NewAbsenceHandlers создаёт набор HTTP-обработчиков отсутствий.
+func NewAbsenceHandlers(svc *service.AbsenceService) *AbsenceHandlers {
+	return &AbsenceHandlers{svc: svc}
+}
+
+// AddAbsence обрабатывает добавление отсутствия пользователя.
+func (h *AbsenceHandlers) AddAbsence(w http.ResponseWriter, r *http.Request) {
+	var req AddAbsenceRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	absence, err := h.svc.AddAbsence(r.Context(), req.UserID, req.StartsAt, req.EndsAt, req.Reason)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(AbsenceResponse{Absence: mapAbsenceToDTO(absence)})
+}
+
+// UpdateAbsence обрабатывает изменение отсутствия.
+func (h *AbsenceHandlers) UpdateAbsence(w http.ResponseWriter, r *http.Request) {
+	var req UpdateAbsenceRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	absence, err := h.svc.UpdateAbsence(r.Context(), req.AbsenceID, req.StartsAt, req.EndsAt, req.Reason)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(AbsenceResponse{Absence: mapAbsenceToDTO(absence)})
+}
+
+// DeleteAbsence обрабатывает удаление отсутствия.
+func (h *AbsenceHandlers) DeleteAbsence(w http.ResponseWriter, r *http.Request) {
+	var req DeleteAbsenceRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	absence, err := h.svc.DeleteAbsence(r.Context(), req.AbsenceID)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(AbsenceResponse{Absence: mapAbsenceToDTO(absence)})
+}
+
+// ListAbsences возвращает отсутствия пользователя.
+func (h *AbsenceHandlers) ListAbsences(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		WriteError(w, &domain.DomainError{
+			Code: domain.ErrorCodeNotFound,
+			Err:  domain.ErrNotFound,
+		})
+
+		return
+	}
+
+	absences, err := h.svc.ListAbsences(r.Context(), userID)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(AbsenceListResponse{
+		UserID:   userID,
+		Absences: mapAbsencesToDTO(absences),
+	})
+}
+
+// ImportICS импортирует отсутствия из iCalendar-файла.
+// Файл передаётся полем file в multipart/form-data либо телом запроса с типом text/calendar.
+func (h *AbsenceHandlers) ImportICS(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		WriteError(w, &domain.DomainError{
+			Code: domain.ErrorCodeNotFound,
+			Err:  domain.ErrNotFound,
+		})
+
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCalendarSize)
+
+	var calendar io.Reader = r.Body
+
+	if err := r.ParseMultipartForm(maxCalendarSize); err == nil {
+		file, _, err := r.FormFile("file")
+
+		if err != nil {
+			WriteError(w, domain.NewDomainError(domain.ErrorCodeValidation, domain.ErrInvalidInput))
+			return
+		}
+
+		defer func() {
+			_ = file.Close()
+		}()
+
+		calendar = file
+	}
+
+	absences, err := h.svc.ImportICS(r.Context(), userID, calendar)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(AbsenceListResponse{
+		UserID:   userID,
+		Absences: mapAbsencesToDTO(absences),
+	})
+}
+
+func mapAbsenceToDTO(a domain.Absence) AbsenceDTO {
+	return AbsenceDTO{
+		AbsenceID:   a.ID,
+		UserID:      a.UserID,
+		StartsAt:    a.StartsAt,
+		EndsAt:      a.EndsAt,
+		Reason:      a.Reason,
+		Source:      string(a.Source),
+		ExternalUID: a.ExternalUID,
+	}
+}
+
+func mapAbsencesToDTO(absences []domain.Absence) []AbsenceDTO {
+	res := make([]AbsenceDTO, 0, len(absences))
+
+	for _, a := range absences {
+		res = append(res, mapAbsenceToDTO(a))
+	}
+
+	return res
+}
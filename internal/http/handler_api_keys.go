@@ -0,0 +1,92 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/service"
+)
+
+// APIKeyHandlers содержит HTTP-обработчики управления ключами API.
+type APIKeyHandlers struct {
+	svc *service.APIKeyService
+}
+
+// NewAPIKeyHandlers создаёт набор HTTP-обработчиков ключей API.
+func NewAPIKeyHandlers(svc *service.APIKeyService) *APIKeyHandlers {
+	return &APIKeyHandlers{svc: svc}
+}
+
+// CreateKey выпускает ключ API.
+func (h *APIKeyHandlers) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	key, secret, err := h.svc.CreateKey(r.Context(), req.Name, domain.Role(req.Role), req.UserID, req.Org)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(CreateAPIKeyResponse{Key: mapAPIKeyToDTO(key), Secret: secret})
+}
+
+// ListKeys возвращает все ключи API без их значений.
+func (h *APIKeyHandlers) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.svc.ListKeys(r.Context())
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := APIKeysResponse{Keys: make([]APIKeyDTO, 0, len(keys))}
+
+	for _, k := range keys {
+		resp.Keys = append(resp.Keys, mapAPIKeyToDTO(k))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// RevokeKey отзывает ключ API.
+func (h *APIKeyHandlers) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	var req RevokeAPIKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	key, err := h.svc.RevokeKey(r.Context(), req.KeyID)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(RevokeAPIKeyResponse{Key: mapAPIKeyToDTO(key)})
+}
+
+func mapAPIKeyToDTO(k domain.APIKey) APIKeyDTO {
+	return APIKeyDTO{
+		KeyID:      k.ID,
+		Name:       k.Name,
+		Role:       string(k.Role),
+		UserID:     k.UserID,
+		Prefix:     k.Prefix,
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
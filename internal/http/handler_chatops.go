@@ -0,0 +1,126 @@
+package httpapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/service"
+)
+
+const (
+	// slackMaxClockSkew — насколько метка времени запроса может отличаться от текущего времени
+	// (защита от повторной отправки перехваченного запроса).
+	slackMaxClockSkew = 5 * time.Minute
+	// slackMaxBodySize — ограничение размера тела slash-команды.
+	slackMaxBodySize = 64 << 10
+)
+
+// ChatOpsHandlers принимает slash-команды Slack (и совместимого с ним Mattermost).
+type ChatOpsHandlers struct {
+	svc           *service.ChatOpsService
+	orgSvc        *service.OrganizationService
+	signingSecret string
+	now           func() time.Time
+}
+
+// NewChatOpsHandlers создаёт обработчики slash-команд с проверкой подписи по signingSecret.
+func NewChatOpsHandlers(
+	svc *service.ChatOpsService,
+	orgSvc *service.OrganizationService,
+	signingSecret string,
+) *ChatOpsHandlers {
+	return &ChatOpsHandlers{svc: svc, orgSvc: orgSvc, signingSecret: signingSecret, now: time.Now}
+}
+
+// SlashResponse — ответ на slash-команду в формате Slack; ephemeral виден только вызвавшему.
+type SlashResponse struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// Slash обрабатывает form-запрос slash-команды. Ошибки бизнес-логики возвращаются
+// текстом ответа со статусом 200, иначе чат показал бы пользователю только общий сбой.
+func (h *ChatOpsHandlers) Slash(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, slackMaxBodySize))
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	if err := verifySlackSignature(h.signingSecret, r.Header, body, h.now()); err != nil {
+		WriteError(w, domain.NewDomainError(domain.ErrorCodeUnauthorized, err))
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+
+	if err != nil {
+		WriteError(w, domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("invalid form body: %w", domain.ErrInvalidInput)))
+
+		return
+	}
+
+	// Команда выполняется в организации рабочего пространства чата, из которого пришла
+	orgID, err := h.orgSvc.ChatOrganization(r.Context(), form.Get("team_id"))
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	text, err := h.svc.Execute(domain.WithOrganization(r.Context(), orgID), service.ChatCommand{
+		ChatUserID: form.Get("user_id"),
+		Text:       form.Get("text"),
+	}, h.now().UTC())
+
+	var derr *domain.DomainError
+
+	if errors.As(err, &derr) {
+		text, err = "Не получилось: "+derr.Error(), nil
+	}
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(SlashResponse{ResponseType: "ephemeral", Text: text})
+}
+
+// verifySlackSignature проверяет подпись X-Slack-Signature: "v0=" + HMAC-SHA256 секрета
+// от "v0:<X-Slack-Request-Timestamp>:<тело>", и что метка времени не старше slackMaxClockSkew.
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+
+	if err != nil {
+		return fmt.Errorf("missing or invalid request timestamp: %w", domain.ErrUnauthorized)
+	}
+
+	if skew := now.Sub(time.Unix(sec, 0)); skew > slackMaxClockSkew || skew < -slackMaxClockSkew {
+		return fmt.Errorf("request timestamp is too old: %w", domain.ErrUnauthorized)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("invalid request signature: %w", domain.ErrUnauthorized)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/service"
+)
+
+// EventHandlers содержит HTTP-обработчики для чтения событий сервиса.
+type EventHandlers struct {
+	svc *service.EventService
+}
+
+// NewEventHandlers создаёт набор HTTP-обработчиков событий.
+func NewEventHandlers(svc *service.EventService) *EventHandlers {
+	return &EventHandlers{svc: svc}
+}
+
+// ListEvents возвращает события после after_id (необязательно фильтруя по team_name).
+func (h *EventHandlers) ListEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	afterID, err := parseIntParam(q.Get("after_id"))
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	limit, err := parseIntParam(q.Get("limit"))
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	events, err := h.svc.List(r.Context(), q.Get("team_name"), afterID, int(limit))
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := EventsResponse{Events: make([]EventDTO, 0, len(events))}
+
+	for _, e := range events {
+		resp.Events = append(resp.Events, EventDTO{
+			EventID:       e.ID,
+			Type:          string(e.Type),
+			TeamName:      e.TeamName,
+			PullRequestID: e.PRID,
+			UserID:        e.UserID,
+			Payload:       e.Payload,
+			CreatedAt:     e.CreatedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// parseIntParam разбирает необязательный числовой query-параметр (пустой — 0).
+func parseIntParam(v string) (int64, error) {
+	if v == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+
+	if err != nil {
+		return 0, domain.NewDomainError(domain.ErrorCodeValidation, fmt.Errorf("invalid number %q: %w", v, domain.ErrInvalidInput))
+	}
+
+	return n, nil
+}
@@ -0,0 +1,88 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/service"
+)
+
+// OrganizationHandlers содержит HTTP-обработчики управления организациями.
+type OrganizationHandlers struct {
+	svc *service.OrganizationService
+}
+
+// NewOrganizationHandlers создаёт набор HTTP-обработчиков организаций.
+func NewOrganizationHandlers(svc *service.OrganizationService) *OrganizationHandlers {
+	return &OrganizationHandlers{svc: svc}
+}
+
+// CreateOrganization заводит организацию.
+func (h *OrganizationHandlers) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var req CreateOrganizationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	org, err := h.svc.CreateOrganization(r.Context(), req.Slug, req.Name, req.ChatTeamID)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(OrganizationResponse{Organization: mapOrganizationToDTO(org)})
+}
+
+// SetChatTeam связывает организацию с рабочим пространством чата для slash-команд.
+func (h *OrganizationHandlers) SetChatTeam(w http.ResponseWriter, r *http.Request) {
+	var req SetOrganizationChatTeamRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	org, err := h.svc.SetChatTeam(r.Context(), req.Slug, req.ChatTeamID)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(OrganizationResponse{Organization: mapOrganizationToDTO(org)})
+}
+
+// ListOrganizations возвращает все организации.
+func (h *OrganizationHandlers) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	orgs, err := h.svc.ListOrganizations(r.Context())
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := OrganizationsResponse{Organizations: make([]OrganizationDTO, 0, len(orgs))}
+
+	for _, o := range orgs {
+		resp.Organizations = append(resp.Organizations, mapOrganizationToDTO(o))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func mapOrganizationToDTO(o domain.Organization) OrganizationDTO {
+	return OrganizationDTO{
+		Slug:       o.Slug,
+		Name:       o.Name,
+		ChatTeamID: o.ChatTeamID,
+		CreatedAt:  o.CreatedAt,
+	}
+}
@@ -3,6 +3,7 @@ package httpapi
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"pr-reviewer-service/internal/domain"
 	"pr-reviewer-service/internal/service"
@@ -27,7 +28,10 @@ func (h *PullRequestHandlers) CreatePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := h.svc.CreatePR(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID)
+	explain, _ := strconv.ParseBool(r.URL.Query().Get("explain"))
+
+	pr, explanation, err := h.svc.CreatePR(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID,
+		req.Repository, req.Number, req.Labels, explain)
 
 	if err != nil {
 		WriteError(w, err)
@@ -35,14 +39,45 @@ func (h *PullRequestHandlers) CreatePR(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := CreatePRResponse{
-		PR: mapPRToDTO(pr),
+		PR:         mapPRToDTO(pr),
+		Assignment: mapExplanationToDTO(explanation),
 	}
 
+	setETag(w, pr.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+// PreviewReviewers показывает, кто был бы назначен на PR, ничего не создавая.
+func (h *PullRequestHandlers) PreviewReviewers(w http.ResponseWriter, r *http.Request) {
+	var req CreatePRRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	explain, _ := strconv.ParseBool(r.URL.Query().Get("explain"))
+
+	reviewers, explanation, err := h.svc.PreviewReviewers(r.Context(), req.AuthorID, req.Repository, req.Labels, explain)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := PreviewReviewersResponse{
+		PullRequestID:     req.PullRequestID,
+		AuthorID:          req.AuthorID,
+		AssignedReviewers: reviewers,
+		Assignment:        mapExplanationToDTO(explanation),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
 // MergePR обрабатывает запрос на пометку pull request как merged.
 func (h *PullRequestHandlers) MergePR(w http.ResponseWriter, r *http.Request) {
 	var req MergePRRequest
@@ -63,6 +98,7 @@ func (h *PullRequestHandlers) MergePR(w http.ResponseWriter, r *http.Request) {
 		PR: mapPRToDTO(pr),
 	}
 
+	setETag(w, pr.Version)
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
@@ -76,7 +112,7 @@ func (h *PullRequestHandlers) ReassignReviewer(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	pr, replacedBy, err := h.svc.ReassignReviewer(r.Context(), req.PullRequestID, req.OldUserID)
+	pr, replacedBy, err := h.svc.ReassignReviewer(r.Context(), req.PullRequestID, req.OldUserID, req.NewUserID)
 
 	if err != nil {
 		WriteError(w, err)
@@ -88,6 +124,170 @@ func (h *PullRequestHandlers) ReassignReviewer(w http.ResponseWriter, r *http.Re
 		ReplacedBy: replacedBy,
 	}
 
+	setETag(w, pr.Version)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// DeclineReview обрабатывает отказ ревьюера от назначенного PR с автоматической заменой.
+func (h *PullRequestHandlers) DeclineReview(w http.ResponseWriter, r *http.Request) {
+	var req DeclineRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	pr, replacedBy, err := h.svc.DeclineReview(r.Context(), req.PullRequestID, req.UserID, req.Reason)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	setETag(w, pr.Version)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(DeclineResponse{
+		PR:         mapPRToDTO(pr),
+		ReplacedBy: replacedBy,
+	})
+}
+
+// MarkReviewed отмечает, что ревьювер взялся за ревью PR.
+func (h *PullRequestHandlers) MarkReviewed(w http.ResponseWriter, r *http.Request) {
+	var req MarkReviewedRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	pr, err := h.svc.MarkReviewed(r.Context(), req.PullRequestID, req.UserID)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	setETag(w, pr.Version)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ChangeReviewerResponse{PR: mapPRToDTO(pr)})
+}
+
+// AddReviewer вручную назначает ревьюера на PR.
+func (h *PullRequestHandlers) AddReviewer(w http.ResponseWriter, r *http.Request) {
+	var req ChangeReviewerRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	pr, err := h.svc.AddReviewer(r.Context(), req.PullRequestID, req.UserID, req.ActorID)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	setETag(w, pr.Version)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ChangeReviewerResponse{PR: mapPRToDTO(pr)})
+}
+
+// RemoveReviewer снимает ревьюера с PR без подбора замены.
+func (h *PullRequestHandlers) RemoveReviewer(w http.ResponseWriter, r *http.Request) {
+	var req ChangeReviewerRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	pr, err := h.svc.RemoveReviewer(r.Context(), req.PullRequestID, req.UserID, req.ActorID)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	setETag(w, pr.Version)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ChangeReviewerResponse{PR: mapPRToDTO(pr)})
+}
+
+// Volunteer назначает пользователя ревьюером PR по его просьбе.
+func (h *PullRequestHandlers) Volunteer(w http.ResponseWriter, r *http.Request) {
+	var req VolunteerRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	pr, err := h.svc.Volunteer(r.Context(), req.PullRequestID, req.UserID)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	setETag(w, pr.Version)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ChangeReviewerResponse{PR: mapPRToDTO(pr)})
+}
+
+// GetPR возвращает pull request; ETag ответа — его версия для If-Match.
+func (h *PullRequestHandlers) GetPR(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+
+	if prID == "" {
+		WriteError(w, &domain.DomainError{
+			Code: domain.ErrorCodeNotFound,
+			Err:  domain.ErrNotFound,
+		})
+
+		return
+	}
+
+	pr, err := h.svc.GetPR(r.Context(), prID)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	setETag(w, pr.Version)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(PullRequestResponse{PR: mapPRToDTO(pr)})
+}
+
+// ExplainAssignment объясняет, почему на PR назначены текущие ревьюеры и кто мог бы быть выбран.
+func (h *PullRequestHandlers) ExplainAssignment(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+
+	if prID == "" {
+		WriteError(w, &domain.DomainError{
+			Code: domain.ErrorCodeNotFound,
+			Err:  domain.ErrNotFound,
+		})
+
+		return
+	}
+
+	pr, explanation, err := h.svc.ExplainAssignment(r.Context(), prID)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := ExplainAssignmentResponse{
+		PR:         mapPRToDTO(pr),
+		Assignment: mapExplanationToDTO(explanation),
+	}
+
+	setETag(w, pr.Version)
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
@@ -97,9 +297,60 @@ func mapPRToDTO(pr domain.PullRequest) PullRequestDTO {
 		PullRequestID:     pr.ID,
 		PullRequestName:   pr.Name,
 		AuthorID:          pr.AuthorID,
+		Repository:        pr.Repository,
+		Number:            pr.Number,
 		Status:            string(pr.Status),
 		AssignedReviewers: pr.AssignedReviewers,
+		Labels:            pr.Labels,
 		CreatedAt:         pr.CreatedAt,
 		MergedAt:          pr.MergedAt,
 	}
 }
+
+func mapExplanationToDTO(e domain.AssignmentExplanation) AssignmentDTO {
+	res := AssignmentDTO{
+		Strategy:      string(e.Strategy),
+		Reviewers:     make([]ReviewerAssignmentDTO, 0, len(e.Reviewers)),
+		Rules:         make([]RuleDecisionDTO, 0, len(e.Rules)),
+		ReevaluatedAt: e.ReevaluatedAt,
+	}
+
+	for _, r := range e.Reviewers {
+		res.Reviewers = append(res.Reviewers, ReviewerAssignmentDTO{
+			UserID: r.UserID,
+			Reason: string(r.Reason),
+			RuleID: r.RuleID,
+		})
+	}
+
+	for _, d := range e.Rules {
+		res.Rules = append(res.Rules, RuleDecisionDTO{
+			RuleID:        d.RuleID,
+			Kind:          string(d.Kind),
+			UserID:        d.UserID,
+			ReviewerLevel: string(d.ReviewerLevel),
+			Outcome:       string(d.Outcome),
+			Description:   d.Description,
+		})
+	}
+
+	for _, c := range e.Candidates {
+		res.Candidates = append(res.Candidates, CandidateDTO{
+			UserID:   c.UserID,
+			Eligible: c.Excluded == "",
+			Excluded: string(c.Excluded),
+			RuleID:   c.RuleID,
+			Assigned: c.Assigned,
+			Score: CandidateScoreDTO{
+				Weight:           c.Score.Weight,
+				Pairings:         c.Score.Pairings,
+				OpenReviews:      c.Score.OpenReviews,
+				MaxOpenReviews:   c.Score.MaxOpenReviews,
+				InWorkingHours:   c.Score.InWorkingHours,
+				RotationPosition: c.Score.RotationPosition,
+			},
+		})
+	}
+
+	return res
+}
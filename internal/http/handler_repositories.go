@@ -0,0 +1,124 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/service"
+)
+
+// RepositoryHandlers содержит HTTP-обработчики репозиториев кода.
+type RepositoryHandlers struct {
+	svc *service.RepoService
+}
+
+// NewRepositoryHandlers создаёт набор HTTP-обработчиков репозиториев.
+func NewRepositoryHandlers(svc *service.RepoService) *RepositoryHandlers {
+	return &RepositoryHandlers{svc: svc}
+}
+
+// AddRepository добавляет репозиторий команды.
+func (h *RepositoryHandlers) AddRepository(w http.ResponseWriter, r *http.Request) {
+	var req CreateRepositoryRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	repo, err := h.svc.AddRepository(r.Context(), domain.Repo{
+		Name:         req.Name,
+		CodeHost:     domain.CodeHost(req.CodeHost),
+		TeamName:     req.TeamName,
+		ReviewerTeam: req.ReviewerTeam,
+		Strategy:     domain.SelectionStrategy(req.SelectionStrategy),
+	})
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(RepositoryResponse{Repository: mapRepositoryToDTO(repo)})
+}
+
+// GetRepository возвращает репозиторий по имени.
+func (h *RepositoryHandlers) GetRepository(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+
+	if name == "" {
+		WriteError(w, &domain.DomainError{
+			Code: domain.ErrorCodeNotFound,
+			Err:  domain.ErrNotFound,
+		})
+
+		return
+	}
+
+	repo, err := h.svc.GetRepository(r.Context(), name)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(RepositoryResponse{Repository: mapRepositoryToDTO(repo)})
+}
+
+// ListRepositories возвращает репозитории, при необходимости только команды team_name.
+func (h *RepositoryHandlers) ListRepositories(w http.ResponseWriter, r *http.Request) {
+	repos, err := h.svc.ListRepositories(r.Context(), r.URL.Query().Get("team_name"))
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := RepositoriesResponse{Repositories: make([]RepositoryDTO, 0, len(repos))}
+
+	for _, repo := range repos {
+		resp.Repositories = append(resp.Repositories, mapRepositoryToDTO(repo))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// SetOverrides изменяет команду ревьюверов и стратегию выбора репозитория.
+func (h *RepositoryHandlers) SetOverrides(w http.ResponseWriter, r *http.Request) {
+	var req SetRepositoryOverridesRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	repo, err := h.svc.SetOverrides(r.Context(), req.Name, domain.RepoOverridesUpdate{
+		ReviewerTeam: req.ReviewerTeam.toDomain(),
+		Strategy:     req.SelectionStrategy.toDomain(),
+	})
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(RepositoryResponse{Repository: mapRepositoryToDTO(repo)})
+}
+
+func mapRepositoryToDTO(repo domain.Repo) RepositoryDTO {
+	return RepositoryDTO{
+		Name:              repo.Name,
+		CodeHost:          string(repo.CodeHost),
+		TeamName:          repo.TeamName,
+		ReviewerTeam:      repo.ReviewerTeam,
+		SelectionStrategy: string(repo.Strategy),
+		CreatedAt:         repo.CreatedAt,
+		UpdatedAt:         repo.UpdatedAt,
+	}
+}
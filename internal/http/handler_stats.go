@@ -17,9 +17,10 @@ func NewStatsHandlers(svc *service.StatsService) *StatsHandlers {
 	return &StatsHandlers{svc: svc}
 }
 
-// GetAssignmentsByUser возвращает статистику назначений на ревью по пользователям.
+// GetAssignmentsByUser возвращает статистику назначений на ревью по пользователям
+// (необязательный параметр team_name ограничивает её одной командой).
 func (h *StatsHandlers) GetAssignmentsByUser(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.svc.GetAssignmentsByUser(r.Context())
+	stats, err := h.svc.GetAssignmentsByUser(r.Context(), r.URL.Query().Get("team_name"))
 
 	if err != nil {
 		WriteError(w, err)
@@ -34,6 +35,7 @@ func (h *StatsHandlers) GetAssignmentsByUser(w http.ResponseWriter, r *http.Requ
 		resp.Stats = append(resp.Stats, UserAssignmentStatDTO{
 			UserID:      s.UserID,
 			Assignments: s.Count,
+			Declines:    s.Declines,
 		})
 	}
 
@@ -31,10 +31,13 @@ func (h *TeamHandlers) CreateTeam(w http.ResponseWriter, r *http.Request) {
 
 	for _, m := range req.Members {
 		members = append(members, domain.User{
-			ID:       m.UserID,
-			Username: m.Username,
-			TeamName: req.TeamName,
-			IsActive: m.IsActive,
+			ID:             m.UserID,
+			Username:       m.Username,
+			TeamName:       req.TeamName,
+			IsActive:       m.IsActive,
+			MaxOpenReviews: m.MaxOpenReviews,
+			Seniority:      domain.Seniority(m.Seniority),
+			Weight:         m.SelectionWeight,
 		})
 	}
 
@@ -46,18 +49,17 @@ func (h *TeamHandlers) CreateTeam(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := TeamCreateResponse{
-		Team: TeamDTO{
-			TeamName: team.Name,
-			Members:  mapUsersToTeamMembers(team.Members),
-		},
+		Team: mapTeamToDTO(team),
 	}
 
+	setContentETag(w, resp.Team)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
-// GetTeam возвращает описание команды по имени.
+// GetTeam возвращает описание команды по имени. ETag ответа вычисляется по содержимому и меняется
+// с любым изменением команды, включая участников; для If-Match в /team/setSettings служит settings.version.
 func (h *TeamHandlers) GetTeam(w http.ResponseWriter, r *http.Request) {
 	teamName := r.URL.Query().Get("team_name")
 
@@ -77,23 +79,200 @@ func (h *TeamHandlers) GetTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := TeamDTO{
-		TeamName: team.Name,
-		Members:  mapUsersToTeamMembers(team.Members),
+	resp := mapTeamToDTO(team)
+
+	setContentETag(w, resp)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// SetSettings изменяет настройки назначения ревьюверов команды.
+func (h *TeamHandlers) SetSettings(w http.ResponseWriter, r *http.Request) {
+	var req SetTeamSettingsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	update := domain.TeamSettingsUpdate{
+		DefaultMaxOpenReviews: req.DefaultMaxOpenReviews.toDomain(),
+		PreferWorkingHours:    req.PreferWorkingHours,
+		PairingWindowDays:     req.PairingWindowDays,
+		MaxReviewers:          req.MaxReviewers,
+		ReviewSLAHours:        req.ReviewSLAHours,
+		LeadID:                req.LeadID.toDomain(),
+		ChatWebhookURL:        req.ChatWebhookURL.toDomain(),
+		ChatChannel:           req.ChatChannel.toDomain(),
+	}
+
+	if req.CapacityPolicy != nil {
+		policy := domain.CapacityPolicy(*req.CapacityPolicy)
+		update.CapacityPolicy = &policy
+	}
+
+	if req.SelectionStrategy != nil {
+		strategy := domain.SelectionStrategy(*req.SelectionStrategy)
+		update.Strategy = &strategy
+	}
+
+	if req.StaleAction != nil {
+		action := domain.StaleAction(*req.StaleAction)
+		update.StaleAction = &action
+	}
+
+	settings, err := h.svc.UpdateSettings(r.Context(), req.TeamName, update)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := SetTeamSettingsResponse{
+		TeamName: req.TeamName,
+		Settings: mapTeamSettingsToDTO(settings),
 	}
 
+	setETag(w, settings.Version)
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+// AddRule добавляет правило назначения ревьюверов в команду.
+func (h *TeamHandlers) AddRule(w http.ResponseWriter, r *http.Request) {
+	var req AddReviewRuleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	rule, err := h.svc.AddRule(r.Context(), domain.ReviewRule{
+		TeamName:      req.TeamName,
+		Kind:          domain.RuleKind(req.Kind),
+		AuthorID:      req.AuthorID,
+		AuthorLevel:   domain.Seniority(req.AuthorLevel),
+		Label:         req.Label,
+		ReviewerID:    req.ReviewerID,
+		ReviewerLevel: domain.Seniority(req.ReviewerLevel),
+		Description:   req.Description,
+	})
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(ReviewRuleResponse{Rule: mapRuleToDTO(rule)})
+}
+
+// ListRules возвращает правила назначения ревьюверов команды.
+func (h *TeamHandlers) ListRules(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+
+	if teamName == "" {
+		WriteError(w, &domain.DomainError{
+			Code: domain.ErrorCodeNotFound,
+			Err:  domain.ErrNotFound,
+		})
+
+		return
+	}
+
+	rules, err := h.svc.ListRules(r.Context(), teamName)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := ReviewRuleListResponse{
+		TeamName: teamName,
+		Rules:    make([]ReviewRuleDTO, 0, len(rules)),
+	}
+
+	for _, rule := range rules {
+		resp.Rules = append(resp.Rules, mapRuleToDTO(rule))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// DeleteRule удаляет правило назначения ревьюверов.
+func (h *TeamHandlers) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	var req DeleteReviewRuleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	rule, err := h.svc.DeleteRule(r.Context(), req.RuleID)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ReviewRuleResponse{Rule: mapRuleToDTO(rule)})
+}
+
+func mapRuleToDTO(rule domain.ReviewRule) ReviewRuleDTO {
+	return ReviewRuleDTO{
+		RuleID:        rule.ID,
+		TeamName:      rule.TeamName,
+		Kind:          string(rule.Kind),
+		AuthorID:      rule.AuthorID,
+		AuthorLevel:   string(rule.AuthorLevel),
+		Label:         rule.Label,
+		ReviewerID:    rule.ReviewerID,
+		ReviewerLevel: string(rule.ReviewerLevel),
+		Description:   rule.Description,
+	}
+}
+
+func mapTeamToDTO(team domain.Team) TeamDTO {
+	settings := mapTeamSettingsToDTO(team.Settings)
+
+	return TeamDTO{
+		TeamName: team.Name,
+		Settings: &settings,
+		Members:  mapUsersToTeamMembers(team.Members),
+	}
+}
+
+func mapTeamSettingsToDTO(s domain.TeamSettings) TeamSettingsDTO {
+	return TeamSettingsDTO{
+		DefaultMaxOpenReviews: s.DefaultMaxOpenReviews,
+		CapacityPolicy:        string(s.CapacityPolicy),
+		PreferWorkingHours:    s.PreferWorkingHours,
+		PairingWindowDays:     s.PairingWindowDays,
+		SelectionStrategy:     string(s.Strategy),
+		MaxReviewers:          s.MaxReviewers,
+		ReviewSLAHours:        s.ReviewSLAHours,
+		StaleAction:           string(s.StaleAction),
+		LeadID:                s.LeadID,
+		ChatWebhookSet:        s.ChatWebhookURL != "",
+		ChatChannel:           s.ChatChannel,
+		Version:               s.Version,
+	}
+}
+
 func mapUsersToTeamMembers(users []domain.User) []TeamMemberDTO {
 	res := make([]TeamMemberDTO, 0, len(users))
 
 	for _, u := range users {
 		res = append(res, TeamMemberDTO{
-			UserID:   u.ID,
-			Username: u.Username,
-			IsActive: u.IsActive,
+			UserID:          u.ID,
+			Username:        u.Username,
+			IsActive:        u.IsActive,
+			MaxOpenReviews:  u.MaxOpenReviews,
+			Seniority:       string(u.Seniority),
+			SelectionWeight: u.Weight,
 		})
 	}
 
@@ -2,7 +2,10 @@ package httpapi
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"pr-reviewer-service/internal/domain"
 	"pr-reviewer-service/internal/service"
@@ -35,18 +38,111 @@ func (h *UserHandlers) SetIsActive(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := SetIsActiveResponse{
-		User: UserDTO{
-			UserID:   user.ID,
-			Username: user.Username,
-			TeamName: user.TeamName,
-			IsActive: user.IsActive,
-		},
+		User: mapUserToDTO(user),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+// SetMaxOpenReviews обрабатывает запрос на изменение личного лимита открытых ревью.
+func (h *UserHandlers) SetMaxOpenReviews(w http.ResponseWriter, r *http.Request) {
+	var req SetMaxOpenReviewsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	user, err := h.svc.SetMaxOpenReviews(r.Context(), req.UserID, req.MaxOpenReviews)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := SetMaxOpenReviewsResponse{
+		User: mapUserToDTO(user),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// SetSeniority обрабатывает запрос на изменение уровня и веса пользователя.
+func (h *UserHandlers) SetSeniority(w http.ResponseWriter, r *http.Request) {
+	var req SetSeniorityRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	user, err := h.svc.SetSeniority(r.Context(), req.UserID, domain.Seniority(req.Seniority), req.SelectionWeight)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(SetSeniorityResponse{User: mapUserToDTO(user)})
+}
+
+// SetWorkSchedule обрабатывает запрос на изменение часового пояса и рабочих часов пользователя.
+func (h *UserHandlers) SetWorkSchedule(w http.ResponseWriter, r *http.Request) {
+	var req SetWorkScheduleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	var hours *domain.WorkHours
+
+	if req.WorkHours != nil {
+		parsed, err := parseWorkHours(*req.WorkHours)
+
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+
+		hours = &parsed
+	}
+
+	user, err := h.svc.SetWorkSchedule(r.Context(), req.UserID, req.Timezone, hours)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(SetWorkScheduleResponse{User: mapUserToDTO(user)})
+}
+
+// SetNotifications обрабатывает запрос на изменение настроек уведомлений пользователя.
+func (h *UserHandlers) SetNotifications(w http.ResponseWriter, r *http.Request) {
+	var req SetNotificationsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	user, err := h.svc.SetNotifications(r.Context(), req.UserID, req.Email.toDomain(), req.ChatHandle.toDomain(),
+		req.DigestEnabled)
+
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(SetNotificationsResponse{User: mapUserToDTO(user)})
+}
+
 // GetReviewPRs возвращает список pull request-ов, которые пользователь должен ревьюить.
 func (h *UserHandlers) GetReviewPRs(w http.ResponseWriter, r *http.Request) {
 	userID := r.URL.Query().Get("user_id")
@@ -60,7 +156,7 @@ func (h *UserHandlers) GetReviewPRs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	uid, prs, err := h.svc.GetReviewPRs(r.Context(), userID)
+	uid, prs, load, err := h.svc.GetReviewPRs(r.Context(), userID)
 
 	if err != nil {
 		WriteError(w, err)
@@ -68,14 +164,117 @@ func (h *UserHandlers) GetReviewPRs(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := UserReviewResponse{
-		UserID:       uid,
-		PullRequests: mapPRShortsToDTO(prs),
+		UserID:         uid,
+		PullRequests:   mapPRShortsToDTO(prs),
+		OpenReviews:    load.OpenReviews,
+		MaxOpenReviews: load.MaxOpenReviews,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+func mapUserToDTO(u domain.User) UserDTO {
+	dto := UserDTO{
+		UserID:          u.ID,
+		Username:        u.Username,
+		TeamName:        u.TeamName,
+		IsActive:        u.IsActive,
+		MaxOpenReviews:  u.MaxOpenReviews,
+		Timezone:        u.Timezone,
+		Seniority:       string(u.Seniority),
+		SelectionWeight: u.SelectionWeight(),
+		Email:           u.Email,
+		DigestEnabled:   u.DigestEnabled,
+		ChatHandle:      u.ChatHandle,
+	}
+
+	if u.WorkHours != nil {
+		dto.WorkHours = &WorkHoursDTO{
+			Start: formatMinuteOfDay(u.WorkHours.StartMinute),
+			End:   formatMinuteOfDay(u.WorkHours.EndMinute),
+			Days:  formatWeekdays(u.WorkHours.Days),
+		}
+	}
+
+	return dto
+}
+
+var weekdayNames = [...]string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+
+// parseWorkHours разбирает рабочие часы вида "09:00"–"18:00" и дни недели ("MON".."SUN").
+// Пустой список дней означает понедельник–пятницу.
+func parseWorkHours(dto WorkHoursDTO) (domain.WorkHours, error) {
+	start, err := parseMinuteOfDay(dto.Start)
+
+	if err != nil {
+		return domain.WorkHours{}, err
+	}
+
+	end, err := parseMinuteOfDay(dto.End)
+
+	if err != nil {
+		return domain.WorkHours{}, err
+	}
+
+	days := domain.DefaultWorkDays
+
+	if len(dto.Days) > 0 {
+		days = 0
+
+		for _, name := range dto.Days {
+			idx := -1
+
+			for i, wd := range weekdayNames {
+				if strings.EqualFold(name, wd) {
+					idx = i
+					break
+				}
+			}
+
+			if idx < 0 {
+				return domain.WorkHours{}, domain.NewDomainError(domain.ErrorCodeValidation,
+					fmt.Errorf("unknown weekday %q: %w", name, domain.ErrInvalidInput))
+			}
+
+			days |= 1 << idx
+		}
+	}
+
+	return domain.WorkHours{StartMinute: start, EndMinute: end, Days: days}, nil
+}
+
+func parseMinuteOfDay(s string) (int, error) {
+	if s == "24:00" {
+		return 24 * 60, nil
+	}
+
+	t, err := time.Parse("15:04", s)
+
+	if err != nil {
+		return 0, domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("invalid time of day %q, expected HH:MM: %w", s, domain.ErrInvalidInput))
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatMinuteOfDay(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+func formatWeekdays(set domain.WeekdaySet) []string {
+	res := make([]string, 0, len(weekdayNames))
+
+	for i, name := range weekdayNames {
+		if set.Has(time.Weekday(i)) {
+			res = append(res, name)
+		}
+	}
+
+	return res
+}
+
 func mapPRShortsToDTO(prs []domain.PullRequestShort) []PullRequestShortDTO {
 	res := make([]PullRequestShortDTO, 0, len(prs))
 
@@ -1,10 +1,21 @@
 package httpapi
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"pr-reviewer-service/internal/domain"
 	"pr-reviewer-service/internal/logging"
+	"pr-reviewer-service/internal/service"
 )
 
 type statusRecorder struct {
@@ -56,3 +67,296 @@ func RecoveryMiddleware(logger *logging.Logger) func(http.Handler) http.Handler
 type domainErrorInternal struct{}
 
 func (d *domainErrorInternal) Error() string { return "internal error" }
+
+// AuthMiddleware аутентифицирует запрос и кладёт вызывающего в контекст. Ключ API передаётся
+// в заголовке X-API-Key или Authorization: Bearer; bearer-токен в формате JWT проверяется через tokens
+// (nil — вход по JWT отключён). Запрос выполняется в организации вызывающего. Если enabled == false,
+// все запросы выполняются от имени оператора платформы в организации по умолчанию.
+func AuthMiddleware(
+	keys *service.APIKeyService,
+	tokens *service.TokenAuthService,
+	enabled bool,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !enabled {
+				caller := domain.Caller{
+					Name:     "anonymous",
+					OrgID:    domain.DefaultOrganizationID,
+					Role:     domain.RoleAdmin,
+					Platform: true,
+				}
+
+				next.ServeHTTP(w, r.WithContext(withCaller(r, caller)))
+				return
+			}
+
+			var (
+				caller domain.Caller
+				err    error
+			)
+
+			secret := r.Header.Get("X-API-Key")
+			token := bearerToken(r)
+
+			switch {
+			case secret != "":
+				caller, err = keys.Authenticate(r.Context(), secret)
+			case token != "" && tokens != nil && isJWT(token):
+				caller, err = tokens.Authenticate(r.Context(), token, time.Now())
+			case token != "":
+				caller, err = keys.Authenticate(r.Context(), token)
+			default:
+				err = domain.NewDomainError(domain.ErrorCodeUnauthorized,
+					fmt.Errorf("api key or bearer token is required: %w", domain.ErrUnauthorized))
+			}
+
+			if err != nil {
+				WriteError(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withCaller(r, caller)))
+		})
+	}
+}
+
+// withCaller возвращает контекст запроса с вызывающим и его организацией.
+func withCaller(r *http.Request, caller domain.Caller) context.Context {
+	return domain.WithOrganization(domain.WithCaller(r.Context(), caller), caller.OrgID)
+}
+
+// RequirePlatform пропускает только оператора платформы.
+func RequirePlatform(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if caller, ok := domain.CallerFrom(r.Context()); !ok || !caller.Platform {
+			WriteError(w, domain.NewDomainError(domain.ErrorCodeForbidden,
+				fmt.Errorf("only the platform operator may %s %s: %w", r.Method, r.URL.Path, domain.ErrForbidden)))
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireRole пропускает только вызывающих с одной из ролей roles.
+func RequireRole(roles ...domain.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			caller, ok := domain.CallerFrom(r.Context())
+
+			if !ok {
+				WriteError(w, domain.NewDomainError(domain.ErrorCodeUnauthorized, domain.ErrUnauthorized))
+				return
+			}
+
+			if !caller.HasRole(roles...) {
+				WriteError(w, domain.NewDomainError(domain.ErrorCodeForbidden,
+					fmt.Errorf("role %s is not allowed to %s %s: %w", caller.Role, r.Method, r.URL.Path, domain.ErrForbidden)))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// responseRecorder запоминает статус и тело ответа, передавая их дальше клиенту.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// IdempotencyMiddleware обрабатывает заголовок Idempotency-Key у POST-запросов: первый запрос
+// с ключом выполняется, и его ответ сохраняется; повтор тем же вызывающим с тем же методом, путём
+// и телом получает сохранённый ответ (с ETag) и заголовок Idempotent-Replayed, а с другим —
+// 422 IDEMPOTENCY_KEY_REUSED. Ставится после проверки роли; ответы 5xx, 401 и 403 не сохраняются,
+// чтобы повтор выполнил запрос заново.
+func IdempotencyMiddleware(svc *service.IdempotencyService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+
+			if err != nil {
+				WriteError(w, domain.NewDomainError(domain.ErrorCodeValidation,
+					fmt.Errorf("read request body: %w", domain.ErrInvalidInput)))
+
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			rec, replay, err := svc.Begin(r.Context(), key, requestHash(r, body), time.Now().UTC())
+
+			if err != nil {
+				WriteError(w, err)
+				return
+			}
+
+			if replay {
+				if rec.ContentType != "" {
+					w.Header().Set("Content-Type", rec.ContentType)
+				}
+
+				if rec.ETag != "" {
+					w.Header().Set("ETag", rec.ETag)
+				}
+
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(rec.StatusCode)
+				_, _ = w.Write(rec.Body)
+				return
+			}
+
+			// ответ сохраняется и после отмены запроса клиентом, чтобы повтор его получил
+			ctx := context.WithoutCancel(r.Context())
+			resp := &responseRecorder{ResponseWriter: w}
+			completed := false
+
+			defer func() {
+				if !completed {
+					_ = svc.Release(ctx, rec)
+				}
+			}()
+
+			next.ServeHTTP(resp, r)
+
+			if !storable(resp.status) {
+				return
+			}
+
+			rec.StatusCode = resp.status
+			rec.ContentType = resp.Header().Get("Content-Type")
+			rec.ETag = resp.Header().Get("ETag")
+			rec.Body = resp.body.Bytes()
+
+			if err := svc.Complete(ctx, rec); err == nil {
+				completed = true
+			}
+		})
+	}
+}
+
+// storable сообщает, сохраняется ли ответ со статусом status для повтора по ключу идемпотентности:
+// сбои сервера и отказы в доступе не окончательны, и повтор должен выполниться заново.
+func storable(status int) bool {
+	switch {
+	case status == 0, status >= http.StatusInternalServerError:
+		return false
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return false
+	default:
+		return true
+	}
+}
+
+// IfMatchMiddleware передаёт в контекст версию из заголовка If-Match (значение ETag из ответа
+// на чтение): изменение PR или настроек команды выполнится, только если версия не изменилась,
+// иначе вернётся 412 PRECONDITION_FAILED. Заголовок "*" и его отсутствие версию не проверяют.
+func IfMatchMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := strings.TrimSpace(r.Header.Get("If-Match"))
+
+		if header == "" || header == "*" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		version, err := parseETag(header)
+
+		if err != nil {
+			WriteError(w, domain.NewDomainError(domain.ErrorCodePreconditionFailed, err))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(domain.WithExpectedVersion(r.Context(), version)))
+	})
+}
+
+// setETag отдаёт версию ресурса в заголовке ETag.
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// setContentETag отдаёт в ETag хеш представления ресурса без собственной версии: такой ETag
+// не принимается в If-Match, но меняется при любом изменении ответа.
+func setContentETag(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	w.Header().Set("ETag", `"c-`+hex.EncodeToString(sum[:16])+`"`)
+}
+
+// parseETag разбирает строгий ETag вида "<версия>", выданный setETag.
+func parseETag(tag string) (int64, error) {
+	unquoted, err := strconv.Unquote(tag)
+
+	if err != nil || !strings.HasPrefix(tag, `"`) {
+		return 0, fmt.Errorf("If-Match must be an ETag returned by the service: %w", domain.ErrVersionMismatch)
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("If-Match must be an ETag returned by the service: %w", domain.ErrVersionMismatch)
+	}
+
+	return version, nil
+}
+
+// requestHash возвращает хеш метода, пути с параметрами и тела запроса.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bearerToken возвращает токен из заголовка Authorization: Bearer (пусто, если его нет).
+func bearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+
+	h := r.Header.Get("Authorization")
+
+	if len(h) < len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(h[len(prefix):])
+}
+
+// isJWT сообщает, похож ли токен на JWT (три сегмента через точку); в ключах API точек нет.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
@@ -6,16 +6,29 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"pr-reviewer-service/internal/domain"
 	"pr-reviewer-service/internal/logging"
 	"pr-reviewer-service/internal/service"
 )
 
 // NewRouter настраивает HTTP-маршруты и middleware сервиса.
+// Если authEnabled == false, ключ API не проверяется и все запросы выполняются с ролью ADMIN;
+// tokenSvc == nil отключает вход по JWT.
 func NewRouter(
 	teamSvc *service.TeamService,
 	userSvc *service.UserService,
 	prSvc *service.PullRequestService,
+	repoSvc *service.RepoService,
 	statsSvc *service.StatsService,
+	absenceSvc *service.AbsenceService,
+	eventSvc *service.EventService,
+	chatOpsSvc *service.ChatOpsService,
+	keySvc *service.APIKeyService,
+	orgSvc *service.OrganizationService,
+	idemSvc *service.IdempotencyService,
+	tokenSvc *service.TokenAuthService,
+	authEnabled bool,
+	slackSigningSecret string,
 	logger *logging.Logger,
 ) nethttp.Handler {
 	r := chi.NewRouter()
@@ -26,28 +39,107 @@ func NewRouter(
 	teamHandlers := NewTeamHandlers(teamSvc)
 	userHandlers := NewUserHandlers(userSvc)
 	prHandlers := NewPullRequestHandlers(prSvc)
+	repoHandlers := NewRepositoryHandlers(repoSvc)
 	statsHandlers := NewStatsHandlers(statsSvc)
+	absenceHandlers := NewAbsenceHandlers(absenceSvc)
+	eventHandlers := NewEventHandlers(eventSvc)
+	keyHandlers := NewAPIKeyHandlers(keySvc)
+	orgHandlers := NewOrganizationHandlers(orgSvc)
 
 	r.Get("/health", HealthHandler)
 
-	r.Route("/team", func(r chi.Router) {
-		r.Post("/add", teamHandlers.CreateTeam)
-		r.Get("/get", teamHandlers.GetTeam)
-	})
+	// Slash-команды чата аутентифицируются подписью и принимаются, только если задан секрет
+	if slackSigningSecret != "" {
+		r.Post("/chat/slash", NewChatOpsHandlers(chatOpsSvc, orgSvc, slackSigningSecret).Slash)
+	}
 
-	r.Route("/users", func(r chi.Router) {
-		r.Post("/setIsActive", userHandlers.SetIsActive)
-		r.Get("/getReview", userHandlers.GetReviewPRs)
-	})
+	// Остальные маршруты требуют ключ API или JWT; роль проверяется для каждого маршрута,
+	// и только после неё — ключ идемпотентности, чтобы отказ в доступе не занимал и не сохранял ключ
+	idempotent := IdempotencyMiddleware(idemSvc)
+	allow := func(roles ...domain.Role) func(nethttp.Handler) nethttp.Handler {
+		return func(next nethttp.Handler) nethttp.Handler {
+			return RequireRole(roles...)(idempotent(next))
+		}
+	}
 
-	r.Route("/pullRequest", func(r chi.Router) {
-		r.Post("/create", prHandlers.CreatePR)
-		r.Post("/merge", prHandlers.MergePR)
-		r.Post("/reassign", prHandlers.ReassignReviewer)
-	})
+	read := allow(domain.RoleAdmin, domain.RoleTeamLead, domain.RoleBot, domain.RoleReader)
+	write := allow(domain.RoleAdmin, domain.RoleTeamLead, domain.RoleBot)
+	manage := allow(domain.RoleAdmin, domain.RoleTeamLead)
+	admin := allow(domain.RoleAdmin)
+
+	r.Group(func(r chi.Router) {
+		r.Use(AuthMiddleware(keySvc, tokenSvc, authEnabled))
+		r.Use(IfMatchMiddleware)
+
+		r.Route("/team", func(r chi.Router) {
+			r.With(manage).Post("/add", teamHandlers.CreateTeam)
+			r.With(read).Get("/get", teamHandlers.GetTeam)
+			r.With(manage).Post("/setSettings", teamHandlers.SetSettings)
+
+			r.Route("/rules", func(r chi.Router) {
+				r.With(manage).Post("/add", teamHandlers.AddRule)
+				r.With(read).Get("/list", teamHandlers.ListRules)
+				r.With(manage).Post("/delete", teamHandlers.DeleteRule)
+			})
+		})
+
+		r.Route("/users", func(r chi.Router) {
+			r.With(manage).Post("/setIsActive", userHandlers.SetIsActive)
+			r.With(manage).Post("/setMaxOpenReviews", userHandlers.SetMaxOpenReviews)
+			r.With(write).Post("/setWorkSchedule", userHandlers.SetWorkSchedule)
+			r.With(manage).Post("/setSeniority", userHandlers.SetSeniority)
+			r.With(write).Post("/setNotifications", userHandlers.SetNotifications)
+			r.With(read).Get("/getReview", userHandlers.GetReviewPRs)
 
-	// Доп. статистика
-	r.Get("/stats/assignments", statsHandlers.GetAssignmentsByUser)
+			r.Route("/absences", func(r chi.Router) {
+				r.With(write).Post("/add", absenceHandlers.AddAbsence)
+				r.With(write).Post("/update", absenceHandlers.UpdateAbsence)
+				r.With(write).Post("/delete", absenceHandlers.DeleteAbsence)
+				r.With(read).Get("/list", absenceHandlers.ListAbsences)
+				r.With(write).Post("/import", absenceHandlers.ImportICS)
+			})
+		})
+
+		r.Route("/repositories", func(r chi.Router) {
+			r.With(manage).Post("/add", repoHandlers.AddRepository)
+			r.With(read).Get("/get", repoHandlers.GetRepository)
+			r.With(read).Get("/list", repoHandlers.ListRepositories)
+			r.With(manage).Post("/setOverrides", repoHandlers.SetOverrides)
+		})
+
+		r.Route("/pullRequest", func(r chi.Router) {
+			r.With(write).Post("/create", prHandlers.CreatePR)
+			r.With(read).Get("/get", prHandlers.GetPR)
+			r.With(read).Post("/previewReviewers", prHandlers.PreviewReviewers)
+			r.With(write).Post("/merge", prHandlers.MergePR)
+			r.With(write).Post("/reassign", prHandlers.ReassignReviewer)
+			r.With(write).Post("/decline", prHandlers.DeclineReview)
+			r.With(write).Post("/markReviewed", prHandlers.MarkReviewed)
+			r.With(write).Post("/addReviewer", prHandlers.AddReviewer)
+			r.With(write).Post("/removeReviewer", prHandlers.RemoveReviewer)
+			r.With(write).Post("/volunteer", prHandlers.Volunteer)
+			r.With(read).Get("/explainAssignment", prHandlers.ExplainAssignment)
+		})
+
+		// Доп. статистика
+		r.With(read).Get("/stats/assignments", statsHandlers.GetAssignmentsByUser)
+		r.With(read).Get("/events/list", eventHandlers.ListEvents)
+
+		r.Route("/auth/keys", func(r chi.Router) {
+			r.Use(admin)
+			r.Post("/create", keyHandlers.CreateKey)
+			r.Get("/list", keyHandlers.ListKeys)
+			r.Post("/revoke", keyHandlers.RevokeKey)
+		})
+
+		// Организации заводит только оператор платформы
+		r.Route("/orgs", func(r chi.Router) {
+			r.Use(RequirePlatform, idempotent)
+			r.Post("/create", orgHandlers.CreateOrganization)
+			r.Get("/list", orgHandlers.ListOrganizations)
+			r.Post("/setChatTeam", orgHandlers.SetChatTeam)
+		})
+	})
 
 	// Оборачиваем в TimeoutHandler, чтобы приблизиться к SLI 300ms
 	timeout := 250 * time.Millisecond
@@ -0,0 +1,371 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCalendar возвращается, если вход не похож на iCalendar (RFC 5545).
+var ErrInvalidCalendar = errors.New("invalid iCalendar data")
+
+// Event — событие VEVENT в интервале [Start, End).
+type Event struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+	AllDay  bool
+}
+
+// vevent — разбираемое событие со свойствами, которые не попадают в Event.
+type vevent struct {
+	Event
+	cancelled bool
+	// duration — продолжительность из DURATION (вместо DTEND).
+	duration *duration
+}
+
+// duration — продолжительность RFC 5545 (3.3.6): дни и недели номинальные (сутки по календарю
+// с учётом перехода на летнее время), часы, минуты и секунды — точные.
+type duration struct {
+	days  int
+	clock time.Duration
+}
+
+// property — одна строка контента вида NAME;PARAM=VALUE:VALUE.
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse разбирает календарь и возвращает события VEVENT.
+// Даты (VALUE=DATE) и время без зоны трактуются в поясе loc — часовом поясе владельца календаря.
+// Отменённые события (STATUS:CANCELLED) пропускаются. Повторяющиеся события (RRULE, RDATE) не
+// поддерживаются и отклоняются целиком, чтобы не импортировать только первое вхождение серии.
+func Parse(r io.Reader, loc *time.Location) ([]Event, error) {
+	lines, err := unfold(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events []Event
+		cur    *vevent
+		sawCal bool
+	)
+
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		p, err := parseLine(line)
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch {
+		case p.name == "BEGIN" && p.value == "VCALENDAR":
+			sawCal = true
+
+		case p.name == "BEGIN" && p.value == "VEVENT":
+			cur = &vevent{}
+
+		case p.name == "END" && p.value == "VEVENT":
+			if cur == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN: %w", i+1, ErrInvalidCalendar)
+			}
+
+			if !cur.cancelled {
+				if err := finalize(cur); err != nil {
+					return nil, fmt.Errorf("event %q: %w", cur.UID, err)
+				}
+
+				events = append(events, cur.Event)
+			}
+
+			cur = nil
+
+		case cur != nil:
+			if err := apply(cur, p, loc); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+	}
+
+	if !sawCal {
+		return nil, fmt.Errorf("missing BEGIN:VCALENDAR: %w", ErrInvalidCalendar)
+	}
+
+	return events, nil
+}
+
+// unfold склеивает перенесённые строки (продолжение начинается с пробела или табуляции).
+func unfold(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	// слишком длинная строка (bufio.ErrTooLong) или оборванное тело — тоже некорректный календарь
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read calendar: %w: %w", ErrInvalidCalendar, err)
+	}
+
+	return lines, nil
+}
+
+func parseLine(line string) (property, error) {
+	colon := strings.IndexByte(line, ':')
+
+	if colon < 0 {
+		return property{}, fmt.Errorf("no value separator: %w", ErrInvalidCalendar)
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := strings.Split(head, ";")
+
+	p := property{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string, len(parts)-1),
+		value:  value,
+	}
+
+	for _, param := range parts[1:] {
+		k, v, ok := strings.Cut(param, "=")
+
+		if !ok {
+			continue
+		}
+
+		p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+
+	return p, nil
+}
+
+func apply(e *vevent, p property, loc *time.Location) error {
+	switch p.name {
+	case "RRULE", "RDATE":
+		return fmt.Errorf("recurring events (%s) are not supported: %w", p.name, ErrInvalidCalendar)
+
+	case "UID":
+		e.UID = p.value
+
+	case "SUMMARY":
+		e.Summary = unescape(p.value)
+
+	case "STATUS":
+		e.cancelled = strings.EqualFold(p.value, "CANCELLED")
+
+	case "DTSTART":
+		t, allDay, err := parseTime(p, loc)
+
+		if err != nil {
+			return err
+		}
+
+		e.Start, e.AllDay = t, allDay
+
+	case "DTEND":
+		t, _, err := parseTime(p, loc)
+
+		if err != nil {
+			return err
+		}
+
+		e.End = t
+
+	case "DURATION":
+		d, err := parseDuration(p.value)
+
+		if err != nil {
+			return err
+		}
+
+		e.duration = &d
+	}
+
+	return nil
+}
+
+// finalize вычисляет конец события и переводит время в UTC.
+func finalize(e *vevent) error {
+	if e.Start.IsZero() {
+		return fmt.Errorf("missing DTSTART: %w", ErrInvalidCalendar)
+	}
+
+	if e.duration != nil {
+		if !e.End.IsZero() {
+			return fmt.Errorf("DTEND and DURATION are mutually exclusive: %w", ErrInvalidCalendar)
+		}
+
+		// дни прибавляются по календарю пояса DTSTART, поэтому сутки отпуска через переход
+		// на летнее время заканчиваются в ту же полночь
+		e.End = e.Start.AddDate(0, 0, e.duration.days).Add(e.duration.clock)
+	}
+
+	// Без DTEND и DURATION событие на дату длится один день (RFC 5545, 3.6.1)
+	if e.End.IsZero() && e.AllDay {
+		e.End = e.Start.AddDate(0, 0, 1)
+	}
+
+	if !e.End.After(e.Start) {
+		return fmt.Errorf("DTEND must be after DTSTART: %w", ErrInvalidCalendar)
+	}
+
+	e.Start, e.End = e.Start.UTC(), e.End.UTC()
+
+	return nil
+}
+
+// parseDuration разбирает значение DURATION: [+/-]P[nW] или [+/-]P[nD][T[nH][nM][nS]].
+func parseDuration(s string) (duration, error) {
+	invalid := fmt.Errorf("invalid DURATION %q: %w", s, ErrInvalidCalendar)
+	sign := 1
+
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	rest, ok := strings.CutPrefix(s, "P")
+
+	if !ok || rest == "" {
+		return duration{}, invalid
+	}
+
+	var (
+		d       duration
+		inTime  bool
+		units   = "WD"
+		sawUnit bool
+	)
+
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return duration{}, invalid
+			}
+
+			inTime, units, rest = true, "HMS", rest[1:]
+			sawUnit = false
+
+			continue
+		}
+
+		n := 0
+
+		for n < len(rest) && rest[n] >= '0' && rest[n] <= '9' {
+			n++
+		}
+
+		if n == 0 || n == len(rest) {
+			return duration{}, invalid
+		}
+
+		value, err := strconv.Atoi(rest[:n])
+
+		if err != nil {
+			return duration{}, invalid
+		}
+
+		// единицы идут по убыванию и не повторяются; недели не сочетаются с другими единицами
+		unit := strings.IndexByte(units, rest[n])
+
+		if unit < 0 || (units == "WD" && rest[n] == 'W' && n+1 != len(rest)) {
+			return duration{}, invalid
+		}
+
+		switch rest[n] {
+		case 'W':
+			d.days += value * 7
+		case 'D':
+			d.days += value
+		case 'H':
+			d.clock += time.Duration(value) * time.Hour
+		case 'M':
+			d.clock += time.Duration(value) * time.Minute
+		case 'S':
+			d.clock += time.Duration(value) * time.Second
+		}
+
+		units, rest, sawUnit = units[unit+1:], rest[n+1:], true
+	}
+
+	if inTime && !sawUnit {
+		return duration{}, invalid
+	}
+
+	d.days *= sign
+	d.clock *= time.Duration(sign)
+
+	return d, nil
+}
+
+// parseTime разбирает DATE или DATE-TIME с учётом TZID. Без TZID дата и время трактуются в поясе loc.
+// Время возвращается в своём поясе; в UTC его переводит finalize.
+func parseTime(p property, loc *time.Location) (time.Time, bool, error) {
+	if tzid := p.params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(tzid)
+
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown TZID %q: %w", tzid, ErrInvalidCalendar)
+		}
+
+		loc = l
+	}
+
+	if p.params["VALUE"] == "DATE" || len(p.value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", p.value, loc)
+
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("parse %s: %w", p.name, ErrInvalidCalendar)
+		}
+
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(p.value, "Z") {
+		t, err := time.Parse("20060102T150405Z", p.value)
+
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("parse %s: %w", p.name, ErrInvalidCalendar)
+		}
+
+		return t, false, nil
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", p.value, loc)
+
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("parse %s: %w", p.name, ErrInvalidCalendar)
+	}
+
+	return t, false, nil
+}
+
+func unescape(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
@@ -0,0 +1,77 @@
+package ical
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func calendar(event ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:e1\r\n" +
+		strings.Join(event, "\r\n") + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+}
+
+func TestParse_Duration(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+
+	cases := []struct {
+		name       string
+		lines      []string
+		start, end time.Time
+	}{
+		{
+			name:  "timed",
+			lines: []string{"DTSTART:20300301T090000Z", "DURATION:PT1H30M"},
+			start: time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC),
+			end:   time.Date(2030, 3, 1, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "days and time",
+			lines: []string{"DTSTART:20300301T090000Z", "DURATION:+P1DT2H"},
+			start: time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC),
+			end:   time.Date(2030, 3, 2, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			// дни номинальные: неделя через переход на летнее время заканчивается в ту же полночь
+			name:  "weeks in user timezone",
+			lines: []string{"DTSTART;VALUE=DATE:20300325", "DURATION:P1W"},
+			start: time.Date(2030, 3, 25, 0, 0, 0, 0, berlin).UTC(),
+			end:   time.Date(2030, 4, 1, 0, 0, 0, 0, berlin).UTC(),
+		},
+	}
+
+	for _, tc := range cases {
+		events, err := Parse(strings.NewReader(calendar(tc.lines...)), berlin)
+
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		if len(events) != 1 || !events[0].Start.Equal(tc.start) || !events[0].End.Equal(tc.end) {
+			t.Fatalf("%s: expected [%v, %v), got %+v", tc.name, tc.start, tc.end, events)
+		}
+	}
+}
+
+func TestParse_InvalidCalendar(t *testing.T) {
+	cases := map[string]string{
+		"negative duration":   calendar("DTSTART:20300301T090000Z", "DURATION:-PT1H"),
+		"malformed duration":  calendar("DTSTART:20300301T090000Z", "DURATION:P1H"),
+		"weeks with days":     calendar("DTSTART:20300301T090000Z", "DURATION:P1W2D"),
+		"empty time part":     calendar("DTSTART:20300301T090000Z", "DURATION:P1DT"),
+		"duration with dtend": calendar("DTSTART:20300301T090000Z", "DTEND:20300301T100000Z", "DURATION:PT1H"),
+		"recurring":           calendar("DTSTART:20300301T090000Z", "DTEND:20300301T100000Z", "RRULE:FREQ=DAILY"),
+		"line too long":       calendar("SUMMARY:" + strings.Repeat("x", 2<<20)),
+	}
+
+	for name, input := range cases {
+		if _, err := Parse(strings.NewReader(input), time.UTC); !errors.Is(err, ErrInvalidCalendar) {
+			t.Fatalf("%s: expected ErrInvalidCalendar, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"time"
+
+	"pr-reviewer-service/internal/config"
+	"pr-reviewer-service/internal/domain"
+)
+
+// SMTPMailer реализует domain.Mailer поверх SMTP-сервера.
+type SMTPMailer struct {
+	cfg config.SMTPConfig
+}
+
+// NewSMTPMailer создаёт новый SMTPMailer.
+func NewSMTPMailer(cfg config.SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{cfg: cfg}
+}
+
+// Send отправляет письмо с текстовой и HTML-версией (multipart/alternative).
+// Если задан логин, используется PLAIN-аутентификация (net/smtp разрешает её только по TLS или на localhost).
+func (m *SMTPMailer) Send(ctx context.Context, msg domain.EmailMessage) error {
+	body, err := buildMessage(m.cfg.From, msg)
+
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.cfg.Host, m.cfg.Port)
+
+	var auth smtp.Auth
+
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	// net/smtp не принимает контекст, поэтому отправка выполняется в отдельной горутине
+	done := make(chan error, 1)
+
+	go func() {
+		done <- smtp.SendMail(addr, auth, m.cfg.From, []string{msg.To}, body)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("send mail to %s: %w", msg.To, err)
+		}
+
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// buildMessage собирает MIME-письмо с текстовой и HTML-частями в quoted-printable.
+func buildMessage(from string, msg domain.EmailMessage) ([]byte, error) {
+	var buf bytes.Buffer
+
+	// заголовки пишутся до первой части; multipart.Writer ничего не пишет до CreatePart
+	mw := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\n"+
+		"Content-Type: multipart/alternative; boundary=%q\r\n\r\n",
+		from, msg.To, mime.QEncoding.Encode("utf-8", msg.Subject),
+		time.Now().UTC().Format(time.RFC1123Z), mw.Boundary())
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("create mail part: %w", err)
+		}
+
+		qp := quotedprintable.NewWriter(w)
+
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, fmt.Errorf("write mail part: %w", err)
+		}
+
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("close mail part: %w", err)
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("close mail: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
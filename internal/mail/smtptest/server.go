@@ -0,0 +1,247 @@
+// Package smtptest — минимальный SMTP-сервер в памяти процесса для тестов отправки писем.
+package smtptest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	netmail "net/mail"
+	"strings"
+	"sync"
+)
+
+// Message — письмо, принятое сервером.
+type Message struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// Server принимает письма по SMTP на 127.0.0.1 и хранит их в памяти.
+// Поддерживается подмножество команд, которого достаточно для net/smtp.SendMail (без STARTTLS и AUTH).
+type Server struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	messages []Message
+	wg       sync.WaitGroup
+}
+
+// NewServer запускает сервер на случайном свободном порту.
+func NewServer() (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{ln: ln}
+	s.wg.Add(1)
+
+	go s.serve()
+
+	return s, nil
+}
+
+// Host возвращает хост сервера для настроек SMTP.
+func (s *Server) Host() string {
+	return s.ln.Addr().(*net.TCPAddr).IP.String()
+}
+
+// Port возвращает порт сервера.
+func (s *Server) Port() string {
+	return fmt.Sprint(s.ln.Addr().(*net.TCPAddr).Port)
+}
+
+// Messages возвращает копию принятых писем.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]Message, len(s.messages))
+	copy(res, s.messages)
+	return res
+}
+
+// Close останавливает сервер.
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.ln.Accept()
+
+		if err != nil {
+			return
+		}
+
+		s.wg.Add(1)
+
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = io.WriteString(conn, line+"\r\n")
+	}
+
+	reply("220 smtptest ready")
+
+	var msg Message
+
+	for {
+		line, err := r.ReadString('\n')
+
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 smtptest")
+
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg = Message{From: addrArg(line)}
+			reply("250 OK")
+
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			msg.To = append(msg.To, addrArg(line))
+			reply("250 OK")
+
+		case cmd == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+
+			data, err := readData(r)
+
+			if err != nil {
+				return
+			}
+
+			msg.Data = data
+
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+
+			reply("250 OK")
+
+		case cmd == "RSET", cmd == "NOOP":
+			reply("250 OK")
+
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// addrArg извлекает адрес из аргумента MAIL FROM:/RCPT TO:.
+func addrArg(line string) string {
+	arg := strings.TrimSpace(line[strings.Index(line, ":")+1:])
+	return strings.Trim(arg, "<>")
+}
+
+// readData читает тело письма до строки с одной точкой, снимая точку-экранирование.
+func readData(r *bufio.Reader) ([]byte, error) {
+	var sb strings.Builder
+
+	for {
+		line, err := r.ReadString('\n')
+
+		if err != nil {
+			return nil, err
+		}
+
+		if line == ".\r\n" || line == ".\n" {
+			return []byte(sb.String()), nil
+		}
+
+		sb.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
+
+// Parsed — разобранное письмо: тема и тела по типу содержимого (text/plain, text/html).
+type Parsed struct {
+	Subject string
+	Bodies  map[string]string
+}
+
+// Parse разбирает письмо, декодируя тему и quoted-printable части multipart-письма.
+func (m Message) Parse() (Parsed, error) {
+	msg, err := netmail.ReadMessage(strings.NewReader(string(m.Data)))
+
+	if err != nil {
+		return Parsed{}, err
+	}
+
+	var dec mime.WordDecoder
+
+	subject, err := dec.DecodeHeader(msg.Header.Get("Subject"))
+
+	if err != nil {
+		return Parsed{}, err
+	}
+
+	res := Parsed{Subject: subject, Bodies: make(map[string]string)}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+
+	if err != nil {
+		return Parsed{}, err
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		body, err := io.ReadAll(msg.Body)
+
+		if err != nil {
+			return Parsed{}, err
+		}
+
+		res.Bodies[mediaType] = string(body)
+		return res, nil
+	}
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	for {
+		part, err := mr.NextPart()
+
+		if err == io.EOF {
+			return res, nil
+		}
+
+		if err != nil {
+			return Parsed{}, err
+		}
+
+		body, err := io.ReadAll(part)
+
+		if err != nil {
+			return Parsed{}, err
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		res.Bodies[partType] = string(body)
+	}
+}
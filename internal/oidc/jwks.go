@@ -0,0 +1,358 @@
+// Package oidc проверяет JWT, выпущенные OIDC-провайдером, по его набору ключей (JWKS, RFC 7517).
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrInvalidJWKS возвращается, если набор ключей не удаётся разобрать.
+var ErrInvalidJWKS = errors.New("invalid JWKS")
+
+// KeySet — открытые ключи подписи из JWKS по kid. Ключи без kid подходят к любому токену.
+type KeySet struct {
+	byKID map[string][]crypto.PublicKey
+	anon  []crypto.PublicKey
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS разбирает JWKS. Ключи шифрования (use=enc) и неподдерживаемых типов пропускаются;
+// набор без единого ключа подписи считается ошибкой.
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWKS, err)
+	}
+
+	ks := &KeySet{byKID: make(map[string][]crypto.PublicKey)}
+	count := 0
+
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var (
+			pub crypto.PublicKey
+			err error
+		)
+
+		switch k.Kty {
+		case "RSA":
+			pub, err = parseRSAKey(k)
+		case "EC":
+			pub, err = parseECKey(k)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %v", ErrInvalidJWKS, k.Kid, err)
+		}
+
+		if k.Kid == "" {
+			ks.anon = append(ks.anon, pub)
+		} else {
+			ks.byKID[k.Kid] = append(ks.byKID[k.Kid], pub)
+		}
+
+		count++
+	}
+
+	if count == 0 {
+		return nil, fmt.Errorf("%w: no signing keys", ErrInvalidJWKS)
+	}
+
+	return ks, nil
+}
+
+// Lookup возвращает ключи-кандидаты для токена с заголовком kid (пустой kid — все ключи).
+func (ks *KeySet) Lookup(kid string) []crypto.PublicKey {
+	if kid == "" {
+		keys := append([]crypto.PublicKey(nil), ks.anon...)
+
+		for _, k := range ks.byKID {
+			keys = append(keys, k...)
+		}
+
+		return keys
+	}
+
+	return append(append([]crypto.PublicKey(nil), ks.byKID[kid]...), ks.anon...)
+}
+
+func (ks *KeySet) has(kid string) bool {
+	_, ok := ks.byKID[kid]
+	return ok
+}
+
+func parseRSAKey(k jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+
+	e, err := decodeBigInt(k.E)
+
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+
+	if n.BitLen() < 2048 {
+		return nil, fmt.Errorf("RSA key is shorter than 2048 bits")
+	}
+
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("unsupported RSA exponent")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func parseECKey(k jsonWebKey) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, err := decodeBigInt(k.X)
+
+	if err != nil {
+		return nil, fmt.Errorf("x: %w", err)
+	}
+
+	y, err := decodeBigInt(k.Y)
+
+	if err != nil {
+		return nil, fmt.Errorf("y: %w", err)
+	}
+
+	if !curve.IsOnCurve(x, y) {
+		return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, fmt.Errorf("value is missing")
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// KeySource отдаёт ключи-кандидаты для проверки подписи токена с заголовком kid.
+type KeySource interface {
+	Keys(ctx context.Context, kid string) ([]crypto.PublicKey, error)
+}
+
+// StaticKeySource — неизменяемый набор ключей, например загруженный из файла.
+type StaticKeySource struct {
+	set *KeySet
+}
+
+// NewStaticKeySource создаёт источник из готового набора ключей.
+func NewStaticKeySource(set *KeySet) *StaticKeySource {
+	return &StaticKeySource{set: set}
+}
+
+// LoadJWKSFile читает JWKS из файла path.
+func LoadJWKSFile(path string) (*StaticKeySource, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS file: %w", err)
+	}
+
+	set, err := ParseJWKS(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStaticKeySource(set), nil
+}
+
+// Keys реализует KeySource.
+func (s *StaticKeySource) Keys(_ context.Context, kid string) ([]crypto.PublicKey, error) {
+	return s.set.Lookup(kid), nil
+}
+
+// minRefetchInterval — минимальный интервал между попытками загрузить JWKS.
+const minRefetchInterval = 30 * time.Second
+
+// fetchTimeout ограничивает одну загрузку JWKS: она не привязана к контексту запроса,
+// чтобы отменённый запрос не прерывал загрузку, которую ждут другие.
+const fetchTimeout = 10 * time.Second
+
+// maxJWKSSize — предельный размер ответа с JWKS.
+const maxJWKSSize = 1 << 20
+
+// RemoteKeySource загружает JWKS по URL и кэширует его на время refresh. Токен с неизвестным kid
+// вызывает внеплановую загрузку, чтобы подхватить ротацию ключей; повторные попытки — не чаще
+// раза в minRefetchInterval, в том числе пока ни один набор ещё не загружен. Одновременные запросы
+// ждут одну общую загрузку, которая выполняется без блокировки кэша.
+type RemoteKeySource struct {
+	url     string
+	client  *http.Client
+	refresh time.Duration
+	group   singleflight.Group
+
+	mu          sync.Mutex
+	set         *KeySet
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	lastErr     error
+}
+
+// NewRemoteKeySource создаёт источник ключей по адресу url.
+func NewRemoteKeySource(url string, client *http.Client, refresh time.Duration) *RemoteKeySource {
+	return &RemoteKeySource{url: url, client: client, refresh: refresh}
+}
+
+// Keys реализует KeySource. При недоступности провайдера используется последний загруженный набор.
+func (s *RemoteKeySource) Keys(ctx context.Context, kid string) ([]crypto.PublicKey, error) {
+	s.mu.Lock()
+	set, lastErr := s.set, s.lastErr
+	stale := set == nil || time.Since(s.fetchedAt) >= s.refresh || (kid != "" && !set.has(kid))
+	s.mu.Unlock()
+
+	if stale {
+		ch := s.group.DoChan("jwks", s.refetch)
+
+		select {
+		case res := <-ch:
+			if res.Err != nil {
+				lastErr = res.Err
+			} else {
+				set = res.Val.(*KeySet)
+			}
+		case <-ctx.Done():
+			lastErr = ctx.Err()
+		}
+	}
+
+	if set == nil {
+		if lastErr == nil {
+			lastErr = errors.New("JWKS is not loaded yet")
+		}
+
+		return nil, lastErr
+	}
+
+	return set.Lookup(kid), nil
+}
+
+// refetch загружает JWKS и обновляет кэш. Чаще раза в minRefetchInterval провайдер не запрашивается:
+// вместо этого возвращается кэш или последняя ошибка.
+func (s *RemoteKeySource) refetch() (any, error) {
+	s.mu.Lock()
+
+	if time.Since(s.attemptedAt) < minRefetchInterval {
+		set, err := s.set, s.lastErr
+		s.mu.Unlock()
+
+		if set == nil {
+			return nil, err
+		}
+
+		return set, nil
+	}
+
+	s.attemptedAt = time.Now()
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	set, err := s.fetch(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.lastErr = err
+
+		if s.set == nil {
+			return nil, err
+		}
+
+		return s.set, nil
+	}
+
+	s.set, s.fetchedAt, s.lastErr = set, time.Now(), nil
+
+	return set, nil
+}
+
+func (s *RemoteKeySource) fetch(ctx context.Context) (*KeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("build JWKS request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS: %w", err)
+	}
+
+	return ParseJWKS(data)
+}
@@ -0,0 +1,260 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken возвращается, если токен повреждён, подписан неизвестным ключом,
+// просрочен или выпущен не для этого сервиса.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims — утверждения из тела JWT.
+type Claims map[string]any
+
+// String возвращает строковое утверждение по пути path ("realm_access.roles" — вложенный объект).
+func (c Claims) String(path string) string {
+	s, _ := c.lookup(path).(string)
+	return s
+}
+
+// Strings возвращает утверждение по пути path как список строк: одиночная строка
+// даёт список из одного элемента, прочие типы — пустой список.
+func (c Claims) Strings(path string) []string {
+	switch v := c.lookup(path).(type) {
+	case string:
+		return []string{v}
+	case []any:
+		res := make([]string, 0, len(v))
+
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				res = append(res, s)
+			}
+		}
+
+		return res
+	default:
+		return nil
+	}
+}
+
+func (c Claims) lookup(path string) any {
+	var cur any = map[string]any(c)
+
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := cur.(map[string]any)
+
+		if !ok {
+			return nil
+		}
+
+		cur = obj[part]
+	}
+
+	return cur
+}
+
+// Verifier проверяет подпись и срок действия JWT, а также издателя и аудиторию, если они заданы.
+type Verifier struct {
+	keys     KeySource
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+// NewVerifier создаёт проверку токенов по ключам из keys. Пустые issuer и audience не проверяются;
+// leeway — допустимое расхождение часов при проверке exp и nbf.
+func NewVerifier(keys KeySource, issuer, audience string, leeway time.Duration) *Verifier {
+	return &Verifier{keys: keys, issuer: issuer, audience: audience, leeway: leeway}
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify проверяет токен на момент now и возвращает его утверждения.
+// Поддерживаются алгоритмы RS256/384/512, PS256/384/512 и ES256/384/512; exp обязателен.
+func (v *Verifier) Verify(ctx context.Context, token string, now time.Time) (Claims, error) {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var h header
+
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrInvalidToken, err)
+	}
+
+	alg, ok := algorithms[h.Alg]
+
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, h.Alg)
+	}
+
+	keys, err := v.keys.Keys(ctx, h.Kid)
+
+	if err != nil {
+		return nil, fmt.Errorf("load signing keys: %w", err)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+
+	if !alg.verifyAny(keys, signed, sig) {
+		return nil, fmt.Errorf("%w: signature does not match any key", ErrInvalidToken)
+	}
+
+	var claims Claims
+
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+
+	if err := v.checkClaims(claims, now); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) checkClaims(c Claims, now time.Time) error {
+	exp, ok := c["exp"].(float64)
+
+	if !ok {
+		return fmt.Errorf("%w: exp is required", ErrInvalidToken)
+	}
+
+	if now.After(time.Unix(int64(exp), 0).Add(v.leeway)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+
+	if nbf, ok := c["nbf"].(float64); ok && now.Add(v.leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+
+	if v.issuer != "" && c.String("iss") != v.issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, c.String("iss"))
+	}
+
+	if v.audience != "" && !contains(c.Strings("aud"), v.audience) {
+		return fmt.Errorf("%w: token is not issued for audience %q", ErrInvalidToken, v.audience)
+	}
+
+	return nil
+}
+
+func decodeSegment(seg string, out any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+
+	return false
+}
+
+// algorithm — алгоритм подписи JWS: хеш и семейство ключа.
+type algorithm struct {
+	hash crypto.Hash
+	// kind — "RS" (RSASSA-PKCS1-v1_5), "PS" (RSASSA-PSS) или "ES" (ECDSA).
+	kind string
+	// curveBits — размер кривой для ES (у каждого алгоритма ES своя кривая).
+	curveBits int
+}
+
+var algorithms = map[string]algorithm{
+	"RS256": {hash: crypto.SHA256, kind: "RS"},
+	"RS384": {hash: crypto.SHA384, kind: "RS"},
+	"RS512": {hash: crypto.SHA512, kind: "RS"},
+	"PS256": {hash: crypto.SHA256, kind: "PS"},
+	"PS384": {hash: crypto.SHA384, kind: "PS"},
+	"PS512": {hash: crypto.SHA512, kind: "PS"},
+	"ES256": {hash: crypto.SHA256, kind: "ES", curveBits: 256},
+	"ES384": {hash: crypto.SHA384, kind: "ES", curveBits: 384},
+	"ES512": {hash: crypto.SHA512, kind: "ES", curveBits: 521},
+}
+
+// verifyAny сообщает, подходит ли подпись хотя бы к одному ключу подходящего типа.
+func (a algorithm) verifyAny(keys []crypto.PublicKey, signed, sig []byte) bool {
+	digest := digest(a.hash, signed)
+
+	for _, key := range keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			switch a.kind {
+			case "RS":
+				if rsa.VerifyPKCS1v15(k, a.hash, digest, sig) == nil {
+					return true
+				}
+			case "PS":
+				if rsa.VerifyPSS(k, a.hash, digest, sig, nil) == nil {
+					return true
+				}
+			}
+		case *ecdsa.PublicKey:
+			if a.kind == "ES" && k.Curve.Params().BitSize == a.curveBits && verifyECDSA(k, digest, sig) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// verifyECDSA проверяет подпись JWS в формате r||s фиксированной длины (RFC 7518, 3.4).
+func verifyECDSA(key *ecdsa.PublicKey, digest, sig []byte) bool {
+	size := (key.Curve.Params().BitSize + 7) / 8
+
+	if len(sig) != 2*size {
+		return false
+	}
+
+	r := new(big.Int).SetBytes(sig[:size])
+	s := new(big.Int).SetBytes(sig[size:])
+
+	return ecdsa.Verify(key, digest, r, s)
+}
+
+func digest(h crypto.Hash, data []byte) []byte {
+	switch h {
+	case crypto.SHA384:
+		sum := sha512.Sum384(data)
+		return sum[:]
+	case crypto.SHA512:
+		sum := sha512.Sum512(data)
+		return sum[:]
+	default:
+		sum := sha256.Sum256(data)
+		return sum[:]
+	}
+}
@@ -0,0 +1,229 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// sign собирает токен с заголовком header, подписанный функцией signFn.
+func sign(t *testing.T, header, claims map[string]any, signFn func(digest []byte) []byte) string {
+	t.Helper()
+
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	signed := b64(h) + "." + b64(c)
+	digest := sha256.Sum256([]byte(signed))
+
+	return signed + "." + b64(signFn(digest[:]))
+}
+
+func testKeys(t *testing.T) (*rsa.PrivateKey, *ecdsa.PrivateKey, *KeySet) {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatalf("generate EC key: %v", err)
+	}
+
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": "AQAB"},
+		{"kty": "EC", "kid": "ec", "crv": "P-256",
+			"x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+	}})
+
+	set, err := ParseJWKS(jwks)
+
+	if err != nil {
+		t.Fatalf("parse JWKS: %v", err)
+	}
+
+	return rsaKey, ecKey, set
+}
+
+func TestVerifier(t *testing.T) {
+	rsaKey, ecKey, set := testKeys(t)
+	v := NewVerifier(NewStaticKeySource(set), "https://portal", "pr-reviewer", time.Minute)
+
+	claims := map[string]any{
+		"iss": "https://portal",
+		"aud": []string{"other", "pr-reviewer"},
+		"sub": "u1",
+		"exp": testNow.Add(time.Hour).Unix(),
+		"realm_access": map[string]any{
+			"roles": []string{"pr-leads", "staff"},
+		},
+	}
+
+	signRSA := func(d []byte) []byte {
+		sig, _ := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, d)
+		return sig
+	}
+
+	signEC := func(d []byte) []byte {
+		r, s, _ := ecdsa.Sign(rand.Reader, ecKey, d)
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	with := func(key string, value any) map[string]any {
+		c := make(map[string]any, len(claims))
+
+		for k, v := range claims {
+			c[k] = v
+		}
+
+		c[key] = value
+
+		return c
+	}
+
+	valid := []struct {
+		name  string
+		token string
+	}{
+		{"RS256", sign(t, map[string]any{"alg": "RS256", "kid": "rsa"}, claims, signRSA)},
+		{"ES256", sign(t, map[string]any{"alg": "ES256", "kid": "ec"}, claims, signEC)},
+		{"no kid", sign(t, map[string]any{"alg": "ES256"}, claims, signEC)},
+		{"clock skew", sign(t, map[string]any{"alg": "ES256", "kid": "ec"},
+			with("exp", testNow.Add(-30*time.Second).Unix()), signEC)},
+	}
+
+	for _, tc := range valid {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := v.Verify(context.Background(), tc.token, testNow)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if c.String("sub") != "u1" || len(c.Strings("realm_access.roles")) != 2 {
+				t.Fatalf("unexpected claims: %v", c)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name  string
+		token string
+	}{
+		{"malformed", "abc.def"},
+		{"alg none", sign(t, map[string]any{"alg": "none"}, claims, func([]byte) []byte { return nil })},
+		{"HS256", sign(t, map[string]any{"alg": "HS256", "kid": "hmac"}, claims, signRSA)},
+		{"key of other type", sign(t, map[string]any{"alg": "RS256", "kid": "ec"}, claims, signRSA)},
+		{"unknown kid", sign(t, map[string]any{"alg": "RS256", "kid": "old"}, claims, signRSA)},
+		{"expired", sign(t, map[string]any{"alg": "RS256", "kid": "rsa"},
+			with("exp", testNow.Add(-time.Hour).Unix()), signRSA)},
+		{"no exp", sign(t, map[string]any{"alg": "RS256", "kid": "rsa"}, with("exp", nil), signRSA)},
+		{"not yet valid", sign(t, map[string]any{"alg": "RS256", "kid": "rsa"},
+			with("nbf", testNow.Add(time.Hour).Unix()), signRSA)},
+		{"issuer", sign(t, map[string]any{"alg": "RS256", "kid": "rsa"}, with("iss", "https://evil"), signRSA)},
+		{"audience", sign(t, map[string]any{"alg": "RS256", "kid": "rsa"}, with("aud", "other"), signRSA)},
+	}
+
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := v.Verify(context.Background(), tc.token, testNow); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRemoteKeySource(t *testing.T) {
+	rsaKey, _, _ := testKeys(t)
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": "AQAB"},
+	}})
+
+	var (
+		hits    atomic.Int32
+		failing atomic.Bool
+	)
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		<-release
+
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = w.Write(jwks)
+	}))
+	defer srv.Close()
+
+	src := NewRemoteKeySource(srv.URL, srv.Client(), time.Hour)
+
+	// отмена запроса не прерывает общую загрузку, которую ждут остальные
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := src.Keys(cancelled, "rsa"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if keys, err := src.Keys(context.Background(), "rsa"); err != nil || len(keys) != 1 {
+				t.Errorf("expected one key, got %d (%v)", len(keys), err)
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := hits.Load(); n != 1 {
+		t.Fatalf("expected a single JWKS fetch, got %d", n)
+	}
+
+	// без загруженного набора ошибка провайдера не приводит к запросу на каждый токен
+	failing.Store(true)
+	empty := NewRemoteKeySource(srv.URL, srv.Client(), time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if _, err := empty.Keys(context.Background(), "rsa"); err == nil {
+			t.Fatal("expected fetch error")
+		}
+	}
+
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("expected failed fetch to be throttled, got %d fetches", n)
+	}
+}
@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// absenceColumns — список колонок user_absences в порядке, ожидаемом scanAbsence.
+const absenceColumns = `id, user_id, starts_at, ends_at, reason, source, external_uid, created_at, updated_at`
+
+func scanAbsence(row rowScanner) (domain.Absence, error) {
+	var (
+		a   domain.Absence
+		uid sql.NullString
+	)
+
+	if err := row.Scan(&a.ID, &a.UserID, &a.StartsAt, &a.EndsAt, &a.Reason, &a.Source, &uid, &a.CreatedAt, &a.UpdatedAt); err != nil {
+		return domain.Absence{}, err
+	}
+
+	a.ExternalUID = uid.String
+	return a, nil
+}
+
+// AbsenceRepository реализует domain.AbsenceRepository для PostgreSQL.
+type AbsenceRepository struct {
+	db *sql.DB
+}
+
+// NewAbsenceRepository создаёт новый AbsenceRepository.
+func NewAbsenceRepository(db *sql.DB) *AbsenceRepository {
+	return &AbsenceRepository{db: db}
+}
+
+// Create сохраняет новое отсутствие пользователя.
+func (r *AbsenceRepository) Create(ctx context.Context, a domain.Absence) (domain.Absence, error) {
+	now := time.Now().UTC()
+
+	created, err := scanAbsence(r.db.QueryRowContext(ctx,
+		`INSERT INTO user_absences (user_id, starts_at, ends_at, reason, source, external_uid, created_at, updated_at,
+		                            org_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 RETURNING `+absenceColumns,
+		a.UserID, a.StartsAt, a.EndsAt, a.Reason, string(a.Source), nullString(a.ExternalUID), now, now,
+		orgID(ctx),
+	))
+
+	if err != nil {
+		return domain.Absence{}, fmt.Errorf("insert absence: %w", err)
+	}
+
+	return created, nil
+}
+
+// Update изменяет интервал и причину отсутствия.
+func (r *AbsenceRepository) Update(ctx context.Context, a domain.Absence) (domain.Absence, error) {
+	updated, err := scanAbsence(r.db.QueryRowContext(ctx,
+		`UPDATE user_absences
+		    SET starts_at = $2,
+		        ends_at = $3,
+		        reason = $4,
+		        updated_at = $5
+		  WHERE id = $1 AND org_id = $6
+		  RETURNING `+absenceColumns,
+		a.ID, a.StartsAt, a.EndsAt, a.Reason, time.Now().UTC(), orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.Absence{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.Absence{}, fmt.Errorf("update absence: %w", err)
+	}
+
+	return updated, nil
+}
+
+// GetByID возвращает отсутствие по идентификатору.
+func (r *AbsenceRepository) GetByID(ctx context.Context, id int64) (domain.Absence, error) {
+	a, err := scanAbsence(r.db.QueryRowContext(ctx,
+		`SELECT `+absenceColumns+` FROM user_absences WHERE id = $1 AND org_id = $2`,
+		id, orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.Absence{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.Absence{}, fmt.Errorf("select absence: %w", err)
+	}
+
+	return a, nil
+}
+
+// Delete удаляет отсутствие и возвращает удалённую запись.
+func (r *AbsenceRepository) Delete(ctx context.Context, id int64) (domain.Absence, error) {
+	deleted, err := scanAbsence(r.db.QueryRowContext(ctx,
+		`DELETE FROM user_absences WHERE id = $1 AND org_id = $2 RETURNING `+absenceColumns,
+		id, orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.Absence{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.Absence{}, fmt.Errorf("delete absence: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// ListByUser возвращает отсутствия пользователя, упорядоченные по началу интервала.
+func (r *AbsenceRepository) ListByUser(ctx context.Context, userID string) ([]domain.Absence, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+absenceColumns+`
+		   FROM user_absences
+		  WHERE user_id = $1 AND org_id = $2
+		  ORDER BY starts_at, id`,
+		userID, orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("select absences: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.Absence
+
+	for rows.Next() {
+		a, err := scanAbsence(rows)
+
+		if err != nil {
+			return nil, fmt.Errorf("scan absence: %w", err)
+		}
+
+		res = append(res, a)
+	}
+
+	return res, nil
+}
+
+// UpsertImported сохраняет отсутствия из внешнего календаря в одной транзакции.
+// Записи с уже известным UID обновляются, а не дублируются.
+func (r *AbsenceRepository) UpsertImported(ctx context.Context, absences []domain.Absence) ([]domain.Absence, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC()
+	res := make([]domain.Absence, 0, len(absences))
+
+	for _, a := range absences {
+		saved, err := scanAbsence(tx.QueryRowContext(ctx,
+			`INSERT INTO user_absences (user_id, starts_at, ends_at, reason, source, external_uid, created_at, updated_at,
+			                            org_id)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			 ON CONFLICT (org_id, user_id, external_uid) WHERE external_uid IS NOT NULL DO UPDATE
+			 SET starts_at = EXCLUDED.starts_at,
+			     ends_at = EXCLUDED.ends_at,
+			     reason = EXCLUDED.reason,
+			     updated_at = EXCLUDED.updated_at
+			 RETURNING `+absenceColumns,
+			a.UserID, a.StartsAt, a.EndsAt, a.Reason, string(a.Source), nullString(a.ExternalUID), now, now,
+			orgID(ctx),
+		))
+
+		if err != nil {
+			return nil, fmt.Errorf("upsert absence %s: %w", a.ExternalUID, err)
+		}
+
+		res = append(res, saved)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return res, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
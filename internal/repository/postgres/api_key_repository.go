@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// apiKeyColumns — список колонок api_keys в порядке, ожидаемом scanAPIKey.
+const apiKeyColumns = `id, org_id, name, role, user_id, key_prefix, created_at, last_used_at, revoked_at`
+
+func scanAPIKey(row rowScanner) (domain.APIKey, error) {
+	var (
+		k                   domain.APIKey
+		userID              sql.NullString
+		lastUsed, revokedAt sql.NullTime
+	)
+
+	if err := row.Scan(&k.ID, &k.OrgID, &k.Name, &k.Role, &userID, &k.Prefix, &k.CreatedAt, &lastUsed, &revokedAt); err != nil {
+		return domain.APIKey{}, err
+	}
+
+	k.UserID = userID.String
+
+	if lastUsed.Valid {
+		k.LastUsedAt = &lastUsed.Time
+	}
+
+	if revokedAt.Valid {
+		k.RevokedAt = &revokedAt.Time
+	}
+
+	return k, nil
+}
+
+// APIKeyRepository реализует domain.APIKeyRepository для PostgreSQL.
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository создаёт новый APIKeyRepository.
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create сохраняет ключ с хешем hash.
+func (r *APIKeyRepository) Create(ctx context.Context, key domain.APIKey, hash string) (domain.APIKey, error) {
+	created, err := scanAPIKey(r.db.QueryRowContext(ctx,
+		`INSERT INTO api_keys (name, role, user_id, key_prefix, key_hash, created_at, org_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING `+apiKeyColumns,
+		key.Name, string(key.Role), nullString(key.UserID), key.Prefix, hash, time.Now().UTC(), orgID(ctx),
+	))
+
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("insert api key: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByHash возвращает неотозванный ключ по хешу. Это единственный запрос без фильтра
+// по организации: организация вызывающего определяется как раз по его ключу.
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (domain.APIKey, error) {
+	k, err := scanAPIKey(r.db.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+`
+		   FROM api_keys
+		  WHERE key_hash = $1
+		    AND revoked_at IS NULL`,
+		hash,
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.APIKey{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("select api key: %w", err)
+	}
+
+	return k, nil
+}
+
+// List возвращает все ключи организации, включая отозванные, в порядке создания.
+func (r *APIKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+apiKeyColumns+`
+		   FROM api_keys
+		  WHERE org_id = $1
+		  ORDER BY id`,
+		orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("select api keys: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.APIKey
+
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+
+		if err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+
+		res = append(res, k)
+	}
+
+	return res, rows.Err()
+}
+
+// Revoke отзывает ключ, сохраняя время первого отзыва.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int64, at time.Time) (domain.APIKey, error) {
+	k, err := scanAPIKey(r.db.QueryRowContext(ctx,
+		`UPDATE api_keys
+		    SET revoked_at = COALESCE(revoked_at, $2)
+		  WHERE id = $1 AND org_id = $3
+		 RETURNING `+apiKeyColumns,
+		id, at, orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.APIKey{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("revoke api key: %w", err)
+	}
+
+	return k, nil
+}
+
+// TouchLastUsed обновляет last_used_at не чаще раза в interval, чтобы не писать в базу на каждый запрос.
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id int64, at time.Time, interval time.Duration) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys
+		    SET last_used_at = $2
+		  WHERE id = $1
+		    AND (last_used_at IS NULL OR last_used_at < $3)`,
+		id, at, at.Add(-interval),
+	); err != nil {
+		return fmt.Errorf("update api key last_used_at: %w", err)
+	}
+
+	return nil
+}
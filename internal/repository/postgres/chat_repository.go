@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// ChatRepository реализует domain.ChatRepository для PostgreSQL.
+type ChatRepository struct {
+	db *sql.DB
+}
+
+// NewChatRepository создаёт новый ChatRepository.
+func NewChatRepository(db *sql.DB) *ChatRepository {
+	return &ChatRepository{db: db}
+}
+
+// ListChatChannels возвращает команды с настроенным вебхуком чата.
+func (r *ChatRepository) ListChatChannels(ctx context.Context) ([]domain.ChatChannel, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT team_name, chat_webhook_url, chat_channel, chat_cursor, chat_window_start, chat_window_count
+		   FROM teams
+		  WHERE chat_webhook_url IS NOT NULL
+		    AND org_id = $1
+		  ORDER BY team_name`,
+		orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("select chat channels: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.ChatChannel
+
+	for rows.Next() {
+		var (
+			ch          domain.ChatChannel
+			channel     sql.NullString
+			windowStart sql.NullTime
+		)
+
+		if err := rows.Scan(&ch.TeamName, &ch.WebhookURL, &channel, &ch.Cursor, &windowStart, &ch.WindowCount); err != nil {
+			return nil, fmt.Errorf("scan chat channel: %w", err)
+		}
+
+		ch.Channel = channel.String
+		ch.WindowStart = windowStart.Time
+		res = append(res, ch)
+	}
+
+	return res, rows.Err()
+}
+
+// ListAssignmentChanges возвращает изменения ревьюверов в PR, авторы которых состоят в команде.
+func (r *ChatRepository) ListAssignmentChanges(
+	ctx context.Context,
+	teamName string,
+	afterID int64,
+	before time.Time,
+	limit int,
+) ([]domain.AssignmentChange, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT h.id, h.tx_id, h.pr_id, p.name, h.action, h.actor_id,
+		        a.user_id, a.username, a.chat_handle,
+		        rv.user_id, rv.username, rv.chat_handle
+		   FROM review_assignment_history h
+		   JOIN pull_requests p ON p.org_id = h.org_id AND p.id = h.pr_id
+		   JOIN users a ON a.org_id = h.org_id AND a.user_id = h.author_id
+		   JOIN users rv ON rv.org_id = h.org_id AND rv.user_id = h.reviewer_id
+		  WHERE h.org_id = $5
+		    AND a.team_name = $1
+		    AND h.id > $2
+		    AND h.created_at <= $3
+		  ORDER BY h.id
+		  LIMIT $4`,
+		teamName, afterID, before, limit, orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("select assignment changes: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.AssignmentChange
+
+	for rows.Next() {
+		var (
+			c                            domain.AssignmentChange
+			actorID                      sql.NullString
+			authorHandle, reviewerHandle sql.NullString
+		)
+
+		if err := rows.Scan(&c.ID, &c.TxID, &c.PRID, &c.PRName, &c.Action, &actorID,
+			&c.Author.ID, &c.Author.Username, &authorHandle,
+			&c.Reviewer.ID, &c.Reviewer.Username, &reviewerHandle,
+		); err != nil {
+			return nil, fmt.Errorf("scan assignment change: %w", err)
+		}
+
+		c.ActorID = actorID.String
+		c.Author.ChatHandle = authorHandle.String
+		c.Reviewer.ChatHandle = reviewerHandle.String
+		res = append(res, c)
+	}
+
+	return res, rows.Err()
+}
+
+// SaveChatState сохраняет курсор и окно ограничения частоты. Если вебхук команды успел смениться,
+// состояние не перезаписывается: курсор уже перенесён при смене вебхука.
+func (r *ChatRepository) SaveChatState(ctx context.Context, ch domain.ChatChannel) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE teams
+		    SET chat_cursor = $3,
+		        chat_window_start = $4,
+		        chat_window_count = $5
+		  WHERE team_name = $1
+		    AND org_id = $6
+		    AND chat_webhook_url = $2`,
+		ch.TeamName, ch.WebhookURL, ch.Cursor, ch.WindowStart, ch.WindowCount, orgID(ctx),
+	); err != nil {
+		return fmt.Errorf("update chat state: %w", err)
+	}
+
+	return nil
+}
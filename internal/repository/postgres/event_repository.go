@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// eventColumns — список колонок events в порядке, ожидаемом scanEvent.
+const eventColumns = `id, type, team_name, pr_id, user_id, payload, created_at`
+
+func scanEvent(row rowScanner) (domain.Event, error) {
+	var (
+		e            domain.Event
+		prID, userID sql.NullString
+		payload      []byte
+	)
+
+	if err := row.Scan(&e.ID, &e.Type, &e.TeamName, &prID, &userID, &payload, &e.CreatedAt); err != nil {
+		return domain.Event{}, err
+	}
+
+	if err := json.Unmarshal(payload, &e.Payload); err != nil {
+		return domain.Event{}, fmt.Errorf("decode event payload: %w", err)
+	}
+
+	e.PRID = prID.String
+	e.UserID = userID.String
+	return e, nil
+}
+
+// EventRepository реализует domain.EventRepository для PostgreSQL.
+type EventRepository struct {
+	db *sql.DB
+}
+
+// NewEventRepository создаёт новый EventRepository.
+func NewEventRepository(db *sql.DB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// Publish сохраняет событие.
+func (r *EventRepository) Publish(ctx context.Context, e domain.Event) (domain.Event, error) {
+	payload := e.Payload
+
+	if payload == nil {
+		payload = map[string]string{}
+	}
+
+	raw, err := json.Marshal(payload)
+
+	if err != nil {
+		return domain.Event{}, fmt.Errorf("encode event payload: %w", err)
+	}
+
+	created, err := scanEvent(r.db.QueryRowContext(ctx,
+		`INSERT INTO events (type, team_name, pr_id, user_id, payload, created_at, org_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING `+eventColumns,
+		string(e.Type), e.TeamName, nullString(e.PRID), nullString(e.UserID), raw, time.Now().UTC(), orgID(ctx),
+	))
+
+	if err != nil {
+		return domain.Event{}, fmt.Errorf("insert event: %w", err)
+	}
+
+	return created, nil
+}
+
+// List возвращает до limit событий с идентификатором больше afterID в порядке публикации.
+func (r *EventRepository) List(ctx context.Context, teamName string, afterID int64, limit int) ([]domain.Event, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+eventColumns+`
+		   FROM events
+		  WHERE id > $1
+		    AND org_id = $4
+		    AND ($2 = '' OR team_name = $2)
+		  ORDER BY id
+		  LIMIT $3`,
+		afterID, teamName, limit, orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("select events: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.Event
+
+	for rows.Next() {
+		e, err := scanEvent(rows)
+
+		if err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+
+		res = append(res, e)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// IdempotencyRepository реализует domain.IdempotencyRepository для PostgreSQL.
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyRepository создаёт новый IdempotencyRepository.
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve занимает ключ одной вставкой, поэтому из конкурентных запросов с одним ключом
+// выполняется только один; истёкшая или брошенная незавершённой запись перезаписывается.
+func (r *IdempotencyRepository) Reserve(
+	ctx context.Context,
+	rec domain.IdempotencyRecord,
+	expiredBefore, staleBefore time.Time,
+) (domain.IdempotencyRecord, bool, error) {
+	var reservedKey string
+
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO idempotency_keys (idempotency_key, caller, request_hash, created_at, org_id)
+		 VALUES ($1, $2, $3, $4, $7)
+		 ON CONFLICT (org_id, caller, idempotency_key) DO UPDATE
+		    SET request_hash = EXCLUDED.request_hash,
+		        status_code = NULL,
+		        content_type = NULL,
+		        etag = NULL,
+		        response_body = NULL,
+		        created_at = EXCLUDED.created_at
+		  WHERE idempotency_keys.created_at < $5
+		     OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < $6)
+		 RETURNING idempotency_key`,
+		rec.Key, rec.Caller, rec.RequestHash, rec.CreatedAt, expiredBefore, staleBefore, orgID(ctx),
+	).Scan(&reservedKey)
+
+	if err == nil {
+		return rec, true, nil
+	}
+
+	if err != sql.ErrNoRows {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("reserve idempotency key: %w", err)
+	}
+
+	var (
+		stored      domain.IdempotencyRecord
+		statusCode  sql.NullInt32
+		contentType sql.NullString
+		etag        sql.NullString
+	)
+
+	err = r.db.QueryRowContext(ctx,
+		`SELECT idempotency_key, caller, request_hash, status_code, content_type, etag, response_body, created_at
+		   FROM idempotency_keys
+		  WHERE idempotency_key = $1 AND caller = $2 AND org_id = $3`,
+		rec.Key, rec.Caller, orgID(ctx),
+	).Scan(&stored.Key, &stored.Caller, &stored.RequestHash, &statusCode, &contentType, &etag,
+		&stored.Body, &stored.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return domain.IdempotencyRecord{}, false, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("select idempotency key: %w", err)
+	}
+
+	stored.Completed = statusCode.Valid
+	stored.StatusCode = int(statusCode.Int32)
+	stored.ContentType = contentType.String
+	stored.ETag = etag.String
+	return stored, false, nil
+}
+
+// Complete сохраняет ответ на запрос с ключом. Если ключ уже перехватил другой запрос
+// (попытка считалась брошенной), запись не меняется.
+func (r *IdempotencyRepository) Complete(ctx context.Context, rec domain.IdempotencyRecord) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE idempotency_keys
+		    SET status_code = $4,
+		        content_type = $5,
+		        etag = $6,
+		        response_body = $7
+		  WHERE idempotency_key = $1 AND caller = $2 AND created_at = $3
+		    AND status_code IS NULL AND org_id = $8`,
+		rec.Key, rec.Caller, rec.CreatedAt, rec.StatusCode, nullString(rec.ContentType), nullString(rec.ETag),
+		rec.Body, orgID(ctx),
+	)
+
+	if err != nil {
+		return fmt.Errorf("update idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Release удаляет запись ключа, занятую попыткой rec, если ответ на неё ещё не сохранён.
+func (r *IdempotencyRepository) Release(ctx context.Context, rec domain.IdempotencyRecord) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys
+		  WHERE idempotency_key = $1 AND caller = $2 AND created_at = $3
+		    AND status_code IS NULL AND org_id = $4`,
+		rec.Key, rec.Caller, rec.CreatedAt, orgID(ctx),
+	)
+
+	if err != nil {
+		return fmt.Errorf("delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired удаляет записи старше before.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE created_at < $1 AND org_id = $2`,
+		before, orgID(ctx),
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// JobLocker реализует domain.JobLocker на сессионных advisory-блокировках PostgreSQL.
+// Блокировка держится на отдельном соединении и снимается при его закрытии, поэтому
+// упавший экземпляр не оставляет задачу заблокированной.
+type JobLocker struct {
+	db *sql.DB
+}
+
+// NewJobLocker создаёт новый JobLocker.
+func NewJobLocker(db *sql.DB) *JobLocker {
+	return &JobLocker{db: db}
+}
+
+// TryRun выполняет fn, если удалось взять advisory-блокировку с ключом hashtext(name).
+func (l *JobLocker) TryRun(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	conn, err := l.db.Conn(ctx)
+
+	if err != nil {
+		return false, fmt.Errorf("acquire conn: %w", err)
+	}
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	var locked bool
+
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, name).Scan(&locked); err != nil {
+		return false, fmt.Errorf("try advisory lock: %w", err)
+	}
+
+	if !locked {
+		return false, nil
+	}
+
+	defer func() {
+		// контекст задачи мог быть отменён, а блокировку нужно снять в любом случае
+		_, _ = conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, name)
+	}()
+
+	return true, fn(ctx)
+}
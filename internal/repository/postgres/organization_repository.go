@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// organizationColumns — список колонок organizations в порядке, ожидаемом scanOrganization.
+const organizationColumns = `id, slug, name, chat_team_id, created_at`
+
+func scanOrganization(row rowScanner) (domain.Organization, error) {
+	var (
+		o          domain.Organization
+		chatTeamID sql.NullString
+	)
+
+	if err := row.Scan(&o.ID, &o.Slug, &o.Name, &chatTeamID, &o.CreatedAt); err != nil {
+		return domain.Organization{}, err
+	}
+
+	o.ChatTeamID = chatTeamID.String
+	return o, nil
+}
+
+// OrganizationRepository реализует domain.OrganizationRepository для PostgreSQL.
+type OrganizationRepository struct {
+	db *sql.DB
+}
+
+// NewOrganizationRepository создаёт новый OrganizationRepository.
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// Create сохраняет новую организацию.
+func (r *OrganizationRepository) Create(ctx context.Context, org domain.Organization) (domain.Organization, error) {
+	created, err := scanOrganization(r.db.QueryRowContext(ctx,
+		`INSERT INTO organizations (slug, name, chat_team_id, created_at)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING `+organizationColumns,
+		org.Slug, org.Name, nullString(org.ChatTeamID), time.Now().UTC(),
+	))
+
+	if err != nil {
+		return domain.Organization{}, fmt.Errorf("insert organization: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetBySlug возвращает организацию по короткому имени.
+func (r *OrganizationRepository) GetBySlug(ctx context.Context, slug string) (domain.Organization, error) {
+	return r.getBy(ctx, "slug", slug)
+}
+
+// GetByChatTeamID возвращает организацию по идентификатору рабочего пространства чата.
+func (r *OrganizationRepository) GetByChatTeamID(ctx context.Context, chatTeamID string) (domain.Organization, error) {
+	return r.getBy(ctx, "chat_team_id", chatTeamID)
+}
+
+// getBy возвращает организацию по значению уникальной колонки column.
+func (r *OrganizationRepository) getBy(ctx context.Context, column, value string) (domain.Organization, error) {
+	o, err := scanOrganization(r.db.QueryRowContext(ctx,
+		`SELECT `+organizationColumns+` FROM organizations WHERE `+column+` = $1`,
+		value,
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.Organization{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.Organization{}, fmt.Errorf("select organization: %w", err)
+	}
+
+	return o, nil
+}
+
+// List возвращает все организации в порядке создания.
+func (r *OrganizationRepository) List(ctx context.Context) ([]domain.Organization, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+organizationColumns+` FROM organizations ORDER BY id`,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("select organizations: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.Organization
+
+	for rows.Next() {
+		o, err := scanOrganization(rows)
+
+		if err != nil {
+			return nil, fmt.Errorf("scan organization: %w", err)
+		}
+
+		res = append(res, o)
+	}
+
+	return res, rows.Err()
+}
+
+// SetChatTeamID меняет рабочее пространство чата организации.
+func (r *OrganizationRepository) SetChatTeamID(
+	ctx context.Context,
+	id int64,
+	chatTeamID string,
+) (domain.Organization, error) {
+	o, err := scanOrganization(r.db.QueryRowContext(ctx,
+		`UPDATE organizations SET chat_team_id = $2 WHERE id = $1 RETURNING `+organizationColumns,
+		id, nullString(chatTeamID),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.Organization{}, domain.ErrNotFound
+	}
+
+	if isUniqueViolation(err) {
+		return domain.Organization{}, domain.ErrOrgExists
+	}
+
+	if err != nil {
+		return domain.Organization{}, fmt.Errorf("update organization chat team: %w", err)
+	}
+
+	return o, nil
+}
@@ -1,14 +1,21 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 
 	"pr-reviewer-service/internal/config"
+	"pr-reviewer-service/internal/domain"
 )
 
+// uniqueViolation — SQLSTATE нарушения ограничения уникальности.
+const uniqueViolation = "23505"
+
 // NewDB создаёт и настраивает подключение к PostgreSQL.
 func NewDB(cfg config.DBConfig) (*sql.DB, error) {
 	db, err := sql.Open("pgx", cfg.DSN)
@@ -28,3 +35,16 @@ func NewDB(cfg config.DBConfig) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// orgID возвращает организацию из контекста: каждый запрос репозиториев ограничен её данными,
+// а без организации в контексте ничего не находится и ничего не вставляется.
+func orgID(ctx context.Context) int64 {
+	return domain.OrganizationFrom(ctx)
+}
+
+// isUniqueViolation сообщает, что запрос нарушил ограничение уникальности, например, когда
+// конкурентный запрос успел вставить ту же строку.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
@@ -19,37 +19,61 @@ func NewPullRequestRepository(db *sql.DB) *PullRequestRepository {
 	return &PullRequestRepository{db: db}
 }
 
-// Create создаёт pull request и его ревьюеров в одной транзакции.
+// Create создаёт pull request и его ревьюеров в одной транзакции (внутри WithTx — во внешней).
+// Если PR с тем же идентификатором (или номером в репозитории) уже есть, в том числе созданный
+// конкурентным запросом, возвращает ErrPRExists.
 func (r *PullRequestRepository) Create(ctx context.Context, pr domain.PullRequest) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, commit, rollback, err := beginTx(ctx, r.db)
 
 	if err != nil {
-		return fmt.Errorf("begin tx: %w", err)
+		return err
 	}
 
-	defer func() { _ = tx.Rollback() }()
+	defer rollback()
 
 	_, err = tx.ExecContext(ctx,
-		`INSERT INTO pull_requests (id, name, author_id, status, created_at, merged_at)
-		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		`INSERT INTO pull_requests (id, name, author_id, status, created_at, merged_at,
+		                            repository_id, number, org_id)
+		 VALUES ($1, $2, $3, $4, $5, $6,
+		         (SELECT id FROM repositories WHERE name = $7 AND org_id = $9), $8, $9)`,
 		pr.ID, pr.Name, pr.AuthorID, string(pr.Status), pr.CreatedAt, pr.MergedAt,
+		pr.Repository, sql.NullInt32{Int32: int32(pr.Number), Valid: pr.Number > 0}, orgID(ctx),
 	)
 
+	if isUniqueViolation(err) {
+		return domain.ErrPRExists
+	}
+
 	if err != nil {
 		return fmt.Errorf("insert pull_request: %w", err)
 	}
 
 	for _, reviewerID := range pr.AssignedReviewers {
 		if _, err := tx.ExecContext(ctx,
-			`INSERT INTO pr_reviewers (pr_id, reviewer_id)
-			 VALUES ($1, $2)`,
-			pr.ID, reviewerID,
+			`INSERT INTO pr_reviewers (pr_id, reviewer_id, org_id)
+			 VALUES ($1, $2, $3)`,
+			pr.ID, reviewerID, orgID(ctx),
 		); err != nil {
 			return fmt.Errorf("insert pr_reviewer: %w", err)
 		}
+
+		if err := insertHistory(ctx, tx, pr.ID, reviewerID, "", domain.AssignmentActionAssigned); err != nil {
+			return err
+		}
 	}
 
-	if err := tx.Commit(); err != nil {
+	for _, label := range pr.Labels {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO pr_labels (pr_id, label, org_id)
+			 VALUES ($1, $2, $3)
+			 ON CONFLICT DO NOTHING`,
+			pr.ID, label, orgID(ctx),
+		); err != nil {
+			return fmt.Errorf("insert pr_label: %w", err)
+		}
+	}
+
+	if err := commit(); err != nil {
 		return fmt.Errorf("commit tx: %w", err)
 	}
 
@@ -58,14 +82,19 @@ func (r *PullRequestRepository) Create(ctx context.Context, pr domain.PullReques
 
 // GetByID возвращает полный pull request с назначенными ревьюерами.
 func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (domain.PullRequest, error) {
-	var pr domain.PullRequest
+	var (
+		pr         domain.PullRequest
+		repository sql.NullString
+		number     sql.NullInt32
+	)
 
-	err := r.db.QueryRowContext(ctx,
-		`SELECT id, name, author_id, status, created_at, merged_at
-		   FROM pull_requests
-		  WHERE id = $1`,
-		id,
-	).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt)
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT p.id, p.name, p.author_id, p.status, p.created_at, p.merged_at, r.name, p.number, p.version
+		   FROM pull_requests p
+		   LEFT JOIN repositories r ON r.id = p.repository_id
+		  WHERE p.id = $1 AND p.org_id = $2`,
+		id, orgID(ctx),
+	).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &repository, &number, &pr.Version)
 
 	if err == sql.ErrNoRows {
 		return domain.PullRequest{}, domain.ErrNotFound
@@ -75,9 +104,12 @@ func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (domain.
 		return domain.PullRequest{}, fmt.Errorf("select pull_request: %w", err)
 	}
 
-	rows, err := r.db.QueryContext(ctx,
-		`SELECT reviewer_id FROM pr_reviewers WHERE pr_id = $1`,
-		id,
+	pr.Repository = repository.String
+	pr.Number = int(number.Int32)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT reviewer_id FROM pr_reviewers WHERE pr_id = $1 AND org_id = $2`,
+		id, orgID(ctx),
 	)
 
 	if err != nil {
@@ -101,21 +133,92 @@ func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (domain.
 	}
 
 	pr.AssignedReviewers = reviewers
+
+	labelRows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT label FROM pr_labels WHERE pr_id = $1 AND org_id = $2 ORDER BY label`,
+		id, orgID(ctx),
+	)
+
+	if err != nil {
+		return domain.PullRequest{}, fmt.Errorf("select labels: %w", err)
+	}
+
+	defer func() {
+		_ = labelRows.Close()
+	}()
+
+	for labelRows.Next() {
+		var label string
+
+		if err := labelRows.Scan(&label); err != nil {
+			return domain.PullRequest{}, fmt.Errorf("scan label: %w", err)
+		}
+
+		pr.Labels = append(pr.Labels, label)
+	}
+
 	return pr, nil
 }
 
 // MarkMerged помечает pull request как merged и возвращает обновлённую сущность.
 func (r *PullRequestRepository) MarkMerged(ctx context.Context, id string, mergedAt time.Time) (domain.PullRequest, error) {
-	res, err := r.db.ExecContext(ctx,
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return domain.PullRequest{}, fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := lockPR(ctx, tx, id); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx,
 		`UPDATE pull_requests
 		    SET status = $2,
-		        merged_at = $3
-		  WHERE id = $1`,
-		id, string(domain.PRStatusMerged), mergedAt,
+		        merged_at = $3,
+		        version = version + 1
+		  WHERE id = $1 AND org_id = $4`,
+		id, string(domain.PRStatusMerged), mergedAt, orgID(ctx),
+	); err != nil {
+		return domain.PullRequest{}, fmt.Errorf("update pull_request merged: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.PullRequest{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// ReassignReviewer заменяет одного ревьюера другим в рамках транзакции (или внешней транзакции WithTx).
+func (r *PullRequestRepository) ReassignReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) (domain.PullRequest, error) {
+	tx, commit, rollback, err := beginTx(ctx, r.db)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	defer rollback()
+
+	status, err := lockPR(ctx, tx, prID)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if status == domain.PRStatusMerged {
+		return domain.PullRequest{}, domain.ErrPRMerged
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`DELETE FROM pr_reviewers WHERE pr_id = $1 AND reviewer_id = $2 AND org_id = $3`,
+		prID, oldReviewerID, orgID(ctx),
 	)
 
 	if err != nil {
-		return domain.PullRequest{}, fmt.Errorf("update pull_request merged: %w", err)
+		return domain.PullRequest{}, fmt.Errorf("delete old reviewer: %w", err)
 	}
 
 	affected, err := res.RowsAffected()
@@ -125,14 +228,39 @@ func (r *PullRequestRepository) MarkMerged(ctx context.Context, id string, merge
 	}
 
 	if affected == 0 {
-		return domain.PullRequest{}, domain.ErrNotFound
+		return domain.PullRequest{}, domain.ErrReviewerNotAssigned
 	}
 
-	return r.GetByID(ctx, id)
+	if err := insertReviewer(ctx, tx, prID, newReviewerID); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := insertHistory(ctx, tx, prID, oldReviewerID, "", domain.AssignmentActionUnassigned); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := insertHistory(ctx, tx, prID, newReviewerID, "", domain.AssignmentActionAssigned); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := bumpPRVersion(ctx, tx, prID); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := commit(); err != nil {
+		return domain.PullRequest{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return r.GetByID(ctx, prID)
 }
 
-// ReassignReviewer заменяет одного ревьюера другим в рамках транзакции.
-func (r *PullRequestRepository) ReassignReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) (domain.PullRequest, error) {
+// AddReviewer добавляет ревьюера на PR. Строка PR блокируется, чтобы параллельные добавления
+// не превысили maxReviewers.
+func (r *PullRequestRepository) AddReviewer(
+	ctx context.Context,
+	prID, reviewerID, actorID string,
+	maxReviewers int,
+) (domain.PullRequest, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 
 	if err != nil {
@@ -141,13 +269,86 @@ func (r *PullRequestRepository) ReassignReviewer(ctx context.Context, prID, oldR
 
 	defer func() { _ = tx.Rollback() }()
 
+	status, err := lockPR(ctx, tx, prID)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if status == domain.PRStatusMerged {
+		return domain.PullRequest{}, domain.ErrPRMerged
+	}
+
+	var (
+		count    int
+		assigned bool
+	)
+
+	err = tx.QueryRowContext(ctx,
+		`SELECT COUNT(*), COALESCE(BOOL_OR(reviewer_id = $2), FALSE)
+		   FROM pr_reviewers
+		  WHERE pr_id = $1 AND org_id = $3`,
+		prID, reviewerID, orgID(ctx),
+	).Scan(&count, &assigned)
+
+	if err != nil {
+		return domain.PullRequest{}, fmt.Errorf("count reviewers: %w", err)
+	}
+
+	if assigned {
+		return domain.PullRequest{}, domain.ErrAlreadyAssigned
+	}
+
+	if count >= maxReviewers {
+		return domain.PullRequest{}, domain.ErrTooManyReviewers
+	}
+
+	if err := insertReviewer(ctx, tx, prID, reviewerID); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := insertHistory(ctx, tx, prID, reviewerID, actorID, domain.AssignmentActionAssigned); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := bumpPRVersion(ctx, tx, prID); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.PullRequest{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return r.GetByID(ctx, prID)
+}
+
+// RemoveReviewer снимает ревьюера с PR.
+func (r *PullRequestRepository) RemoveReviewer(ctx context.Context, prID, reviewerID, actorID string) (domain.PullRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return domain.PullRequest{}, fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	status, err := lockPR(ctx, tx, prID)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if status == domain.PRStatusMerged {
+		return domain.PullRequest{}, domain.ErrPRMerged
+	}
+
 	res, err := tx.ExecContext(ctx,
-		`DELETE FROM pr_reviewers WHERE pr_id = $1 AND reviewer_id = $2`,
-		prID, oldReviewerID,
+		`DELETE FROM pr_reviewers WHERE pr_id = $1 AND reviewer_id = $2 AND org_id = $3`,
+		prID, reviewerID, orgID(ctx),
 	)
 
 	if err != nil {
-		return domain.PullRequest{}, fmt.Errorf("delete old reviewer: %w", err)
+		return domain.PullRequest{}, fmt.Errorf("delete reviewer: %w", err)
 	}
 
 	affected, err := res.RowsAffected()
@@ -160,14 +361,12 @@ func (r *PullRequestRepository) ReassignReviewer(ctx context.Context, prID, oldR
 		return domain.PullRequest{}, domain.ErrReviewerNotAssigned
 	}
 
-	_, err = tx.ExecContext(ctx,
-		`INSERT INTO pr_reviewers (pr_id, reviewer_id)
-		 VALUES ($1, $2)`,
-		prID, newReviewerID,
-	)
+	if err := insertHistory(ctx, tx, prID, reviewerID, actorID, domain.AssignmentActionUnassigned); err != nil {
+		return domain.PullRequest{}, err
+	}
 
-	if err != nil {
-		return domain.PullRequest{}, fmt.Errorf("insert new reviewer: %w", err)
+	if err := bumpPRVersion(ctx, tx, prID); err != nil {
+		return domain.PullRequest{}, err
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -177,14 +376,201 @@ func (r *PullRequestRepository) ReassignReviewer(ctx context.Context, prID, oldR
 	return r.GetByID(ctx, prID)
 }
 
+// DeclineReview снимает ревьюера с PR по его отказу, назначает замену (если она выбрана)
+// и сохраняет отказ с причиной. В истории снятие записывается от имени decline.ActorID.
+// Внутри WithTx выполняется во внешней транзакции.
+func (r *PullRequestRepository) DeclineReview(ctx context.Context, decline domain.ReviewDecline) (domain.PullRequest, error) {
+	tx, commit, rollback, err := beginTx(ctx, r.db)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	defer rollback()
+
+	status, err := lockPR(ctx, tx, decline.PRID)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if status == domain.PRStatusMerged {
+		return domain.PullRequest{}, domain.ErrPRMerged
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`DELETE FROM pr_reviewers WHERE pr_id = $1 AND reviewer_id = $2 AND org_id = $3`,
+		decline.PRID, decline.ReviewerID, orgID(ctx),
+	)
+
+	if err != nil {
+		return domain.PullRequest{}, fmt.Errorf("delete declining reviewer: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return domain.PullRequest{}, fmt.Errorf("rows affected: %w", err)
+	}
+
+	if affected == 0 {
+		return domain.PullRequest{}, domain.ErrReviewerNotAssigned
+	}
+
+	if err := insertHistory(ctx, tx, decline.PRID, decline.ReviewerID, decline.ActorID, domain.AssignmentActionUnassigned); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if decline.ReplacedBy != "" {
+		if err := insertReviewer(ctx, tx, decline.PRID, decline.ReplacedBy); err != nil {
+			return domain.PullRequest{}, err
+		}
+
+		if err := insertHistory(ctx, tx, decline.PRID, decline.ReplacedBy, "", domain.AssignmentActionAssigned); err != nil {
+			return domain.PullRequest{}, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO review_declines (pr_id, reviewer_id, reason, replaced_by, created_at, org_id)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		decline.PRID, decline.ReviewerID, decline.Reason, nullString(decline.ReplacedBy), time.Now().UTC(),
+		orgID(ctx),
+	); err != nil {
+		return domain.PullRequest{}, fmt.Errorf("insert review decline: %w", err)
+	}
+
+	if err := bumpPRVersion(ctx, tx, decline.PRID); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := commit(); err != nil {
+		return domain.PullRequest{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return r.GetByID(ctx, decline.PRID)
+}
+
+// ListDecliners возвращает пользователей, отказывавшихся от ревью PR.
+func (r *PullRequestRepository) ListDecliners(ctx context.Context, prID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT DISTINCT reviewer_id FROM review_declines WHERE pr_id = $1 AND org_id = $2`,
+		prID, orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("select decliners: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []string
+
+	for rows.Next() {
+		var id string
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan decliner: %w", err)
+		}
+
+		res = append(res, id)
+	}
+
+	return res, nil
+}
+
+// MarkReviewed отмечает, что ревьювер взялся за ревью; повторная отметка сохраняет первое время.
+func (r *PullRequestRepository) MarkReviewed(ctx context.Context, prID, reviewerID string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE pr_reviewers
+		    SET reviewed_at = COALESCE(reviewed_at, $3)
+		  WHERE pr_id = $1 AND reviewer_id = $2 AND org_id = $4`,
+		prID, reviewerID, at, orgID(ctx),
+	)
+
+	if err != nil {
+		return fmt.Errorf("update reviewed_at: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if affected == 0 {
+		return domain.ErrReviewerNotAssigned
+	}
+
+	return nil
+}
+
+// ListStaleAssignments возвращает назначения в OPEN PR, которые ревьювер не отметил
+// и которые ещё не эскалировались, старше SLA команды автора на момент at (старые первыми).
+func (r *PullRequestRepository) ListStaleAssignments(ctx context.Context, at time.Time) ([]domain.StaleAssignment, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT rview.pr_id, rview.reviewer_id, t.team_name, rview.assigned_at
+		   FROM pr_reviewers rview
+		   JOIN pull_requests p ON p.org_id = rview.org_id AND p.id = rview.pr_id
+		   JOIN users a ON a.org_id = p.org_id AND a.user_id = p.author_id
+		   JOIN teams t ON t.org_id = a.org_id AND t.team_name = a.team_name
+		  WHERE rview.org_id = $3
+		    AND p.status = $1
+		    AND rview.reviewed_at IS NULL
+		    AND rview.escalated_at IS NULL
+		    AND t.review_sla_hours > 0
+		    AND rview.assigned_at <= $2::timestamptz - make_interval(hours => t.review_sla_hours)
+		  ORDER BY rview.assigned_at, rview.pr_id, rview.reviewer_id`,
+		string(domain.PRStatusOpen), at, orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("select stale assignments: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.StaleAssignment
+
+	for rows.Next() {
+		var a domain.StaleAssignment
+
+		if err := rows.Scan(&a.PRID, &a.ReviewerID, &a.TeamName, &a.AssignedAt); err != nil {
+			return nil, fmt.Errorf("scan stale assignment: %w", err)
+		}
+
+		res = append(res, a)
+	}
+
+	return res, nil
+}
+
+// MarkEscalated отмечает, что по назначению уже была эскалация, чтобы не повторять её.
+func (r *PullRequestRepository) MarkEscalated(ctx context.Context, prID, reviewerID string, at time.Time) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE pr_reviewers
+		    SET escalated_at = $3
+		  WHERE pr_id = $1 AND reviewer_id = $2 AND org_id = $4`,
+		prID, reviewerID, at, orgID(ctx),
+	); err != nil {
+		return fmt.Errorf("update escalated_at: %w", err)
+	}
+
+	return nil
+}
+
 // ListByReviewer возвращает список PR, назначенных конкретному ревьюеру.
 func (r *PullRequestRepository) ListByReviewer(ctx context.Context, reviewerID string) ([]domain.PullRequestShort, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT p.id, p.name, p.author_id, p.status
+		`SELECT p.id, p.name, p.author_id, p.status, p.created_at
 		   FROM pull_requests p
-		   JOIN pr_reviewers rview ON p.id = rview.pr_id
-		  WHERE rview.reviewer_id = $1`,
-		reviewerID,
+		   JOIN pr_reviewers rview ON p.org_id = rview.org_id AND p.id = rview.pr_id
+		  WHERE rview.reviewer_id = $1 AND rview.org_id = $2`,
+		reviewerID, orgID(ctx),
 	)
 
 	if err != nil {
@@ -198,25 +584,82 @@ func (r *PullRequestRepository) ListByReviewer(ctx context.Context, reviewerID s
 	var res []domain.PullRequestShort
 
 	for rows.Next() {
-		var pr domain.PullRequestShort
+		var (
+			pr        domain.PullRequestShort
+			createdAt sql.NullTime
+		)
 
-		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status); err != nil {
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &createdAt); err != nil {
 			return nil, fmt.Errorf("scan pr: %w", err)
 		}
 
+		if createdAt.Valid {
+			pr.CreatedAt = &createdAt.Time
+		}
+
 		res = append(res, pr)
 	}
 
 	return res, nil
 }
 
+// ListAwaitingByAuthor возвращает OPEN PR автора с ревьюверами, ещё не отметившими ревью (старые первыми).
+func (r *PullRequestRepository) ListAwaitingByAuthor(ctx context.Context, authorID string) ([]domain.AwaitingReview, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT p.id, p.name, p.author_id, p.status, p.created_at, rview.reviewer_id
+		   FROM pull_requests p
+		   JOIN pr_reviewers rview ON p.org_id = rview.org_id AND p.id = rview.pr_id
+		  WHERE p.author_id = $1
+		    AND p.org_id = $3
+		    AND p.status = $2
+		    AND rview.reviewed_at IS NULL
+		  ORDER BY p.created_at NULLS LAST, p.id, rview.reviewer_id`,
+		authorID, string(domain.PRStatusOpen), orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("select awaiting prs: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.AwaitingReview
+
+	for rows.Next() {
+		var (
+			pr         domain.PullRequestShort
+			createdAt  sql.NullTime
+			reviewerID string
+		)
+
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &createdAt, &reviewerID); err != nil {
+			return nil, fmt.Errorf("scan awaiting pr: %w", err)
+		}
+
+		if n := len(res); n > 0 && res[n-1].PR.ID == pr.ID {
+			res[n-1].Reviewers = append(res[n-1].Reviewers, reviewerID)
+			continue
+		}
+
+		if createdAt.Valid {
+			pr.CreatedAt = &createdAt.Time
+		}
+
+		res = append(res, domain.AwaitingReview{PR: pr, Reviewers: []string{reviewerID}})
+	}
+
+	return res, nil
+}
+
 // PRExists проверяет, существует ли pull request с таким идентификатором.
 func (r *PullRequestRepository) PRExists(ctx context.Context, id string) (bool, error) {
 	var exists bool
 
 	err := r.db.QueryRowContext(ctx,
-		`SELECT TRUE FROM pull_requests WHERE id = $1`,
-		id,
+		`SELECT TRUE FROM pull_requests WHERE id = $1 AND org_id = $2`,
+		id, orgID(ctx),
 	).Scan(&exists)
 
 	if err == sql.ErrNoRows {
@@ -230,12 +673,29 @@ func (r *PullRequestRepository) PRExists(ctx context.Context, id string) (bool,
 	return true, nil
 }
 
-// GetAssignmentStatsByUser возвращает статистику количества назначений по каждому ревьюеру.
-func (r *PullRequestRepository) GetAssignmentStatsByUser(ctx context.Context) ([]domain.AssignmentStatByUser, error) {
+// GetAssignmentStatsByUser возвращает статистику количества назначений по каждому ревьюеру
+// команды teamName (пусто — всех команд).
+func (r *PullRequestRepository) GetAssignmentStatsByUser(
+	ctx context.Context,
+	teamName string,
+) ([]domain.AssignmentStatByUser, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT reviewer_id, COUNT(*) 
-		   FROM pr_reviewers
-		  GROUP BY reviewer_id`,
+		`SELECT COALESCE(a.reviewer_id, d.reviewer_id), COALESCE(a.cnt, 0), COALESCE(d.cnt, 0)
+		   FROM (SELECT reviewer_id, COUNT(*) AS cnt
+		           FROM pr_reviewers
+		          WHERE org_id = $2
+		          GROUP BY reviewer_id) a
+		   FULL JOIN (SELECT reviewer_id, COUNT(*) AS cnt
+		                FROM review_declines
+		               WHERE org_id = $2
+		               GROUP BY reviewer_id) d
+		     ON d.reviewer_id = a.reviewer_id
+		  WHERE $1 = ''
+		     OR EXISTS (SELECT 1 FROM users u
+		                 WHERE u.org_id = $2
+		                   AND u.user_id = COALESCE(a.reviewer_id, d.reviewer_id)
+		                   AND u.team_name = $1)`,
+		teamName, orgID(ctx),
 	)
 
 	if err != nil {
@@ -251,7 +711,7 @@ func (r *PullRequestRepository) GetAssignmentStatsByUser(ctx context.Context) ([
 	for rows.Next() {
 		var s domain.AssignmentStatByUser
 
-		if err := rows.Scan(&s.UserID, &s.Count); err != nil {
+		if err := rows.Scan(&s.UserID, &s.Count, &s.Declines); err != nil {
 			return nil, fmt.Errorf("scan stat: %w", err)
 		}
 
@@ -261,9 +721,221 @@ func (r *PullRequestRepository) GetAssignmentStatsByUser(ctx context.Context) ([
 	return res, nil
 }
 
+// CountOpenReviews возвращает количество OPEN pull request-ов, назначенных каждому из ревьюеров.
+// Ревьюеры без открытых ревью в результат не попадают.
+func (r *PullRequestRepository) CountOpenReviews(ctx context.Context, reviewerIDs []string) (map[string]int, error) {
+	res := make(map[string]int, len(reviewerIDs))
+
+	if len(reviewerIDs) == 0 {
+		return res, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT rview.reviewer_id, COUNT(*)
+		   FROM pr_reviewers rview
+		   JOIN pull_requests p ON p.org_id = rview.org_id AND p.id = rview.pr_id
+		  WHERE p.status = $1
+		    AND rview.org_id = $3
+		    AND rview.reviewer_id = ANY($2)
+		  GROUP BY rview.reviewer_id`,
+		string(domain.PRStatusOpen), reviewerIDs, orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("count open reviews: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var (
+			id    string
+			count int
+		)
+
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, fmt.Errorf("scan open reviews: %w", err)
+		}
+
+		res[id] = count
+	}
+
+	return res, rows.Err()
+}
+
+// CountPairings возвращает, сколько раз каждый из ревьюеров назначался на PR автора начиная с since.
+func (r *PullRequestRepository) CountPairings(
+	ctx context.Context,
+	authorID string,
+	reviewerIDs []string,
+	since time.Time,
+) (map[string]int, error) {
+	res := make(map[string]int, len(reviewerIDs))
+
+	if len(reviewerIDs) == 0 {
+		return res, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT reviewer_id, COUNT(*)
+		   FROM review_assignment_history
+		  WHERE action = $1
+		    AND org_id = $5
+		    AND author_id = $2
+		    AND reviewer_id = ANY($3)
+		    AND created_at >= $4
+		  GROUP BY reviewer_id`,
+		string(domain.AssignmentActionAssigned), authorID, reviewerIDs, since, orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("count pairings: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var (
+			id    string
+			count int
+		)
+
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, fmt.Errorf("scan pairing: %w", err)
+		}
+
+		res[id] = count
+	}
+
+	return res, rows.Err()
+}
+
+// lockPR блокирует строку PR до конца транзакции, чтобы параллельные изменения PR выполнялись
+// по очереди, и сверяет её версию с ожидаемой (If-Match). Возвращает статус PR.
+func lockPR(ctx context.Context, tx *sql.Tx, prID string) (domain.PRStatus, error) {
+	var (
+		status  domain.PRStatus
+		version int64
+	)
+
+	err := tx.QueryRowContext(ctx,
+		`SELECT status, version FROM pull_requests WHERE id = $1 AND org_id = $2 FOR UPDATE`,
+		prID, orgID(ctx),
+	).Scan(&status, &version)
+
+	if err == sql.ErrNoRows {
+		return "", domain.ErrNotFound
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("lock pull_request: %w", err)
+	}
+
+	if err := domain.CheckVersion(ctx, version); err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+// insertReviewer назначает ревьюера на PR в рамках транзакции tx. Если он уже назначен
+// (например, конкурентным запросом, пока выбиралась замена), возвращает ErrAlreadyAssigned.
+func insertReviewer(ctx context.Context, tx *sql.Tx, prID, reviewerID string) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO pr_reviewers (pr_id, reviewer_id, org_id)
+		 VALUES ($1, $2, $3)`,
+		prID, reviewerID, orgID(ctx),
+	)
+
+	if isUniqueViolation(err) {
+		return domain.ErrAlreadyAssigned
+	}
+
+	if err != nil {
+		return fmt.Errorf("insert pr_reviewer: %w", err)
+	}
+
+	return nil
+}
+
+// bumpPRVersion увеличивает версию PR после изменения его ревьюеров.
+func bumpPRVersion(ctx context.Context, tx *sql.Tx, prID string) error {
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE pull_requests SET version = version + 1 WHERE id = $1 AND org_id = $2`,
+		prID, orgID(ctx),
+	); err != nil {
+		return fmt.Errorf("bump pull_request version: %w", err)
+	}
+
+	return nil
+}
+
+// insertHistory записывает событие в историю назначений в рамках транзакции tx. Если actorID пуст,
+// автором изменения считается вызывающий API из контекста; без него (фоновые задачи) — автоматическое изменение.
+func insertHistory(
+	ctx context.Context,
+	tx *sql.Tx,
+	prID, reviewerID, actorID string,
+	action domain.AssignmentAction,
+) error {
+	if caller, ok := domain.CallerFrom(ctx); ok && actorID == "" {
+		actorID = caller.UserID
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO review_assignment_history (pr_id, reviewer_id, author_id, action, actor_id, created_at, org_id)
+		 SELECT id, $2, author_id, $3, $4, $5, org_id
+		   FROM pull_requests
+		  WHERE id = $1 AND org_id = $6`,
+		prID, reviewerID, string(action), nullString(actorID), time.Now().UTC(), orgID(ctx),
+	); err != nil {
+		return fmt.Errorf("insert assignment history: %w", err)
+	}
+
+	return nil
+}
+
 type txKey struct{}
 
-// WithTx выполняет переданную функцию как транзакцию.
+// beginTx открывает транзакцию метода репозитория. Если ctx получен из WithTx, метод продолжает
+// внешнюю транзакцию: commit и rollback тогда ничего не делают, а фиксирует её WithTx.
+func beginTx(ctx context.Context, db *sql.DB) (tx *sql.Tx, commit func() error, rollback func(), err error) {
+	if outer, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return outer, func() error { return nil }, func() {}, nil
+	}
+
+	tx, err = db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("begin tx: %w", err)
+	}
+
+	return tx, tx.Commit, func() { _ = tx.Rollback() }, nil
+}
+
+// queryer — чтение, общее для пула соединений и транзакции.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn возвращает транзакцию WithTx из ctx, чтобы чтение видело её незафиксированные изменения,
+// или пул соединений db вне её.
+func conn(ctx context.Context, db *sql.DB) queryer {
+	if outer, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return outer
+	}
+
+	return db
+}
+
+// WithTx выполняет переданную функцию как транзакцию; методы репозиториев, принимающие
+// переданный в fn контекст (Create, ReassignReviewer, DeclineReview, GetByID,
+// TeamRepository.AdvanceRoundRobin), выполняются в ней же.
 func (r *PullRequestRepository) WithTx(
 	ctx context.Context,
 	fn func(ctx context.Context, tx *sql.Tx) error,
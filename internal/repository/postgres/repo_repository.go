@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// repoColumns — список колонок repositories в порядке, ожидаемом scanRepo.
+const repoColumns = `id, name, code_host, team_name, reviewer_team, selection_strategy, created_at, updated_at`
+
+func scanRepo(row rowScanner) (domain.Repo, error) {
+	var (
+		repo                   domain.Repo
+		reviewerTeam, strategy sql.NullString
+	)
+
+	if err := row.Scan(
+		&repo.ID, &repo.Name, &repo.CodeHost, &repo.TeamName, &reviewerTeam, &strategy,
+		&repo.CreatedAt, &repo.UpdatedAt,
+	); err != nil {
+		return domain.Repo{}, err
+	}
+
+	repo.ReviewerTeam = reviewerTeam.String
+	repo.Strategy = domain.SelectionStrategy(strategy.String)
+	return repo, nil
+}
+
+// RepoRepository реализует domain.RepoRepository для PostgreSQL.
+type RepoRepository struct {
+	db *sql.DB
+}
+
+// NewRepoRepository создаёт новый RepoRepository.
+func NewRepoRepository(db *sql.DB) *RepoRepository {
+	return &RepoRepository{db: db}
+}
+
+// Create сохраняет новый репозиторий.
+func (r *RepoRepository) Create(ctx context.Context, repo domain.Repo) (domain.Repo, error) {
+	now := time.Now().UTC()
+
+	created, err := scanRepo(r.db.QueryRowContext(ctx,
+		`INSERT INTO repositories (name, code_host, team_name, reviewer_team, selection_strategy,
+		                           created_at, updated_at, org_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $6, $7)
+		 RETURNING `+repoColumns,
+		repo.Name, string(repo.CodeHost), repo.TeamName, nullString(repo.ReviewerTeam),
+		nullString(string(repo.Strategy)), now, orgID(ctx),
+	))
+
+	if err != nil {
+		return domain.Repo{}, fmt.Errorf("insert repository: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByName возвращает репозиторий по имени.
+func (r *RepoRepository) GetByName(ctx context.Context, name string) (domain.Repo, error) {
+	repo, err := scanRepo(r.db.QueryRowContext(ctx,
+		`SELECT `+repoColumns+` FROM repositories WHERE name = $1 AND org_id = $2`,
+		name, orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.Repo{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.Repo{}, fmt.Errorf("select repository: %w", err)
+	}
+
+	return repo, nil
+}
+
+// List возвращает репозитории команды teamName (пусто — всех команд) в порядке имени.
+func (r *RepoRepository) List(ctx context.Context, teamName string) ([]domain.Repo, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+repoColumns+`
+		   FROM repositories
+		  WHERE org_id = $2
+		    AND ($1 = '' OR team_name = $1)
+		  ORDER BY name`,
+		teamName, orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("select repositories: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.Repo
+
+	for rows.Next() {
+		repo, err := scanRepo(rows)
+
+		if err != nil {
+			return nil, fmt.Errorf("scan repository: %w", err)
+		}
+
+		res = append(res, repo)
+	}
+
+	return res, rows.Err()
+}
+
+// UpdateOverrides сохраняет команду ревьюверов и стратегию репозитория.
+func (r *RepoRepository) UpdateOverrides(ctx context.Context, repo domain.Repo) (domain.Repo, error) {
+	updated, err := scanRepo(r.db.QueryRowContext(ctx,
+		`UPDATE repositories
+		    SET reviewer_team = $2,
+		        selection_strategy = $3,
+		        updated_at = $4
+		  WHERE name = $1 AND org_id = $5
+		 RETURNING `+repoColumns,
+		repo.Name, nullString(repo.ReviewerTeam), nullString(string(repo.Strategy)), time.Now().UTC(), orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.Repo{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.Repo{}, fmt.Errorf("update repository: %w", err)
+	}
+
+	return updated, nil
+}
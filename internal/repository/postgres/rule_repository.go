@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// ruleColumns — список колонок team_review_rules в порядке, ожидаемом scanRule.
+const ruleColumns = `id, team_name, kind, author_id, author_level, label, reviewer_id, reviewer_level,
+	description, created_at`
+
+func scanRule(row rowScanner) (domain.ReviewRule, error) {
+	var (
+		rule                       domain.ReviewRule
+		authorID, label            sql.NullString
+		reviewerID                 sql.NullString
+		authorLevel, reviewerLevel sql.NullString
+	)
+
+	if err := row.Scan(
+		&rule.ID, &rule.TeamName, &rule.Kind, &authorID, &authorLevel, &label, &reviewerID, &reviewerLevel,
+		&rule.Description, &rule.CreatedAt,
+	); err != nil {
+		return domain.ReviewRule{}, err
+	}
+
+	rule.AuthorID = authorID.String
+	rule.AuthorLevel = domain.Seniority(authorLevel.String)
+	rule.Label = label.String
+	rule.ReviewerID = reviewerID.String
+	rule.ReviewerLevel = domain.Seniority(reviewerLevel.String)
+	return rule, nil
+}
+
+// ReviewRuleRepository реализует domain.ReviewRuleRepository для PostgreSQL.
+type ReviewRuleRepository struct {
+	db *sql.DB
+}
+
+// NewReviewRuleRepository создаёт новый ReviewRuleRepository.
+func NewReviewRuleRepository(db *sql.DB) *ReviewRuleRepository {
+	return &ReviewRuleRepository{db: db}
+}
+
+// Create сохраняет новое правило команды.
+func (r *ReviewRuleRepository) Create(ctx context.Context, rule domain.ReviewRule) (domain.ReviewRule, error) {
+	created, err := scanRule(r.db.QueryRowContext(ctx,
+		`INSERT INTO team_review_rules (team_name, kind, author_id, author_level, label,
+		                                reviewer_id, reviewer_level, description, created_at, org_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		 RETURNING `+ruleColumns,
+		rule.TeamName, string(rule.Kind), nullString(rule.AuthorID), nullString(string(rule.AuthorLevel)),
+		nullString(rule.Label), nullString(rule.ReviewerID), nullString(string(rule.ReviewerLevel)),
+		rule.Description, time.Now().UTC(), orgID(ctx),
+	))
+
+	if err != nil {
+		return domain.ReviewRule{}, fmt.Errorf("insert review rule: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByID возвращает правило по идентификатору.
+func (r *ReviewRuleRepository) GetByID(ctx context.Context, id int64) (domain.ReviewRule, error) {
+	rule, err := scanRule(r.db.QueryRowContext(ctx,
+		`SELECT `+ruleColumns+` FROM team_review_rules WHERE id = $1 AND org_id = $2`,
+		id, orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.ReviewRule{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.ReviewRule{}, fmt.Errorf("select review rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// Delete удаляет правило и возвращает удалённую запись.
+func (r *ReviewRuleRepository) Delete(ctx context.Context, id int64) (domain.ReviewRule, error) {
+	deleted, err := scanRule(r.db.QueryRowContext(ctx,
+		`DELETE FROM team_review_rules WHERE id = $1 AND org_id = $2 RETURNING `+ruleColumns,
+		id, orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.ReviewRule{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.ReviewRule{}, fmt.Errorf("delete review rule: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// ListByTeam возвращает правила команды в порядке создания.
+func (r *ReviewRuleRepository) ListByTeam(ctx context.Context, teamName string) ([]domain.ReviewRule, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+ruleColumns+`
+		   FROM team_review_rules
+		  WHERE team_name = $1 AND org_id = $2
+		  ORDER BY id`,
+		teamName, orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("select review rules: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.ReviewRule
+
+	for rows.Next() {
+		rule, err := scanRule(rows)
+
+		if err != nil {
+			return nil, fmt.Errorf("scan review rule: %w", err)
+		}
+
+		res = append(res, rule)
+	}
+
+	return res, nil
+}
@@ -24,9 +24,9 @@ func (r *TeamRepository) CreateTeam(ctx context.Context, name string, members []
 	now := time.Now().UTC()
 
 	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO teams (team_name, created_at, updated_at)
-		 VALUES ($1, $2, $3)`,
-		name, now, now,
+		`INSERT INTO teams (team_name, created_at, updated_at, org_id)
+		 VALUES ($1, $2, $3, $4)`,
+		name, now, now, orgID(ctx),
 	)
 
 	if err != nil {
@@ -40,10 +40,15 @@ func (r *TeamRepository) CreateTeam(ctx context.Context, name string, members []
 func (r *TeamRepository) GetTeamWithMembers(ctx context.Context, teamName string) (domain.Team, error) {
 	var t domain.Team
 
+	var defaultMax sql.NullInt32
+
 	err := r.db.QueryRowContext(ctx,
-		`SELECT team_name FROM teams WHERE team_name = $1`,
-		teamName,
-	).Scan(&t.Name)
+		`SELECT team_name, default_max_open_reviews, capacity_policy, prefer_working_hours, pairing_window_days,
+		        selection_strategy, max_reviewers, version
+		   FROM teams WHERE team_name = $1 AND org_id = $2`,
+		teamName, orgID(ctx),
+	).Scan(&t.Name, &defaultMax, &t.Settings.CapacityPolicy, &t.Settings.PreferWorkingHours,
+		&t.Settings.PairingWindowDays, &t.Settings.Strategy, &t.Settings.MaxReviewers, &t.Settings.Version)
 
 	if err == sql.ErrNoRows {
 		return domain.Team{}, domain.ErrNotFound
@@ -53,11 +58,13 @@ func (r *TeamRepository) GetTeamWithMembers(ctx context.Context, teamName string
 		return domain.Team{}, fmt.Errorf("select team: %w", err)
 	}
 
+	t.Settings.DefaultMaxOpenReviews = nullIntPtr(defaultMax)
+
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT user_id, username, team_name, is_active, created_at, updated_at
+		`SELECT `+userColumns+`
 		   FROM users
-		  WHERE team_name = $1`,
-		teamName,
+		  WHERE team_name = $1 AND org_id = $2`,
+		teamName, orgID(ctx),
 	)
 
 	if err != nil {
@@ -71,9 +78,9 @@ func (r *TeamRepository) GetTeamWithMembers(ctx context.Context, teamName string
 	var members []domain.User
 
 	for rows.Next() {
-		var u domain.User
+		u, err := scanUser(rows)
 
-		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if err != nil {
 			return domain.Team{}, fmt.Errorf("scan team member: %w", err)
 		}
 
@@ -89,8 +96,8 @@ func (r *TeamRepository) TeamExists(ctx context.Context, name string) (bool, err
 	var exists bool
 
 	err := r.db.QueryRowContext(ctx,
-		`SELECT TRUE FROM teams WHERE team_name = $1`,
-		name,
+		`SELECT TRUE FROM teams WHERE team_name = $1 AND org_id = $2`,
+		name, orgID(ctx),
 	).Scan(&exists)
 
 	if err == sql.ErrNoRows {
@@ -103,3 +110,168 @@ func (r *TeamRepository) TeamExists(ctx context.Context, name string) (bool, err
 
 	return true, nil
 }
+
+// GetSettings возвращает настройки назначения ревьюверов команды.
+func (r *TeamRepository) GetSettings(ctx context.Context, teamName string) (domain.TeamSettings, error) {
+	var (
+		s          domain.TeamSettings
+		defaultMax sql.NullInt32
+		leadID     sql.NullString
+		webhookURL sql.NullString
+		channel    sql.NullString
+	)
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT default_max_open_reviews, capacity_policy, prefer_working_hours, pairing_window_days,
+		        selection_strategy, max_reviewers, review_sla_hours, stale_action, lead_id,
+		        chat_webhook_url, chat_channel, version
+		   FROM teams WHERE team_name = $1 AND org_id = $2`,
+		teamName, orgID(ctx),
+	).Scan(&defaultMax, &s.CapacityPolicy, &s.PreferWorkingHours, &s.PairingWindowDays, &s.Strategy,
+		&s.MaxReviewers, &s.ReviewSLAHours, &s.StaleAction, &leadID, &webhookURL, &channel, &s.Version)
+
+	if err == sql.ErrNoRows {
+		return domain.TeamSettings{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("select team settings: %w", err)
+	}
+
+	s.DefaultMaxOpenReviews = nullIntPtr(defaultMax)
+	s.LeadID = leadID.String
+	s.ChatWebhookURL = webhookURL.String
+	s.ChatChannel = channel.String
+	return s, nil
+}
+
+// AdvanceRoundRobin блокирует строку команды (SELECT ... FOR UPDATE) на время вызова advance,
+// поэтому параллельные создания PR видят курсор, уже сдвинутый предыдущим вызовом. Внутри
+// PullRequestRepository.WithTx блокировка и сдвиг остаются в транзакции до её фиксации.
+func (r *TeamRepository) AdvanceRoundRobin(
+	ctx context.Context,
+	teamName string,
+	advance func(cursor string) (string, error),
+) error {
+	tx, commit, rollback, err := beginTx(ctx, r.db)
+
+	if err != nil {
+		return err
+	}
+
+	defer rollback()
+
+	var cursor sql.NullString
+
+	err = tx.QueryRowContext(ctx,
+		`SELECT rr_cursor FROM teams WHERE team_name = $1 AND org_id = $2 FOR UPDATE`,
+		teamName, orgID(ctx),
+	).Scan(&cursor)
+
+	if err == sql.ErrNoRows {
+		return domain.ErrNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("lock team cursor: %w", err)
+	}
+
+	next, err := advance(cursor.String)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE teams SET rr_cursor = $2 WHERE team_name = $1 AND org_id = $3`,
+		teamName, nullString(next), orgID(ctx),
+	); err != nil {
+		return fmt.Errorf("update team cursor: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// GetRoundRobinCursor возвращает текущий курсор ротации команды без блокировки.
+func (r *TeamRepository) GetRoundRobinCursor(ctx context.Context, teamName string) (string, error) {
+	var cursor sql.NullString
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT rr_cursor FROM teams WHERE team_name = $1 AND org_id = $2`,
+		teamName, orgID(ctx),
+	).Scan(&cursor)
+
+	if err == sql.ErrNoRows {
+		return "", domain.ErrNotFound
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("select team cursor: %w", err)
+	}
+
+	return cursor.String, nil
+}
+
+// UpdateSettings сохраняет настройки команды и возвращает их актуальное состояние.
+// При смене вебхука чата курсор уведомлений переносится в конец истории назначений,
+// чтобы в новый чат не отправлялись старые изменения. Настройки сохраняются, только если их версия
+// всё ещё равна settings.Version, иначе возвращается ErrVersionMismatch.
+func (r *TeamRepository) UpdateSettings(ctx context.Context, teamName string, settings domain.TeamSettings) (domain.TeamSettings, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE teams
+		    SET default_max_open_reviews = $2,
+		        capacity_policy = $3,
+		        prefer_working_hours = $4,
+		        pairing_window_days = $5,
+		        selection_strategy = $6,
+		        max_reviewers = $7,
+		        review_sla_hours = $8,
+		        stale_action = $9,
+		        lead_id = $10,
+		        chat_cursor = CASE
+		            WHEN chat_webhook_url IS DISTINCT FROM $11
+		            THEN (SELECT COALESCE(MAX(id), 0) FROM review_assignment_history WHERE org_id = $14)
+		            ELSE chat_cursor
+		        END,
+		        chat_webhook_url = $11,
+		        chat_channel = $12,
+		        updated_at = $13,
+		        version = version + 1
+		  WHERE team_name = $1 AND org_id = $14 AND version = $15`,
+		teamName, settings.DefaultMaxOpenReviews, string(settings.CapacityPolicy),
+		settings.PreferWorkingHours, settings.PairingWindowDays, string(settings.Strategy),
+		settings.MaxReviewers, settings.ReviewSLAHours, string(settings.StaleAction),
+		nullString(settings.LeadID), nullString(settings.ChatWebhookURL), nullString(settings.ChatChannel),
+		time.Now().UTC(), orgID(ctx), settings.Version,
+	)
+
+	if err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("update team settings: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("rows affected: %w", err)
+	}
+
+	if affected == 0 {
+		exists, err := r.TeamExists(ctx, teamName)
+
+		if err != nil {
+			return domain.TeamSettings{}, err
+		}
+
+		if exists {
+			return domain.TeamSettings{}, domain.ErrVersionMismatch
+		}
+
+		return domain.TeamSettings{}, domain.ErrNotFound
+	}
+
+	return r.GetSettings(ctx, teamName)
+}
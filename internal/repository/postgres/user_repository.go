@@ -9,6 +9,68 @@ import (
 	"pr-reviewer-service/internal/domain"
 )
 
+// userColumns — список колонок users в порядке, ожидаемом scanUser.
+const userColumns = `user_id, username, team_name, is_active, max_open_reviews,
+	timezone, work_start_min, work_end_min, work_days, seniority, selection_weight,
+	email, digest_enabled, digest_last_sent_at, chat_handle, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row rowScanner) (domain.User, error) {
+	var u domain.User
+
+	var (
+		maxOpen            sql.NullInt32
+		workStart, workEnd sql.NullInt32
+		workDays           int16
+		seniority          sql.NullString
+		weight             sql.NullInt32
+		email              sql.NullString
+		digestSentAt       sql.NullTime
+		chatHandle         sql.NullString
+	)
+
+	if err := row.Scan(
+		&u.ID, &u.Username, &u.TeamName, &u.IsActive, &maxOpen,
+		&u.Timezone, &workStart, &workEnd, &workDays, &seniority, &weight,
+		&email, &u.DigestEnabled, &digestSentAt, &chatHandle, &u.CreatedAt, &u.UpdatedAt,
+	); err != nil {
+		return domain.User{}, err
+	}
+
+	u.Email = email.String
+	u.ChatHandle = chatHandle.String
+
+	if digestSentAt.Valid {
+		u.DigestLastSentAt = &digestSentAt.Time
+	}
+
+	u.MaxOpenReviews = nullIntPtr(maxOpen)
+	u.Seniority = domain.Seniority(seniority.String)
+	u.Weight = nullIntPtr(weight)
+
+	if workStart.Valid && workEnd.Valid {
+		u.WorkHours = &domain.WorkHours{
+			StartMinute: int(workStart.Int32),
+			EndMinute:   int(workEnd.Int32),
+			Days:        domain.WeekdaySet(workDays),
+		}
+	}
+
+	return u, nil
+}
+
+func nullIntPtr(v sql.NullInt32) *int {
+	if !v.Valid {
+		return nil
+	}
+
+	i := int(v.Int32)
+	return &i
+}
+
 // UserRepository реализует domain.UserRepository для PostgreSQL.
 type UserRepository struct {
 	db *sql.DB
@@ -21,13 +83,11 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 // GetByID возвращает пользователя по его идентификатору.
 func (r *UserRepository) GetByID(ctx context.Context, id string) (domain.User, error) {
-	var u domain.User
-
-	err := r.db.QueryRowContext(ctx,
-		`SELECT user_id, username, team_name, is_active, created_at, updated_at
-		   FROM users WHERE user_id = $1`,
-		id,
-	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.CreatedAt, &u.UpdatedAt)
+	u, err := scanUser(r.db.QueryRowContext(ctx,
+		`SELECT `+userColumns+`
+		   FROM users WHERE user_id = $1 AND org_id = $2`,
+		id, orgID(ctx),
+	))
 
 	if err == sql.ErrNoRows {
 		return domain.User{}, domain.ErrNotFound
@@ -40,20 +100,26 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (domain.User, e
 	return u, nil
 }
 
-// UpsertUsers создаёт или обновляет пользователей в составе команды.
+// UpsertUsers создаёт или обновляет пользователей в составе команды. Не указанные у участника
+// лимит ревью, уровень и вес сохраняют прежние значения (сбросить их можно отдельными запросами).
 func (r *UserRepository) UpsertUsers(ctx context.Context, teamName string, users []domain.User) error {
 	now := time.Now().UTC()
 
 	for _, u := range users {
 		if _, err := r.db.ExecContext(ctx,
-			`INSERT INTO users (user_id, username, team_name, is_active, created_at, updated_at)
-			 VALUES ($1, $2, $3, $4, $5, $6)
-			 ON CONFLICT (user_id) DO UPDATE
+			`INSERT INTO users (user_id, username, team_name, is_active, max_open_reviews,
+			                    seniority, selection_weight, created_at, updated_at, org_id)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			 ON CONFLICT (org_id, user_id) DO UPDATE
 			 SET username = EXCLUDED.username,
 			     team_name = EXCLUDED.team_name,
 			     is_active = EXCLUDED.is_active,
+			     max_open_reviews = COALESCE(EXCLUDED.max_open_reviews, users.max_open_reviews),
+			     seniority = COALESCE(EXCLUDED.seniority, users.seniority),
+			     selection_weight = COALESCE(EXCLUDED.selection_weight, users.selection_weight),
 			     updated_at = EXCLUDED.updated_at`,
-			u.ID, u.Username, teamName, u.IsActive, now, now,
+			u.ID, u.Username, teamName, u.IsActive, u.MaxOpenReviews,
+			nullString(string(u.Seniority)), u.Weight, now, now, orgID(ctx),
 		); err != nil {
 			return fmt.Errorf("upsert user %s: %w", u.ID, err)
 		}
@@ -64,16 +130,14 @@ func (r *UserRepository) UpsertUsers(ctx context.Context, teamName string, users
 
 // SetIsActive изменяет флаг активности пользователя и возвращает обновлённые данные.
 func (r *UserRepository) SetIsActive(ctx context.Context, id string, isActive bool) (domain.User, error) {
-	var u domain.User
-
-	err := r.db.QueryRowContext(ctx,
+	u, err := scanUser(r.db.QueryRowContext(ctx,
 		`UPDATE users
 		    SET is_active = $2,
 		        updated_at = $3
-		  WHERE user_id = $1
-	      RETURNING user_id, username, team_name, is_active, created_at, updated_at`,
-		id, isActive, time.Now().UTC(),
-	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.CreatedAt, &u.UpdatedAt)
+		  WHERE user_id = $1 AND org_id = $4
+	      RETURNING `+userColumns,
+		id, isActive, time.Now().UTC(), orgID(ctx),
+	))
 
 	if err == sql.ErrNoRows {
 		return domain.User{}, domain.ErrNotFound
@@ -86,19 +150,183 @@ func (r *UserRepository) SetIsActive(ctx context.Context, id string, isActive bo
 	return u, nil
 }
 
-// GetActiveTeamMembersExcept возвращает активных участников команды кроме указанного пользователя.
-func (r *UserRepository) GetActiveTeamMembersExcept(ctx context.Context, teamName, excludeUserID string) ([]domain.User, error) {
+// SetMaxOpenReviews задаёт личный лимит открытых ревью пользователя (nil — сброс к значению команды).
+func (r *UserRepository) SetMaxOpenReviews(ctx context.Context, id string, maxOpenReviews *int) (domain.User, error) {
+	u, err := scanUser(r.db.QueryRowContext(ctx,
+		`UPDATE users
+		    SET max_open_reviews = $2,
+		        updated_at = $3
+		  WHERE user_id = $1 AND org_id = $4
+	      RETURNING `+userColumns,
+		id, maxOpenReviews, time.Now().UTC(), orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.User{}, fmt.Errorf("update user max_open_reviews: %w", err)
+	}
+
+	return u, nil
+}
+
+// SetWorkSchedule задаёт часовой пояс и рабочие часы пользователя (hours == nil — график не задан).
+func (r *UserRepository) SetWorkSchedule(ctx context.Context, id, timezone string, hours *domain.WorkHours) (domain.User, error) {
+	var (
+		workStart, workEnd sql.NullInt32
+		workDays           = int16(domain.DefaultWorkDays)
+	)
+
+	if hours != nil {
+		workStart = sql.NullInt32{Int32: int32(hours.StartMinute), Valid: true}
+		workEnd = sql.NullInt32{Int32: int32(hours.EndMinute), Valid: true}
+		workDays = int16(hours.Days)
+	}
+
+	u, err := scanUser(r.db.QueryRowContext(ctx,
+		`UPDATE users
+		    SET timezone = $2,
+		        work_start_min = $3,
+		        work_end_min = $4,
+		        work_days = $5,
+		        updated_at = $6
+		  WHERE user_id = $1 AND org_id = $7
+	      RETURNING `+userColumns,
+		id, timezone, workStart, workEnd, workDays, time.Now().UTC(), orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.User{}, fmt.Errorf("update user work schedule: %w", err)
+	}
+
+	return u, nil
+}
+
+// SetSeniority задаёт уровень пользователя и его явный вес при взвешенном выборе (nil — вес по уровню).
+func (r *UserRepository) SetSeniority(ctx context.Context, id string, seniority domain.Seniority, weight *int) (domain.User, error) {
+	u, err := scanUser(r.db.QueryRowContext(ctx,
+		`UPDATE users
+		    SET seniority = $2,
+		        selection_weight = $3,
+		        updated_at = $4
+		  WHERE user_id = $1 AND org_id = $5
+	      RETURNING `+userColumns,
+		id, nullString(string(seniority)), weight, time.Now().UTC(), orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.User{}, fmt.Errorf("update user seniority: %w", err)
+	}
+
+	return u, nil
+}
+
+// GetAvailableTeamMembersExcept возвращает активных участников команды, не отсутствующих в момент at,
+// кроме указанного пользователя.
+func (r *UserRepository) GetAvailableTeamMembersExcept(
+	ctx context.Context,
+	teamName, excludeUserID string,
+	at time.Time,
+) ([]domain.User, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT user_id, username, team_name, is_active, created_at, updated_at
+		`SELECT `+userColumns+`
+		   FROM users u
+		  WHERE u.team_name = $1
+		    AND u.org_id = $4
+		    AND u.is_active = TRUE
+		    AND u.user_id <> $2
+		    AND NOT EXISTS (
+		        SELECT 1
+		          FROM user_absences a
+		         WHERE a.org_id = u.org_id
+		           AND a.user_id = u.user_id
+		           AND a.starts_at <= $3
+		           AND a.ends_at > $3
+		    )`,
+		teamName, excludeUserID, at, orgID(ctx),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("select active team members: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.User
+
+	for rows.Next() {
+		u, err := scanUser(rows)
+
+		if err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+
+		res = append(res, u)
+	}
+
+	return res, nil
+}
+
+// SetNotifications задаёт адрес и имя в чате для уведомлений и подписку на ежедневную сводку.
+// Имя в чате, занятое другим пользователем организации, отклоняется с ErrChatHandleTaken.
+func (r *UserRepository) SetNotifications(
+	ctx context.Context,
+	id, email, chatHandle string,
+	digestEnabled bool,
+) (domain.User, error) {
+	u, err := scanUser(r.db.QueryRowContext(ctx,
+		`UPDATE users
+		    SET email = $2,
+		        chat_handle = $3,
+		        digest_enabled = $4,
+		        updated_at = $5
+		  WHERE user_id = $1 AND org_id = $6
+	      RETURNING `+userColumns,
+		id, nullString(email), nullString(chatHandle), digestEnabled, time.Now().UTC(), orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	if isUniqueViolation(err) {
+		return domain.User{}, domain.ErrChatHandleTaken
+	}
+
+	if err != nil {
+		return domain.User{}, fmt.Errorf("update user notifications: %w", err)
+	}
+
+	return u, nil
+}
+
+// ListDigestRecipients возвращает активных пользователей с адресом и включённой сводкой.
+func (r *UserRepository) ListDigestRecipients(ctx context.Context) ([]domain.User, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+userColumns+`
 		   FROM users
-		  WHERE team_name = $1
+		  WHERE org_id = $1
 		    AND is_active = TRUE
-		    AND user_id <> $2`,
-		teamName, excludeUserID,
+		    AND digest_enabled = TRUE
+		    AND email IS NOT NULL
+		  ORDER BY user_id`,
+		orgID(ctx),
 	)
 
 	if err != nil {
-		return nil, fmt.Errorf("select active team members: %w", err)
+		return nil, fmt.Errorf("select digest recipients: %w", err)
 	}
 
 	defer func() {
@@ -108,9 +336,9 @@ func (r *UserRepository) GetActiveTeamMembersExcept(ctx context.Context, teamNam
 	var res []domain.User
 
 	for rows.Next() {
-		var u domain.User
+		u, err := scanUser(rows)
 
-		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if err != nil {
 			return nil, fmt.Errorf("scan user: %w", err)
 		}
 
@@ -120,13 +348,50 @@ func (r *UserRepository) GetActiveTeamMembersExcept(ctx context.Context, teamNam
 	return res, nil
 }
 
+// MarkDigestSent запоминает время отправки сводки пользователю.
+func (r *UserRepository) MarkDigestSent(ctx context.Context, id string, at time.Time) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE users SET digest_last_sent_at = $2 WHERE user_id = $1 AND org_id = $3`,
+		id, at, orgID(ctx),
+	); err != nil {
+		return fmt.Errorf("update digest_last_sent_at: %w", err)
+	}
+
+	return nil
+}
+
+// GetByChatHandle возвращает пользователя по имени в чате, предпочитая более ранние варианты из handles.
+// Имя в чате уникально в организации (idx_users_org_chat_handle), поэтому каждому варианту
+// соответствует не больше одного пользователя.
+func (r *UserRepository) GetByChatHandle(ctx context.Context, handles []string) (domain.User, error) {
+	u, err := scanUser(r.db.QueryRowContext(ctx,
+		`SELECT `+userColumns+`
+		   FROM users
+		  WHERE chat_handle = ANY($1)
+		    AND org_id = $2
+		  ORDER BY array_position($1, chat_handle), user_id
+		  LIMIT 1`,
+		handles, orgID(ctx),
+	))
+
+	if err == sql.ErrNoRows {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	if err != nil {
+		return domain.User{}, fmt.Errorf("select user by chat handle: %w", err)
+	}
+
+	return u, nil
+}
+
 // GetTeamByUserID возвращает имя команды по идентификатору пользователя.
 func (r *UserRepository) GetTeamByUserID(ctx context.Context, userID string) (string, error) {
 	var teamName string
 
 	err := r.db.QueryRowContext(ctx,
-		`SELECT team_name FROM users WHERE user_id = $1`,
-		userID,
+		`SELECT team_name FROM users WHERE user_id = $1 AND org_id = $2`,
+		userID, orgID(ctx),
 	).Scan(&teamName)
 
 	if err == sql.ErrNoRows {
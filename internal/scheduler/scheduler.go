@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/logging"
+)
+
+// Job — периодическая фоновая задача.
+type Job struct {
+	// Name — имя задачи; по нему же берётся блокировка, поэтому между экземплярами
+	// сервиса одну задачу в каждый момент выполняет только один.
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler запускает задачи по расписанию до отмены контекста.
+type Scheduler struct {
+	locker domain.JobLocker
+	logger *logging.Logger
+	jobs   []Job
+	wg     sync.WaitGroup
+}
+
+// New создаёт планировщик, который выполняет задачи под блокировками locker.
+func New(locker domain.JobLocker, logger *logging.Logger) *Scheduler {
+	return &Scheduler{locker: locker, logger: logger}
+}
+
+// Add регистрирует задачу. Задачи с неположительным интервалом не запускаются.
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		s.logger.Info("scheduler job disabled", "job", job.Name)
+		return
+	}
+
+	s.jobs = append(s.jobs, job)
+}
+
+// Start запускает зарегистрированные задачи; они работают до отмены ctx.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Wait дожидается завершения всех задач после отмены контекста.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			s.runOnce(ctx, job)
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	started := time.Now()
+	ran, err := s.locker.TryRun(ctx, job.Name, job.Run)
+
+	switch {
+	case err != nil:
+		s.logger.Error("scheduler job failed", "job", job.Name, "err", err)
+
+	case !ran:
+		s.logger.Debug("scheduler job skipped: locked by another instance", "job", job.Name)
+
+	default:
+		s.logger.Debug("scheduler job finished", "job", job.Name, "duration", time.Since(started))
+	}
+}
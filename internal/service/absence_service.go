@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/ical"
+)
+
+// AbsenceService содержит бизнес-логику плановых отсутствий пользователей.
+type AbsenceService struct {
+	absenceRepo domain.AbsenceRepository
+	userRepo    domain.UserRepository
+}
+
+// NewAbsenceService создаёт новый AbsenceService.
+func NewAbsenceService(absenceRepo domain.AbsenceRepository, userRepo domain.UserRepository) *AbsenceService {
+	return &AbsenceService{
+		absenceRepo: absenceRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// AddAbsence добавляет отсутствие пользователя в интервале [startsAt, endsAt).
+func (s *AbsenceService) AddAbsence(
+	ctx context.Context,
+	userID string,
+	startsAt, endsAt time.Time,
+	reason string,
+) (domain.Absence, error) {
+	if err := validateAbsencePeriod(startsAt, endsAt); err != nil {
+		return domain.Absence{}, err
+	}
+
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return domain.Absence{}, err
+	}
+
+	if err := authorizeUser(ctx, s.userRepo, userID); err != nil {
+		return domain.Absence{}, err
+	}
+
+	return s.absenceRepo.Create(ctx, domain.Absence{
+		UserID:   userID,
+		StartsAt: startsAt.UTC(),
+		EndsAt:   endsAt.UTC(),
+		Reason:   reason,
+		Source:   domain.AbsenceSourceManual,
+	})
+}
+
+// UpdateAbsence изменяет интервал и причину существующего отсутствия.
+func (s *AbsenceService) UpdateAbsence(
+	ctx context.Context,
+	id int64,
+	startsAt, endsAt time.Time,
+	reason string,
+) (domain.Absence, error) {
+	if err := validateAbsencePeriod(startsAt, endsAt); err != nil {
+		return domain.Absence{}, err
+	}
+
+	if err := s.authorizeAbsence(ctx, id); err != nil {
+		return domain.Absence{}, err
+	}
+
+	updated, err := s.absenceRepo.Update(ctx, domain.Absence{
+		ID:       id,
+		StartsAt: startsAt.UTC(),
+		EndsAt:   endsAt.UTC(),
+		Reason:   reason,
+	})
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.Absence{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return domain.Absence{}, err
+	}
+
+	return updated, nil
+}
+
+// DeleteAbsence удаляет отсутствие и возвращает удалённую запись.
+func (s *AbsenceService) DeleteAbsence(ctx context.Context, id int64) (domain.Absence, error) {
+	if err := s.authorizeAbsence(ctx, id); err != nil {
+		return domain.Absence{}, err
+	}
+
+	deleted, err := s.absenceRepo.Delete(ctx, id)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.Absence{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return domain.Absence{}, err
+	}
+
+	return deleted, nil
+}
+
+// ListAbsences возвращает все отсутствия пользователя.
+func (s *AbsenceService) ListAbsences(ctx context.Context, userID string) ([]domain.Absence, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.absenceRepo.ListByUser(ctx, userID)
+}
+
+// ImportICS импортирует события из iCalendar-файла как отсутствия пользователя.
+// Повторный импорт того же календаря обновляет ранее созданные записи по UID события.
+// Дни отсутствия без времени отсчитываются от полуночи в часовом поясе пользователя.
+func (s *AbsenceService) ImportICS(ctx context.Context, userID string, r io.Reader) ([]domain.Absence, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return nil, err
+	}
+
+	if err := authorizeUser(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+
+	events, err := ical.Parse(r, user.Location())
+
+	if err != nil {
+		if errors.Is(err, ical.ErrInvalidCalendar) {
+			return nil, domain.NewDomainError(domain.ErrorCodeValidation, fmt.Errorf("%w: %w", domain.ErrInvalidInput, err))
+		}
+
+		return nil, err
+	}
+
+	absences := make([]domain.Absence, 0, len(events))
+
+	for _, e := range events {
+		absences = append(absences, domain.Absence{
+			UserID:      userID,
+			StartsAt:    e.Start,
+			EndsAt:      e.End,
+			Reason:      e.Summary,
+			Source:      domain.AbsenceSourceICS,
+			ExternalUID: e.UID,
+		})
+	}
+
+	if len(absences) == 0 {
+		return nil, nil
+	}
+
+	return s.absenceRepo.UpsertImported(ctx, absences)
+}
+
+// authorizeAbsence проверяет право вызывающего изменять отсутствие id.
+func (s *AbsenceService) authorizeAbsence(ctx context.Context, id int64) error {
+	if _, scoped, err := callerTeam(ctx, s.userRepo); err != nil || !scoped {
+		return err
+	}
+
+	a, err := s.absenceRepo.GetByID(ctx, id)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return err
+	}
+
+	return authorizeUser(ctx, s.userRepo, a.UserID)
+}
+
+func (s *AbsenceService) ensureUser(ctx context.Context, userID string) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		if err == domain.ErrNotFound {
+			return domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func validateAbsencePeriod(startsAt, endsAt time.Time) error {
+	if startsAt.IsZero() || endsAt.IsZero() || !endsAt.After(startsAt) {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("ends_at must be after starts_at: %w", domain.ErrInvalidInput))
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+const (
+	// APIKeyPrefix — начало всех ключей API; по нему ключ отличается от других bearer-токенов.
+	APIKeyPrefix = "prs_"
+	// apiKeyPrefixLen — сколько первых символов ключа хранится открыто для отображения.
+	apiKeyPrefixLen = len(APIKeyPrefix) + 6
+	// apiKeyTouchInterval — как часто обновляется время последнего использования ключа.
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKeyService выпускает, отзывает и проверяет ключи API. Ключ показывается только при выпуске,
+// в базе хранится его SHA-256: ключи случайные и длинные, поэтому медленный хеш не нужен.
+// bootstrapKey (если задан) — ключ администратора из конфигурации, не хранящийся в базе,
+// чтобы выпустить первые ключи; он принадлежит оператору платформы.
+type APIKeyService struct {
+	keyRepo      domain.APIKeyRepository
+	userRepo     domain.UserRepository
+	orgRepo      domain.OrganizationRepository
+	bootstrapKey string
+}
+
+// NewAPIKeyService создаёт новый APIKeyService.
+func NewAPIKeyService(
+	keyRepo domain.APIKeyRepository,
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	bootstrapKey string,
+) *APIKeyService {
+	return &APIKeyService{
+		keyRepo:      keyRepo,
+		userRepo:     userRepo,
+		orgRepo:      orgRepo,
+		bootstrapKey: bootstrapKey,
+	}
+}
+
+// CreateKey выпускает ключ с ролью role, действующий от имени userID (пусто — служебный ключ),
+// и возвращает его вместе с открытым значением ключа. Ключ выпускается в организации вызывающего;
+// оператор платформы может указать другую организацию её коротким именем orgSlug.
+func (s *APIKeyService) CreateKey(
+	ctx context.Context,
+	name string,
+	role domain.Role,
+	userID, orgSlug string,
+) (domain.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+
+	if orgSlug != "" {
+		orgCtx, err := s.organizationContext(ctx, orgSlug)
+
+		if err != nil {
+			return domain.APIKey{}, "", err
+		}
+
+		ctx = orgCtx
+	}
+
+	if name == "" {
+		return domain.APIKey{}, "", domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("name is required: %w", domain.ErrInvalidInput))
+	}
+
+	if !role.Valid() {
+		return domain.APIKey{}, "", domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("unknown role %q: %w", role, domain.ErrInvalidInput))
+	}
+
+	// тимлид управляет командой, в которой состоит, поэтому его ключ привязан к пользователю
+	if role == domain.RoleTeamLead && userID == "" {
+		return domain.APIKey{}, "", domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("user_id is required for role %s: %w", role, domain.ErrInvalidInput))
+	}
+
+	if userID != "" {
+		if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+			if err == domain.ErrNotFound {
+				return domain.APIKey{}, "", domain.NewDomainError(domain.ErrorCodeNotFound, err)
+			}
+
+			return domain.APIKey{}, "", err
+		}
+	}
+
+	raw := make([]byte, 32)
+
+	if _, err := rand.Read(raw); err != nil {
+		return domain.APIKey{}, "", fmt.Errorf("generate api key: %w", err)
+	}
+
+	secret := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	key, err := s.keyRepo.Create(ctx, domain.APIKey{
+		Name:   name,
+		Role:   role,
+		UserID: userID,
+		Prefix: secret[:apiKeyPrefixLen],
+	}, hashAPIKey(secret))
+
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	return key, secret, nil
+}
+
+// organizationContext возвращает контекст организации orgSlug; выбирать организацию
+// может только оператор платформы.
+func (s *APIKeyService) organizationContext(ctx context.Context, orgSlug string) (context.Context, error) {
+	if caller, ok := domain.CallerFrom(ctx); !ok || !caller.Platform {
+		return nil, domain.NewDomainError(domain.ErrorCodeForbidden,
+			fmt.Errorf("only the platform operator may issue keys for another organization: %w", domain.ErrForbidden))
+	}
+
+	org, err := s.orgRepo.GetBySlug(ctx, orgSlug)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.NewDomainError(domain.ErrorCodeNotFound,
+				fmt.Errorf("organization %s: %w", orgSlug, err))
+		}
+
+		return nil, err
+	}
+
+	return domain.WithOrganization(ctx, org.ID), nil
+}
+
+// ListKeys возвращает все ключи организации без их значений.
+func (s *APIKeyService) ListKeys(ctx context.Context) ([]domain.APIKey, error) {
+	return s.keyRepo.List(ctx)
+}
+
+// RevokeKey отзывает ключ; отозванный ключ сразу перестаёт приниматься.
+func (s *APIKeyService) RevokeKey(ctx context.Context, id int64) (domain.APIKey, error) {
+	key, err := s.keyRepo.Revoke(ctx, id, time.Now().UTC())
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.APIKey{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return domain.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// Authenticate возвращает вызывающего по открытому значению ключа.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (domain.Caller, error) {
+	if s.bootstrapKey != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(s.bootstrapKey)) == 1 {
+		return domain.Caller{
+			Name:     "bootstrap",
+			OrgID:    domain.DefaultOrganizationID,
+			Role:     domain.RoleAdmin,
+			Platform: true,
+		}, nil
+	}
+
+	key, err := s.keyRepo.GetByHash(ctx, hashAPIKey(secret))
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.Caller{}, domain.NewDomainError(domain.ErrorCodeUnauthorized,
+				fmt.Errorf("invalid api key: %w", domain.ErrUnauthorized))
+		}
+
+		return domain.Caller{}, err
+	}
+
+	if err := s.keyRepo.TouchLastUsed(ctx, key.ID, time.Now().UTC(), apiKeyTouchInterval); err != nil {
+		return domain.Caller{}, err
+	}
+
+	return domain.Caller{KeyID: key.ID, Name: key.Name, OrgID: key.OrgID, UserID: key.UserID, Role: key.Role}, nil
+}
+
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// ExplainAssignment объясняет назначение ревьюеров существующего PR на текущий момент:
+// кто из участников команды мог бы быть выбран сейчас, кто исключён и почему, и как
+// стратегия команды оценивает допустимых кандидатов. Причины назначения уже выбранных
+// ревьюеров восстанавливаются по действующим правилам команды, поэтому объяснение помечается
+// временем переоценки и может расходиться с тем, как ревьюеры были выбраны на самом деле.
+func (s *PullRequestService) ExplainAssignment(
+	ctx context.Context,
+	prID string,
+) (domain.PullRequest, domain.AssignmentExplanation, error) {
+	pr, err := s.prRepo.GetByID(ctx, prID)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.PullRequest{}, domain.AssignmentExplanation{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+	}
+
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.PullRequest{}, domain.AssignmentExplanation{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+	}
+
+	scope, err := s.reviewScopeFor(ctx, pr.Repository, author.TeamName)
+
+	if err != nil {
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+	}
+
+	now := time.Now().UTC()
+	available, err := s.userRepo.GetAvailableTeamMembersExcept(ctx, scope.team, author.ID, now)
+
+	if err != nil {
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+	}
+
+	rules, err := s.ruleRepo.ListByTeam(ctx, scope.team)
+
+	if err != nil {
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+	}
+
+	pool, err := s.loadSelectionPool(ctx, scope, author.ID, available, now)
+
+	if err != nil {
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+	}
+
+	if pool.settings.Strategy == domain.SelectionStrategyRoundRobin {
+		if pool.cursor, err = s.teamRepo.GetRoundRobinCursor(ctx, scope.team); err != nil {
+			return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+		}
+	}
+
+	team, err := s.teamRepo.GetTeamWithMembers(ctx, scope.team)
+
+	if err != nil {
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+	}
+
+	declined, err := s.prRepo.ListDecliners(ctx, pr.ID)
+
+	if err != nil {
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+	}
+
+	membersByID := make(map[string]domain.User, len(team.Members))
+
+	for _, m := range team.Members {
+		membersByID[m.ID] = m
+	}
+
+	explanation := domain.AssignmentExplanation{
+		Strategy:      pool.settings.Strategy,
+		ReevaluatedAt: &now,
+		Candidates: explainCandidates(candidateInput{
+			members: team.Members,
+			policy: newEligibilityPolicy(author, scope.team, pr.AssignedReviewers).
+				withAvailability(available).
+				withDeclined(declined).
+				withRules(rules, pr.Labels),
+			pool: pool,
+		}),
+	}
+
+	for _, id := range pr.AssignedReviewers {
+		reviewer := domain.ReviewerAssignment{UserID: id, Reason: domain.AssignmentReasonSelected}
+
+		for _, rule := range rules {
+			if rule.Kind == domain.RuleKindRequire && rule.Matches(author, pr.Labels) && rule.Targets(membersByID[id]) {
+				reviewer.Reason, reviewer.RuleID = domain.AssignmentReasonRule, rule.ID
+				break
+			}
+		}
+
+		explanation.Reviewers = append(explanation.Reviewers, reviewer)
+	}
+
+	explanation.Rules = currentRuleDecisions(rules, author, pr, team.Members)
+	return pr, explanation, nil
+}
+
+// currentRuleDecisions показывает, как правила команды соотносятся с уже назначенными ревьюерами PR.
+func currentRuleDecisions(
+	rules []domain.ReviewRule,
+	author domain.User,
+	pr domain.PullRequest,
+	members []domain.User,
+) []domain.RuleDecision {
+	byID := make(map[string]domain.User, len(members))
+
+	for _, m := range members {
+		byID[m.ID] = m
+	}
+
+	var decisions []domain.RuleDecision
+
+	for _, rule := range rules {
+		if !rule.Matches(author, pr.Labels) {
+			continue
+		}
+
+		if rule.Kind == domain.RuleKindExclude {
+			for _, m := range members {
+				if m.ID != author.ID && rule.Targets(m) {
+					decisions = append(decisions, decisionFor(rule, m.ID, domain.RuleOutcomeExcluded))
+				}
+			}
+
+			continue
+		}
+
+		decision := decisionFor(rule, rule.ReviewerID, domain.RuleOutcomeUnsatisfied)
+
+		for _, id := range pr.AssignedReviewers {
+			if rule.Targets(byID[id]) {
+				decision = decisionFor(rule, id, domain.RuleOutcomeRequired)
+				break
+			}
+		}
+
+		decisions = append(decisions, decision)
+	}
+
+	return decisions
+}
+
+// candidateInput — всё, что нужно, чтобы объяснить статус каждого участника команды.
+type candidateInput struct {
+	members []domain.User
+	// policy — допуск кандидатов с учётом отсутствий, уже назначенных ревьюеров и правил команды.
+	policy eligibilityPolicy
+	// selected — ревьюеры, назначенные в результате этого выбора.
+	selected map[string]struct{}
+	pool     selectionPool
+}
+
+// explainCandidates описывает каждого участника команды: почему он не мог быть выбран
+// или как его оценила стратегия. Участники упорядочены по user_id.
+func explainCandidates(in candidateInput) []domain.CandidateReport {
+	members := make([]domain.User, len(in.members))
+	copy(members, in.members)
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].ID < members[j].ID
+	})
+
+	reports := make([]domain.CandidateReport, 0, len(members))
+	var eligible []domain.User
+
+	for _, m := range members {
+		_, isSelected := in.selected[m.ID]
+		reason, ruleID := in.policy.check(m)
+
+		report := domain.CandidateReport{
+			UserID:   m.ID,
+			Excluded: reason,
+			RuleID:   ruleID,
+			Assigned: in.policy.isAssigned(m.ID) || isSelected,
+		}
+
+		if m.IsActive && in.policy.isAvailable(m.ID) {
+			limit := in.pool.settings.CapacityFor(m)
+
+			report.Score = domain.CandidateScore{
+				Weight:         m.SelectionWeight(),
+				Pairings:       in.pool.pairings[m.ID],
+				OpenReviews:    in.pool.loads[m.ID],
+				MaxOpenReviews: limit,
+				InWorkingHours: m.InWorkingHours(in.pool.at),
+			}
+		}
+
+		switch {
+		case reason != "":
+			// причина исключения уже определена политикой допуска
+
+		case report.Score.MaxOpenReviews != nil && report.Score.OpenReviews >= *report.Score.MaxOpenReviews:
+			report.Excluded = domain.ExclusionCapacity
+
+		default:
+			eligible = append(eligible, m)
+		}
+
+		reports = append(reports, report)
+	}
+
+	if in.pool.settings.Strategy == domain.SelectionStrategyRoundRobin {
+		rotate(eligible, in.pool.cursor)
+
+		positions := make(map[string]int, len(eligible))
+
+		for i, u := range eligible {
+			positions[u.ID] = i + 1
+		}
+
+		for i := range reports {
+			reports[i].Score.RotationPosition = positions[reports[i].UserID]
+		}
+	}
+
+	return reports
+}
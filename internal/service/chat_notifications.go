@@ -0,0 +1,285 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+//go:embed templates/chat.tmpl
+var chatTemplateFS embed.FS
+
+// Всё, что задают пользователи (идентификатор и название PR, имена), проходит через slack:
+// иначе название вида "<!channel>" или "<https://…|текст>" стало бы упоминанием канала или ссылкой.
+var chatTemplates = template.Must(template.New("chat.tmpl").Funcs(template.FuncMap{
+	"mention":  mention,
+	"mentions": mentions,
+	"slack":    slackEscape,
+}).ParseFS(chatTemplateFS, "templates/chat.tmpl"))
+
+// slackMention — упоминание пользователя Slack по идентификатору ("<@U123>").
+var slackMention = regexp.MustCompile(`^<@[A-Za-z0-9]+>$`)
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackEscape экранирует управляющие символы разметки Slack (mrkdwn): &, < и >.
+func slackEscape(s string) string {
+	return slackEscaper.Replace(s)
+}
+
+const (
+	// chatSettleDelay — насколько свежие записи истории откладываются до следующего прохода:
+	// транзакции фиксируются не в порядке id, и без задержки курсор мог бы перескочить
+	// ещё не зафиксированную запись.
+	chatSettleDelay = 5 * time.Second
+	// chatBatchLimit — сколько записей истории команды обрабатывается за проход.
+	chatBatchLimit = 500
+)
+
+// ChatNotificationService отправляет в чаты команд уведомления о назначении и переназначении
+// ревьюверов. Источник — история назначений, поэтому уведомления не теряются при сбоях отправки
+// и не зависят от того, каким путём изменён список ревьюверов. Не больше limit сообщений
+// в окно window на чат: если изменений больше, остаток сворачивается в одно сводное сообщение,
+// а когда лимит исчерпан, изменения ждут следующего окна.
+type ChatNotificationService struct {
+	chatRepo domain.ChatRepository
+	sender   domain.ChatSender
+	limit    int
+	window   time.Duration
+}
+
+// NewChatNotificationService создаёт новый ChatNotificationService.
+func NewChatNotificationService(
+	chatRepo domain.ChatRepository,
+	sender domain.ChatSender,
+	limit int,
+	window time.Duration,
+) *ChatNotificationService {
+	return &ChatNotificationService{
+		chatRepo: chatRepo,
+		sender:   sender,
+		limit:    limit,
+		window:   window,
+	}
+}
+
+// chatNotification — одно изменение ревьюверов PR, сделанное одним действием.
+type chatNotification struct {
+	PRID       string
+	PRName     string
+	Author     domain.User
+	Assigned   []domain.User
+	Unassigned []domain.User
+}
+
+// Run отправляет уведомления, накопившиеся к моменту at, и возвращает число отправленных сообщений.
+// Доставка «хотя бы один раз»: при ошибке отправки курсор не сдвигается и изменения повторятся.
+func (s *ChatNotificationService) Run(ctx context.Context, at time.Time) (int, error) {
+	channels, err := s.chatRepo.ListChatChannels(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+
+	for _, ch := range channels {
+		n, err := s.notify(ctx, ch, at)
+		sent += n
+
+		if err != nil {
+			return sent, fmt.Errorf("notify team %s: %w", ch.TeamName, err)
+		}
+	}
+
+	return sent, nil
+}
+
+func (s *ChatNotificationService) notify(ctx context.Context, ch domain.ChatChannel, at time.Time) (int, error) {
+	if at.Sub(ch.WindowStart) >= s.window {
+		ch.WindowStart, ch.WindowCount = at, 0
+	}
+
+	quota := s.limit - ch.WindowCount
+
+	if quota <= 0 {
+		return 0, nil
+	}
+
+	changes, err := s.chatRepo.ListAssignmentChanges(ctx, ch.TeamName, ch.Cursor, at.Add(-chatSettleDelay), chatBatchLimit)
+
+	if err != nil || len(changes) == 0 {
+		return 0, err
+	}
+
+	if len(changes) == chatBatchLimit {
+		changes = completeTxs(changes)
+	}
+
+	notifications := groupChanges(changes)
+
+	// лишние изменения сворачиваются в сводку, занимающую последнее доступное сообщение
+	var summary []chatNotification
+
+	if len(notifications) > quota {
+		notifications, summary = notifications[:quota-1], notifications[quota-1:]
+	}
+
+	texts := make([]string, 0, len(notifications)+1)
+
+	for _, n := range notifications {
+		name := "assigned"
+
+		if len(n.Unassigned) > 0 {
+			name = "reassigned"
+		}
+
+		text, err := renderChat(name, n)
+
+		if err != nil {
+			return 0, err
+		}
+
+		texts = append(texts, text)
+	}
+
+	if len(summary) > 0 {
+		text, err := renderChat("summary", summary)
+
+		if err != nil {
+			return 0, err
+		}
+
+		texts = append(texts, text)
+	}
+
+	sent := 0
+
+	for _, text := range texts {
+		if err := s.sender.Post(ctx, ch.WebhookURL, domain.ChatMessage{Channel: ch.Channel, Text: text}); err != nil {
+			// уже отправленные сообщения учитываются в лимите, курсор остаётся на месте
+			ch.WindowCount += sent
+
+			if saveErr := s.chatRepo.SaveChatState(ctx, ch); saveErr != nil {
+				return sent, saveErr
+			}
+
+			return sent, err
+		}
+
+		sent++
+	}
+
+	ch.Cursor = changes[len(changes)-1].ID
+	ch.WindowCount += sent
+
+	return sent, s.chatRepo.SaveChatState(ctx, ch)
+}
+
+// completeTxs обрезает полную пачку до транзакций, целиком попавших в неё: последняя транзакция
+// могла не уместиться и будет прочитана в следующий проход. Записи параллельных транзакций могут
+// чередоваться по id, поэтому граница сдвигается к первой записи каждой транзакции из хвоста.
+// Пачка из одной транзакции отправляется как есть, иначе курсор никогда бы не сдвинулся.
+func completeTxs(changes []domain.AssignmentChange) []domain.AssignmentChange {
+	first := make(map[int64]int)
+
+	for i, c := range changes {
+		if _, ok := first[c.TxID]; !ok {
+			first[c.TxID] = i
+		}
+	}
+
+	cut := first[changes[len(changes)-1].TxID]
+
+	for i := len(changes) - 1; i >= cut; i-- {
+		cut = min(cut, first[changes[i].TxID])
+	}
+
+	if cut == 0 {
+		return changes
+	}
+
+	return changes[:cut]
+}
+
+// groupChanges объединяет записи истории одной транзакции по одному PR в уведомление.
+// Изменения, в которых ревьюверы только сняты, не уведомляются.
+func groupChanges(changes []domain.AssignmentChange) []chatNotification {
+	type key struct {
+		txID int64
+		prID string
+	}
+
+	index := make(map[key]int)
+
+	var res []chatNotification
+
+	for _, c := range changes {
+		k := key{txID: c.TxID, prID: c.PRID}
+		i, ok := index[k]
+
+		if !ok {
+			i = len(res)
+			index[k] = i
+			res = append(res, chatNotification{PRID: c.PRID, PRName: c.PRName, Author: c.Author})
+		}
+
+		if c.Action == domain.AssignmentActionAssigned {
+			res[i].Assigned = append(res[i].Assigned, c.Reviewer)
+		} else {
+			res[i].Unassigned = append(res[i].Unassigned, c.Reviewer)
+		}
+	}
+
+	filtered := res[:0]
+
+	for _, n := range res {
+		if len(n.Assigned) > 0 {
+			filtered = append(filtered, n)
+		}
+	}
+
+	return filtered
+}
+
+func renderChat(name string, data any) (string, error) {
+	var buf bytes.Buffer
+
+	if err := chatTemplates.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("render chat message %s: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// mention возвращает @-упоминание пользователя. Упоминание Slack по идентификатору ("<@U123>")
+// подставляется как есть, остальное экранируется; без имени в чате пользователь называется по username.
+func mention(u domain.User) string {
+	switch {
+	case slackMention.MatchString(u.ChatHandle):
+		return u.ChatHandle
+	case u.ChatHandle != "":
+		return "@" + slackEscape(u.ChatHandle)
+	case u.Username != "":
+		return slackEscape(u.Username)
+	default:
+		return slackEscape(u.ID)
+	}
+}
+
+func mentions(users []domain.User) string {
+	res := make([]string, 0, len(users))
+
+	for _, u := range users {
+		res = append(res, mention(u))
+	}
+
+	return strings.Join(res, ", ")
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+func TestRenderChat_EscapesUserInput(t *testing.T) {
+	n := chatNotification{
+		PRID:   "pr-<!here>",
+		PRName: "<!channel> & <https://evil.example|click>",
+		Author: domain.User{ID: "u1", Username: "<!everyone>"},
+		Assigned: []domain.User{
+			{ID: "u2", ChatHandle: "<@U2>"},
+			{ID: "u3", ChatHandle: "<!channel>"},
+		},
+		Unassigned: []domain.User{{ID: "<u4>"}},
+	}
+
+	for _, name := range []string{"assigned", "reassigned"} {
+		text, err := renderChat(name, n)
+
+		if err != nil {
+			t.Fatalf("render %s: %v", name, err)
+		}
+
+		if strings.Contains(text, "<!") || strings.Contains(text, "<https") {
+			t.Fatalf("%s: user input is not escaped: %s", name, text)
+		}
+
+		for _, want := range []string{"&lt;!channel&gt; &amp; &lt;https://evil.example|click&gt;", "pr-&lt;!here&gt;", "<@U2>"} {
+			if !strings.Contains(text, want) {
+				t.Fatalf("%s: expected %q in %s", name, want, text)
+			}
+		}
+	}
+
+	text, err := renderChat("reassigned", n)
+
+	if err != nil || !strings.Contains(text, "&lt;u4&gt;") || !strings.Contains(text, "@&lt;!channel&gt;") {
+		t.Fatalf("mentions are not escaped: %s (%v)", text, err)
+	}
+
+	text, err = renderChat("summary", []chatNotification{n})
+
+	if err != nil || strings.Contains(text, "<!") || !strings.Contains(text, "&lt;!channel&gt; &amp;") {
+		t.Fatalf("summary is not escaped: %s (%v)", text, err)
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// ChatCommand — команда из чата (slash-команда /review): кто её вызвал и текст после имени команды.
+type ChatCommand struct {
+	// ChatUserID — неизменяемый идентификатор вызвавшего в чате (user_id Slack).
+	ChatUserID string
+	Text       string
+}
+
+// chatOpsUsage — подсказка по командам, которая возвращается на help и неизвестные команды.
+const chatOpsUsage = "Команды:\n" +
+	"• `/review mine` — мои ревью\n" +
+	"• `/review reassign <pr>` — передать моё ревью PR другому участнику команды\n" +
+	"• `/review away until <YYYY-MM-DD>` — отсутствую до указанного дня (ревью не назначаются)"
+
+// ChatOpsService выполняет команды из чата от имени пользователя, сопоставленного
+// по идентификатору в чате (chat_handle), и возвращает текст ответа.
+type ChatOpsService struct {
+	userRepo   domain.UserRepository
+	userSvc    *UserService
+	prSvc      *PullRequestService
+	absenceSvc *AbsenceService
+}
+
+// NewChatOpsService создаёт новый ChatOpsService.
+func NewChatOpsService(
+	userRepo domain.UserRepository,
+	userSvc *UserService,
+	prSvc *PullRequestService,
+	absenceSvc *AbsenceService,
+) *ChatOpsService {
+	return &ChatOpsService{
+		userRepo:   userRepo,
+		userSvc:    userSvc,
+		prSvc:      prSvc,
+		absenceSvc: absenceSvc,
+	}
+}
+
+// Execute выполняет команду cmd в момент at. Ошибки бизнес-логики возвращаются как DomainError,
+// чтобы вызывающий мог показать их пользователю.
+func (s *ChatOpsService) Execute(ctx context.Context, cmd ChatCommand, at time.Time) (string, error) {
+	args := strings.Fields(cmd.Text)
+
+	if len(args) == 0 || args[0] == "help" {
+		return chatOpsUsage, nil
+	}
+
+	user, err := s.caller(ctx, cmd)
+
+	if err != nil {
+		return "", err
+	}
+
+	// сервисы ограничивают участника его командой и записывают его автором изменений
+	ctx = domain.WithCaller(ctx, domain.Caller{
+		Name:   "chat:" + cmd.ChatUserID,
+		OrgID:  domain.OrganizationFrom(ctx),
+		UserID: user.ID,
+		Role:   domain.RoleMember,
+	})
+
+	switch {
+	case args[0] == "mine" && len(args) == 1:
+		return s.mine(ctx, user)
+
+	case args[0] == "reassign" && len(args) == 2:
+		return s.reassign(ctx, user, args[1])
+
+	case args[0] == "away" && len(args) == 3 && args[1] == "until":
+		return s.away(ctx, user, args[2], at)
+	}
+
+	return "Неизвестная команда `" + cmd.Text + "`.\n" + chatOpsUsage, nil
+}
+
+// caller находит пользователя по идентификатору в чате — в формате упоминания Slack или как есть.
+// Имя пользователя в чате (user_name) не используется: его может сменить сам пользователь.
+func (s *ChatOpsService) caller(ctx context.Context, cmd ChatCommand) (domain.User, error) {
+	if cmd.ChatUserID == "" {
+		return domain.User{}, domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("user_id is required: %w", domain.ErrInvalidInput))
+	}
+
+	user, err := s.userRepo.GetByChatHandle(ctx, []string{"<@" + cmd.ChatUserID + ">", cmd.ChatUserID})
+
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.User{}, domain.NewDomainError(domain.ErrorCodeNotFound,
+			fmt.Errorf("no user with chat handle <@%s>, set it via /users/setNotifications: %w", cmd.ChatUserID, err))
+	}
+
+	return user, err
+}
+
+func (s *ChatOpsService) mine(ctx context.Context, user domain.User) (string, error) {
+	_, prs, load, err := s.userSvc.GetReviewPRs(ctx, user.ID)
+
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	for _, pr := range prs {
+		if pr.Status == domain.PRStatusOpen {
+			fmt.Fprintf(&b, "\n• *%s* «%s» от %s", slackEscape(pr.ID), slackEscape(pr.Name), slackEscape(pr.AuthorID))
+		}
+	}
+
+	if b.Len() == 0 {
+		return "Открытых ревью нет.", nil
+	}
+
+	limit := "без лимита"
+
+	if load.MaxOpenReviews != nil {
+		limit = fmt.Sprintf("лимит %d", *load.MaxOpenReviews)
+	}
+
+	return fmt.Sprintf("Открытые ревью (%d, %s):%s", load.OpenReviews, limit, b.String()), nil
+}
+
+func (s *ChatOpsService) reassign(ctx context.Context, user domain.User, prID string) (string, error) {
+	_, replacedBy, err := s.prSvc.ReassignReviewer(ctx, prID, user.ID, "")
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Ревью PR *%s* передано %s.", slackEscape(prID), slackEscape(replacedBy)), nil
+}
+
+// away отмечает пользователя отсутствующим с момента at до начала дня date по его часовому поясу.
+func (s *ChatOpsService) away(ctx context.Context, user domain.User, date string, at time.Time) (string, error) {
+	until, err := time.ParseInLocation(time.DateOnly, date, user.Location())
+
+	if err != nil {
+		return "", domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("invalid date %q, expected YYYY-MM-DD: %w", date, domain.ErrInvalidInput))
+	}
+
+	if _, err := s.absenceSvc.AddAbsence(ctx, user.ID, at, until, "/review away"); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Отсутствие до %s отмечено, новые ревью назначаться не будут.", date), nil
+}
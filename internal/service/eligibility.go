@@ -0,0 +1,125 @@
+package service
+
+import "pr-reviewer-service/internal/domain"
+
+// eligibilityPolicy — единые правила допуска пользователя в ревьюеры PR. Ими пользуются
+// создание PR, переназначение, ручное назначение и объяснение выбора, чтобы инварианты
+// (автор не ревьюит свой PR, только активные участники команды, без дублей) проверялись в одном месте.
+type eligibilityPolicy struct {
+	author   domain.User
+	teamName string
+	assigned map[string]struct{}
+	// declined — кто уже отказывался от этого PR; такие не подбираются автоматически.
+	declined map[string]struct{}
+	// available — кто не отсутствует в момент выбора; nil — отсутствия не проверяются.
+	available map[string]struct{}
+	rules     []domain.ReviewRule
+	labels    []string
+}
+
+// newEligibilityPolicy создаёт политику для PR автора author, ревьюеры которого выбираются
+// из команды teamName; assigned — уже назначенные на PR ревьюеры.
+func newEligibilityPolicy(author domain.User, teamName string, assigned []string) eligibilityPolicy {
+	p := eligibilityPolicy{
+		author:   author,
+		teamName: teamName,
+		assigned: make(map[string]struct{}, len(assigned)),
+	}
+
+	for _, id := range assigned {
+		p.assigned[id] = struct{}{}
+	}
+
+	return p
+}
+
+// withAvailability ограничивает допуск пользователями из available (не отсутствующими сейчас).
+func (p eligibilityPolicy) withAvailability(available []domain.User) eligibilityPolicy {
+	p.available = make(map[string]struct{}, len(available))
+
+	for _, u := range available {
+		p.available[u.ID] = struct{}{}
+	}
+
+	return p
+}
+
+// withDeclined исключает пользователей, уже отказавшихся от PR.
+func (p eligibilityPolicy) withDeclined(declined []string) eligibilityPolicy {
+	p.declined = make(map[string]struct{}, len(declined))
+
+	for _, id := range declined {
+		p.declined[id] = struct{}{}
+	}
+
+	return p
+}
+
+// withRules добавляет исключения по правилам команды для PR с метками labels.
+// При создании PR правила применяет applyRules, чтобы записать решения по ним, поэтому там
+// политика используется без правил.
+func (p eligibilityPolicy) withRules(rules []domain.ReviewRule, labels []string) eligibilityPolicy {
+	p.rules = rules
+	p.labels = labels
+	return p
+}
+
+// isAvailable сообщает, не отсутствует ли пользователь (всегда true, если отсутствия не проверяются).
+func (p eligibilityPolicy) isAvailable(userID string) bool {
+	if p.available == nil {
+		return true
+	}
+
+	_, ok := p.available[userID]
+	return ok
+}
+
+// isAssigned сообщает, назначен ли пользователь на PR до выбора.
+func (p eligibilityPolicy) isAssigned(userID string) bool {
+	_, ok := p.assigned[userID]
+	return ok
+}
+
+// check возвращает первую по приоритету причину, по которой u не может стать ревьюером
+// (пустую, если может), и для RULE — идентификатор исключающего правила.
+func (p eligibilityPolicy) check(u domain.User) (domain.ExclusionReason, int64) {
+	switch {
+	case u.ID == p.author.ID:
+		return domain.ExclusionAuthor, 0
+
+	case !u.IsActive:
+		return domain.ExclusionInactive, 0
+
+	case u.TeamName != p.teamName:
+		return domain.ExclusionNotInTeam, 0
+
+	case !p.isAvailable(u.ID):
+		return domain.ExclusionAbsent, 0
+
+	case p.isAssigned(u.ID):
+		return domain.ExclusionAlreadyAssigned, 0
+	}
+
+	if _, ok := p.declined[u.ID]; ok {
+		return domain.ExclusionDeclined, 0
+	}
+
+	if ruleID, ok := excludedByRules(p.rules, p.author, p.labels, []domain.User{u})[u.ID]; ok {
+		return domain.ExclusionRule, ruleID
+	}
+
+	return "", 0
+}
+
+// filter возвращает допустимых кандидатов из users, сохраняя их порядок.
+func (p eligibilityPolicy) filter(users []domain.User) []domain.User {
+	res := make([]domain.User, 0, len(users))
+
+	for _, u := range users {
+		if reason, _ := p.check(u); reason == "" {
+			res = append(res, u)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,426 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/random"
+)
+
+var (
+	levels     = []domain.Seniority{"", domain.SeniorityJunior, domain.SeniorityMiddle, domain.SenioritySenior, domain.SeniorityLead}
+	strategies = []domain.SelectionStrategy{
+		domain.SelectionStrategyRandom,
+		domain.SelectionStrategyWeighted,
+		domain.SelectionStrategyRoundRobin,
+	}
+	testLabels = []string{"", "backend", "security"}
+)
+
+// randomTeam — случайная команда с PR, на котором часть участников уже назначена.
+type randomTeam struct {
+	users     []domain.User
+	author    domain.User
+	available []domain.User
+	assigned  []string
+	rules     []domain.ReviewRule
+	labels    []string
+}
+
+func generateTeam(r *mathrand.Rand) randomTeam {
+	var t randomTeam
+
+	n := 1 + r.Intn(10)
+
+	for i := 0; i < n; i++ {
+		u := domain.User{
+			ID:        fmt.Sprintf("u%d", i),
+			TeamName:  "core",
+			IsActive:  r.Intn(4) > 0,
+			Seniority: levels[r.Intn(len(levels))],
+		}
+
+		// часть пользователей из чужой команды попадает в выборку, как после смены команды
+		if r.Intn(6) == 0 {
+			u.TeamName = "other"
+		}
+
+		t.users = append(t.users, u)
+	}
+
+	t.author = t.users[r.Intn(n)]
+	t.author.TeamName = "core"
+
+	for i, u := range t.users {
+		if u.ID == t.author.ID {
+			t.users[i] = t.author
+		}
+
+		if r.Intn(5) > 0 {
+			t.available = append(t.available, t.users[i])
+		}
+
+		if r.Intn(4) == 0 {
+			t.assigned = append(t.assigned, u.ID)
+		}
+	}
+
+	for i := 0; i < r.Intn(4); i++ {
+		rule := domain.ReviewRule{
+			ID:    int64(i + 1),
+			Kind:  domain.RuleKindExclude,
+			Label: testLabels[r.Intn(len(testLabels))],
+		}
+
+		if r.Intn(2) == 0 {
+			rule.Kind = domain.RuleKindRequire
+		}
+
+		if r.Intn(2) == 0 {
+			rule.ReviewerID = t.users[r.Intn(n)].ID
+		} else {
+			rule.ReviewerLevel = levels[1+r.Intn(len(levels)-1)]
+		}
+
+		t.rules = append(t.rules, rule)
+	}
+
+	if l := testLabels[r.Intn(len(testLabels))]; l != "" {
+		t.labels = []string{l}
+	}
+
+	return t
+}
+
+// checkInvariants проверяет, что reviewers могут быть ревьюерами PR команды t.
+func checkInvariants(t randomTeam, reviewers []string, withRules bool) error {
+	byID := make(map[string]domain.User, len(t.users))
+
+	for _, u := range t.users {
+		byID[u.ID] = u
+	}
+
+	available := newEligibilityPolicy(t.author, "core", nil).withAvailability(t.available)
+	excluded := excludedByRules(t.rules, t.author, t.labels, t.users)
+	seen := make(map[string]struct{}, len(reviewers))
+
+	for _, id := range reviewers {
+		u := byID[id]
+
+		switch {
+		case id == t.author.ID:
+			return fmt.Errorf("author %s selected", id)
+		case !u.IsActive:
+			return fmt.Errorf("inactive %s selected", id)
+		case u.TeamName != "core":
+			return fmt.Errorf("%s from team %s selected", id, u.TeamName)
+		case !available.isAvailable(id):
+			return fmt.Errorf("absent %s selected", id)
+		}
+
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("%s selected twice", id)
+		}
+
+		seen[id] = struct{}{}
+
+		for _, a := range t.assigned {
+			if a == id {
+				return fmt.Errorf("already assigned %s selected", id)
+			}
+		}
+
+		if _, ok := excluded[id]; ok && withRules {
+			return fmt.Errorf("%s excluded by rule %d selected", id, excluded[id])
+		}
+	}
+
+	return nil
+}
+
+func TestEligibilityPolicy_FilterKeepsInvariants(t *testing.T) {
+	property := func(seed int64) bool {
+		team := generateTeam(mathrand.New(mathrand.NewSource(seed)))
+		policy := newEligibilityPolicy(team.author, "core", team.assigned).
+			withAvailability(team.available).
+			withRules(team.rules, team.labels)
+
+		eligible := policy.filter(team.users)
+
+		if err := checkInvariants(team, ids(eligible), true); err != nil {
+			t.Logf("seed %d: %v", seed, err)
+			return false
+		}
+
+		// каждый отброшенный пользователь должен иметь причину исключения
+		kept := make(map[string]struct{}, len(eligible))
+
+		for _, u := range eligible {
+			kept[u.ID] = struct{}{}
+		}
+
+		for _, u := range team.users {
+			if _, ok := kept[u.ID]; ok {
+				continue
+			}
+
+			if reason, _ := policy.check(u); reason == "" {
+				t.Logf("seed %d: %s dropped without reason", seed, u.ID)
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Фейковые репозитории в памяти: свойства проверяются через PullRequestService целиком, а не через
+// копию его конвейера. Встроенные интерфейсы оставлены nil — вызов неожиданного метода уронит тест.
+
+type fakeUserRepo struct {
+	domain.UserRepository
+	team randomTeam
+}
+
+func (f *fakeUserRepo) GetByID(_ context.Context, id string) (domain.User, error) {
+	for _, u := range f.team.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+
+	return domain.User{}, domain.ErrNotFound
+}
+
+func (f *fakeUserRepo) GetTeamByUserID(ctx context.Context, id string) (string, error) {
+	u, err := f.GetByID(ctx, id)
+	return u.TeamName, err
+}
+
+func (f *fakeUserRepo) GetAvailableTeamMembersExcept(
+	_ context.Context,
+	teamName, excludeUserID string,
+	_ time.Time,
+) ([]domain.User, error) {
+	var res []domain.User
+
+	for _, u := range f.team.available {
+		if u.TeamName == teamName && u.IsActive && u.ID != excludeUserID {
+			res = append(res, u)
+		}
+	}
+
+	return res, nil
+}
+
+type fakeTeamRepo struct {
+	domain.TeamRepository
+	settings domain.TeamSettings
+	cursor   string
+}
+
+func (f *fakeTeamRepo) GetSettings(context.Context, string) (domain.TeamSettings, error) {
+	return f.settings, nil
+}
+
+func (f *fakeTeamRepo) AdvanceRoundRobin(
+	_ context.Context,
+	_ string,
+	advance func(cursor string) (string, error),
+) error {
+	next, err := advance(f.cursor)
+
+	if err != nil {
+		return err
+	}
+
+	f.cursor = next
+	return nil
+}
+
+type fakeRuleRepo struct {
+	domain.ReviewRuleRepository
+	rules []domain.ReviewRule
+}
+
+func (f *fakeRuleRepo) ListByTeam(context.Context, string) ([]domain.ReviewRule, error) {
+	return f.rules, nil
+}
+
+type fakePRRepo struct {
+	domain.PullRequestRepository
+	prs map[string]domain.PullRequest
+}
+
+func (f *fakePRRepo) WithTx(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return fn(ctx, nil)
+}
+
+func (f *fakePRRepo) PRExists(_ context.Context, id string) (bool, error) {
+	_, ok := f.prs[id]
+	return ok, nil
+}
+
+func (f *fakePRRepo) Create(_ context.Context, pr domain.PullRequest) error {
+	f.prs[pr.ID] = pr
+	return nil
+}
+
+func (f *fakePRRepo) GetByID(_ context.Context, id string) (domain.PullRequest, error) {
+	pr, ok := f.prs[id]
+
+	if !ok {
+		return domain.PullRequest{}, domain.ErrNotFound
+	}
+
+	return pr, nil
+}
+
+func (f *fakePRRepo) CountOpenReviews(context.Context, []string) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func (f *fakePRRepo) CountPairings(context.Context, string, []string, time.Time) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func (f *fakePRRepo) ListDecliners(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakePRRepo) ReassignReviewer(_ context.Context, prID, oldReviewerID, newReviewerID string) (domain.PullRequest, error) {
+	pr := f.prs[prID]
+	reviewers := make([]string, 0, len(pr.AssignedReviewers))
+
+	for _, id := range pr.AssignedReviewers {
+		if id == oldReviewerID {
+			id = newReviewerID
+		}
+
+		reviewers = append(reviewers, id)
+	}
+
+	pr.AssignedReviewers = reviewers
+	f.prs[prID] = pr
+	return pr, nil
+}
+
+// newTestPRService собирает PullRequestService над командой team с фейковыми репозиториями.
+func newTestPRService(team randomTeam, settings domain.TeamSettings, cursor string, r random.Rand) (*PullRequestService, *fakePRRepo) {
+	prs := &fakePRRepo{prs: make(map[string]domain.PullRequest)}
+	svc := NewPullRequestService(prs, &fakeUserRepo{team: team}, &fakeTeamRepo{settings: settings, cursor: cursor},
+		&fakeRuleRepo{rules: team.rules}, nil, r)
+
+	return svc, prs
+}
+
+func TestEligibilityPolicy_CreateNeverPicksIneligible(t *testing.T) {
+	property := func(seed int64) bool {
+		r := mathrand.New(mathrand.NewSource(seed))
+		team := generateTeam(r)
+
+		// при создании PR ещё никто не назначен
+		team.assigned = nil
+
+		svc, _ := newTestPRService(team, domain.TeamSettings{
+			Strategy:     strategies[r.Intn(len(strategies))],
+			MaxReviewers: 1 + r.Intn(3),
+		}, "", r)
+
+		pr, _, err := svc.CreatePR(context.Background(), "pr-1", "PR", team.author.ID, "", 0, team.labels, false)
+
+		if err != nil {
+			t.Logf("seed %d: create: %v", seed, err)
+			return false
+		}
+
+		if err := checkInvariants(team, pr.AssignedReviewers, true); err != nil {
+			t.Logf("seed %d: %v", seed, err)
+			return false
+		}
+
+		return true
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEligibilityPolicy_ReassignNeverPicksIneligible(t *testing.T) {
+	property := func(seed int64) bool {
+		r := mathrand.New(mathrand.NewSource(seed))
+		team := generateTeam(r)
+
+		// заменяемый ревьюер — участник команды автора, кроме самого автора
+		old := team.users[r.Intn(len(team.users))]
+
+		if old.ID == team.author.ID || old.TeamName != "core" {
+			return true
+		}
+
+		assigned := team.assigned
+		team.assigned = []string{old.ID}
+
+		for _, id := range assigned {
+			if id != old.ID {
+				team.assigned = append(team.assigned, id)
+			}
+		}
+
+		svc, prs := newTestPRService(team, domain.TeamSettings{Strategy: strategies[r.Intn(len(strategies))]},
+			team.users[r.Intn(len(team.users))].ID, r)
+		prs.prs["pr-1"] = domain.PullRequest{
+			ID:                "pr-1",
+			AuthorID:          team.author.ID,
+			Status:            domain.PRStatusOpen,
+			AssignedReviewers: team.assigned,
+			Labels:            team.labels,
+		}
+
+		_, replacedBy, err := svc.ReassignReviewer(context.Background(), "pr-1", old.ID, "")
+
+		var derr *domain.DomainError
+
+		if errors.As(err, &derr) && derr.Code == domain.ErrorCodeNoCandidate {
+			return true
+		}
+
+		if err != nil {
+			t.Logf("seed %d: reassign: %v", seed, err)
+			return false
+		}
+
+		if err := checkInvariants(team, []string{replacedBy}, true); err != nil {
+			t.Logf("seed %d: %v", seed, err)
+			return false
+		}
+
+		return true
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ids(users []domain.User) []string {
+	res := make([]string, 0, len(users))
+
+	for _, u := range users {
+		res = append(res, u.ID)
+	}
+
+	return res
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// Ограничения размера страницы событий.
+const (
+	DefaultEventsLimit = 100
+	MaxEventsLimit     = 1000
+)
+
+// EventService отдаёт события сервиса внешним потребителям.
+type EventService struct {
+	eventRepo domain.EventRepository
+	userRepo  domain.UserRepository
+}
+
+// NewEventService создаёт новый EventService.
+func NewEventService(eventRepo domain.EventRepository, userRepo domain.UserRepository) *EventService {
+	return &EventService{eventRepo: eventRepo, userRepo: userRepo}
+}
+
+// List возвращает события после afterID (teamName пуст — всех команд; тимлиду — только его команды).
+// limit == 0 — значение по умолчанию.
+func (s *EventService) List(ctx context.Context, teamName string, afterID int64, limit int) ([]domain.Event, error) {
+	if afterID < 0 || limit < 0 || limit > MaxEventsLimit {
+		return nil, domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("after_id must be non-negative and limit within [0, %d]: %w", MaxEventsLimit, domain.ErrInvalidInput))
+	}
+
+	if limit == 0 {
+		limit = DefaultEventsLimit
+	}
+
+	teamName, err := scopeTeam(ctx, s.userRepo, teamName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.eventRepo.List(ctx, teamName, afterID, limit)
+}
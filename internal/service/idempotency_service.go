@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// maxIdempotencyKeyLength — максимальная длина ключа идемпотентности.
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyService запоминает ответы на запросы с ключом идемпотентности, чтобы повтор запроса
+// (например, после таймаута) получил исходный ответ, а не выполнился ещё раз.
+type IdempotencyService struct {
+	repo      domain.IdempotencyRepository
+	retention time.Duration
+	lease     time.Duration
+}
+
+// NewIdempotencyService создаёт новый IdempotencyService; ответы хранятся retention, а ключ
+// незавершённого запроса считается брошенным через lease.
+func NewIdempotencyService(repo domain.IdempotencyRepository, retention, lease time.Duration) *IdempotencyService {
+	return &IdempotencyService{repo: repo, retention: retention, lease: lease}
+}
+
+// Begin начинает запрос вызывающего из ctx с ключом key и хешем requestHash. Если запрос с этим ключом
+// уже выполнен, возвращает его запись для повтора ответа (replay == true); иначе — занятую запись,
+// которую нужно передать в Complete или Release. Повтор с другим запросом — IDEMPOTENCY_KEY_REUSED,
+// повтор до завершения исходного — IDEMPOTENCY_KEY_IN_FLIGHT (пока не истёк lease).
+func (s *IdempotencyService) Begin(
+	ctx context.Context,
+	key, requestHash string,
+	at time.Time,
+) (rec domain.IdempotencyRecord, replay bool, err error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return domain.IdempotencyRecord{}, false, domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("idempotency key must be at most %d characters: %w", maxIdempotencyKeyLength, domain.ErrInvalidInput))
+	}
+
+	// время хранится в базе с точностью до микросекунд, а по нему попытка потом находит свою запись
+	at = at.Truncate(time.Microsecond)
+	attempt := domain.IdempotencyRecord{Key: key, Caller: idempotencyOwner(ctx), RequestHash: requestHash, CreatedAt: at}
+
+	rec, reserved, err := s.repo.Reserve(ctx, attempt, at.Add(-s.retention), at.Add(-s.lease))
+
+	// запись освободили между вставкой и чтением — занимаем ключ ещё раз
+	if err == domain.ErrNotFound {
+		rec, reserved, err = s.repo.Reserve(ctx, attempt, at.Add(-s.retention), at.Add(-s.lease))
+	}
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.IdempotencyRecord{}, false, domain.NewDomainError(domain.ErrorCodeIdempotencyKeyInFlight,
+				domain.ErrIdempotencyInFlight)
+		}
+
+		return domain.IdempotencyRecord{}, false, err
+	}
+
+	if reserved {
+		return rec, false, nil
+	}
+
+	if rec.RequestHash != requestHash {
+		return domain.IdempotencyRecord{}, false, domain.NewDomainError(domain.ErrorCodeIdempotencyKeyReused,
+			domain.ErrIdempotencyKeyReuse)
+	}
+
+	if !rec.Completed {
+		return domain.IdempotencyRecord{}, false, domain.NewDomainError(domain.ErrorCodeIdempotencyKeyInFlight,
+			domain.ErrIdempotencyInFlight)
+	}
+
+	return rec, true, nil
+}
+
+// Complete сохраняет ответ rec на запрос, начатый Begin.
+func (s *IdempotencyService) Complete(ctx context.Context, rec domain.IdempotencyRecord) error {
+	return s.repo.Complete(ctx, rec)
+}
+
+// Release освобождает ключ запроса rec, если ответ не сохраняется (например, при внутренней ошибке),
+// чтобы повтор выполнил запрос заново.
+func (s *IdempotencyService) Release(ctx context.Context, rec domain.IdempotencyRecord) error {
+	return s.repo.Release(ctx, rec)
+}
+
+// Cleanup удаляет ответы старше срока хранения и возвращает их число.
+func (s *IdempotencyService) Cleanup(ctx context.Context, now time.Time) (int64, error) {
+	return s.repo.DeleteExpired(ctx, now.Add(-s.retention))
+}
+
+// idempotencyOwner возвращает владельца ключей идемпотентности: ключ API, а без него — пользователя
+// токена или имя вызывающего. Один и тот же ключ у разных вызывающих означает разные запросы.
+func idempotencyOwner(ctx context.Context) string {
+	caller, ok := domain.CallerFrom(ctx)
+
+	switch {
+	case !ok:
+		return ""
+	case caller.KeyID != 0:
+		return fmt.Sprintf("key:%d", caller.KeyID)
+	case caller.UserID != "":
+		return "user:" + caller.UserID
+	default:
+		return "name:" + caller.Name
+	}
+}
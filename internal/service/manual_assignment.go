@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// AddReviewer вручную назначает reviewerID на PR от имени actorID (пустой — вызывающий, см. resolveActor).
+// Ревьюер должен быть активным участником команды автора (или команды ревьюверов репозитория PR),
+// не автором, не назначенным и не исключённым правилами команды; число ревьюеров не может
+// превысить max_reviewers команды.
+func (s *PullRequestService) AddReviewer(ctx context.Context, prID, reviewerID, actorID string) (domain.PullRequest, error) {
+	pr, author, err := s.loadOpenPR(ctx, prID)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := authorizeTeam(ctx, s.userRepo, author.TeamName); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	actorID, err = s.resolveActor(ctx, actorID)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	scope, err := s.reviewScopeFor(ctx, pr.Repository, author.TeamName)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := s.checkManualReviewer(ctx, pr, author, scope, reviewerID); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	settings, err := s.teamRepo.GetSettings(ctx, scope.team)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	updated, err := s.prRepo.AddReviewer(ctx, prID, reviewerID, actorID, settings.MaxReviewers)
+
+	if err != nil {
+		return domain.PullRequest{}, mapReviewerChangeError(err)
+	}
+
+	return updated, nil
+}
+
+// Volunteer назначает пользователя ревьюером PR по его собственной просьбе. Пользователь, привязанный
+// к ключу или токену, вызывается только сам (пустой userID — он же).
+func (s *PullRequestService) Volunteer(ctx context.Context, prID, userID string) (domain.PullRequest, error) {
+	userID, err := s.resolveActor(ctx, userID)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	return s.AddReviewer(ctx, prID, userID, userID)
+}
+
+// RemoveReviewer снимает ревьюера с PR от имени actorID (пустой — вызывающий) без подбора замены.
+func (s *PullRequestService) RemoveReviewer(ctx context.Context, prID, reviewerID, actorID string) (domain.PullRequest, error) {
+	_, author, err := s.loadOpenPR(ctx, prID)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := authorizeTeam(ctx, s.userRepo, author.TeamName); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	actorID, err = s.resolveActor(ctx, actorID)
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	updated, err := s.prRepo.RemoveReviewer(ctx, prID, reviewerID, actorID)
+
+	if err != nil {
+		return domain.PullRequest{}, mapReviewerChangeError(err)
+	}
+
+	return updated, nil
+}
+
+// loadOpenPR возвращает PR и его автора; для слитого PR возвращает PR_MERGED.
+func (s *PullRequestService) loadOpenPR(ctx context.Context, prID string) (domain.PullRequest, domain.User, error) {
+	pr, err := s.prRepo.GetByID(ctx, prID)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.PullRequest{}, domain.User{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return domain.PullRequest{}, domain.User{}, err
+	}
+
+	if pr.Status == domain.PRStatusMerged {
+		return domain.PullRequest{}, domain.User{}, domain.NewDomainError(domain.ErrorCodePRMerged, domain.ErrPRMerged)
+	}
+
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+
+	if err != nil {
+		return domain.PullRequest{}, domain.User{}, err
+	}
+
+	return pr, author, nil
+}
+
+// resolveActor возвращает, от чьего имени записать изменение. Вызывающий, привязанный к пользователю,
+// действует только от своего имени (пустой actorID — он же); указать другого пользователя могут
+// только ADMIN и BOT, которые действуют по поручению пользователей.
+func (s *PullRequestService) resolveActor(ctx context.Context, actorID string) (string, error) {
+	if caller, ok := domain.CallerFrom(ctx); ok && caller.UserID != "" {
+		if actorID == "" {
+			actorID = caller.UserID
+		}
+
+		if actorID != caller.UserID && !caller.HasRole(domain.RoleAdmin, domain.RoleBot) {
+			return "", domain.NewDomainError(domain.ErrorCodeForbidden,
+				fmt.Errorf("caller %s cannot act on behalf of %s: %w", caller.UserID, actorID, domain.ErrForbidden))
+		}
+	}
+
+	if actorID == "" {
+		return "", nil
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, actorID); err != nil {
+		if err == domain.ErrNotFound {
+			return "", domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return "", err
+	}
+
+	return actorID, nil
+}
+
+// checkManualReviewer проверяет, что reviewerID можно вручную назначить на PR ревьюером из команды scope.
+func (s *PullRequestService) checkManualReviewer(
+	ctx context.Context,
+	pr domain.PullRequest,
+	author domain.User,
+	scope reviewScope,
+	reviewerID string,
+) error {
+	reviewer, err := s.userRepo.GetByID(ctx, reviewerID)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return err
+	}
+
+	rules, err := s.ruleRepo.ListByTeam(ctx, scope.team)
+
+	if err != nil {
+		return err
+	}
+
+	reason, _ := newEligibilityPolicy(author, scope.team, pr.AssignedReviewers).
+		withRules(rules, pr.Labels).
+		check(reviewer)
+
+	switch reason {
+	case domain.ExclusionAuthor:
+		return domain.NewDomainError(domain.ErrorCodeReviewerIsAuthor, domain.ErrReviewerIsAuthor)
+	case domain.ExclusionInactive:
+		return domain.NewDomainError(domain.ErrorCodeReviewerInactive, domain.ErrReviewerInactive)
+	case domain.ExclusionNotInTeam:
+		return domain.NewDomainError(domain.ErrorCodeReviewerNotInTeam, domain.ErrReviewerNotInTeam)
+	case domain.ExclusionAlreadyAssigned:
+		return domain.NewDomainError(domain.ErrorCodeAlreadyAssigned, domain.ErrAlreadyAssigned)
+	case domain.ExclusionRule:
+		return domain.NewDomainError(domain.ErrorCodeReviewerExcluded, domain.ErrReviewerExcluded)
+	}
+
+	return nil
+}
+
+// mapReviewerChangeError переводит ошибки репозитория при изменении списка ревьюеров в доменные коды.
+func mapReviewerChangeError(err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return domain.NewDomainError(domain.ErrorCodeNotFound, err)
+	case domain.ErrPRMerged:
+		return domain.NewDomainError(domain.ErrorCodePRMerged, err)
+	case domain.ErrReviewerNotAssigned:
+		return domain.NewDomainError(domain.ErrorCodeNotAssigned, err)
+	case domain.ErrAlreadyAssigned:
+		return domain.NewDomainError(domain.ErrorCodeAlreadyAssigned, err)
+	case domain.ErrTooManyReviewers:
+		return domain.NewDomainError(domain.ErrorCodeTooManyReviewers, err)
+	case domain.ErrVersionMismatch:
+		return domain.NewDomainError(domain.ErrorCodePreconditionFailed, err)
+	}
+
+	return err
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// orgSlugPattern — допустимое короткое имя организации (совпадает с ограничением в базе).
+var orgSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// OrganizationService заводит организации и выбирает, в какой из них выполняется запрос.
+type OrganizationService struct {
+	orgRepo domain.OrganizationRepository
+}
+
+// NewOrganizationService создаёт новый OrganizationService.
+func NewOrganizationService(orgRepo domain.OrganizationRepository) *OrganizationService {
+	return &OrganizationService{orgRepo: orgRepo}
+}
+
+// CreateOrganization заводит организацию; chatTeamID (может быть пустым) связывает её
+// с рабочим пространством чата для slash-команд.
+func (s *OrganizationService) CreateOrganization(
+	ctx context.Context,
+	slug, name, chatTeamID string,
+) (domain.Organization, error) {
+	slug = strings.TrimSpace(slug)
+	name = strings.TrimSpace(name)
+	chatTeamID = strings.TrimSpace(chatTeamID)
+
+	if !orgSlugPattern.MatchString(slug) {
+		return domain.Organization{}, domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("slug must consist of lowercase letters, digits and dashes: %w", domain.ErrInvalidInput))
+	}
+
+	if name == "" {
+		return domain.Organization{}, domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("name is required: %w", domain.ErrInvalidInput))
+	}
+
+	if err := s.checkFree(ctx, s.orgRepo.GetBySlug, slug); err != nil {
+		return domain.Organization{}, err
+	}
+
+	if chatTeamID != "" {
+		if err := s.checkFree(ctx, s.orgRepo.GetByChatTeamID, chatTeamID); err != nil {
+			return domain.Organization{}, err
+		}
+	}
+
+	return s.orgRepo.Create(ctx, domain.Organization{Slug: slug, Name: name, ChatTeamID: chatTeamID})
+}
+
+// checkFree возвращает ORG_EXISTS, если lookup находит организацию по value.
+func (s *OrganizationService) checkFree(
+	ctx context.Context,
+	lookup func(ctx context.Context, value string) (domain.Organization, error),
+	value string,
+) error {
+	_, err := lookup(ctx, value)
+
+	if err == nil {
+		return domain.NewDomainError(domain.ErrorCodeOrgExists,
+			fmt.Errorf("%s is taken: %w", value, domain.ErrOrgExists))
+	}
+
+	if err != domain.ErrNotFound {
+		return err
+	}
+
+	return nil
+}
+
+// ListOrganizations возвращает все организации.
+func (s *OrganizationService) ListOrganizations(ctx context.Context) ([]domain.Organization, error) {
+	return s.orgRepo.List(ctx)
+}
+
+// GetBySlug возвращает организацию по короткому имени (NOT_FOUND, если такой нет).
+func (s *OrganizationService) GetBySlug(ctx context.Context, slug string) (domain.Organization, error) {
+	org, err := s.orgRepo.GetBySlug(ctx, slug)
+
+	if err == domain.ErrNotFound {
+		return domain.Organization{}, domain.NewDomainError(domain.ErrorCodeNotFound,
+			fmt.Errorf("organization %s: %w", slug, err))
+	}
+
+	return org, err
+}
+
+// ChatOrganization возвращает организацию рабочего пространства чата chatTeamID. Запрос из
+// пространства, не связанного ни с одной организацией, запрещён: иначе любое пространство
+// с тем же секретом подписи получило бы доступ к чужим данным.
+func (s *OrganizationService) ChatOrganization(ctx context.Context, chatTeamID string) (int64, error) {
+	if chatTeamID == "" {
+		return 0, domain.NewDomainError(domain.ErrorCodeForbidden,
+			fmt.Errorf("team_id is required: %w", domain.ErrForbidden))
+	}
+
+	org, err := s.orgRepo.GetByChatTeamID(ctx, chatTeamID)
+
+	if err == domain.ErrNotFound {
+		return 0, domain.NewDomainError(domain.ErrorCodeForbidden,
+			fmt.Errorf("chat workspace %s is not linked to an organization: %w", chatTeamID, domain.ErrForbidden))
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return org.ID, nil
+}
+
+// SetChatTeam связывает организацию slug с рабочим пространством чата chatTeamID
+// (пусто — отвязывает).
+func (s *OrganizationService) SetChatTeam(ctx context.Context, slug, chatTeamID string) (domain.Organization, error) {
+	chatTeamID = strings.TrimSpace(chatTeamID)
+
+	org, err := s.GetBySlug(ctx, slug)
+
+	if err != nil {
+		return domain.Organization{}, err
+	}
+
+	if chatTeamID != "" && chatTeamID != org.ChatTeamID {
+		if err := s.checkFree(ctx, s.orgRepo.GetByChatTeamID, chatTeamID); err != nil {
+			return domain.Organization{}, err
+		}
+	}
+
+	updated, err := s.orgRepo.SetChatTeamID(ctx, org.ID, chatTeamID)
+
+	// пространство заняли одновременно с проверкой
+	if err == domain.ErrOrgExists {
+		return domain.Organization{}, domain.NewDomainError(domain.ErrorCodeOrgExists,
+			fmt.Errorf("%s is taken: %w", chatTeamID, err))
+	}
+
+	return updated, err
+}
+
+// ForEach выполняет fn для каждой организации с её контекстом: так фоновые задачи обходят данные
+// всех организаций. Ошибка одной организации не останавливает обработку остальных.
+func (s *OrganizationService) ForEach(ctx context.Context, fn func(ctx context.Context) error) error {
+	orgs, err := s.orgRepo.List(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, org := range orgs {
+		if err := fn(domain.WithOrganization(ctx, org.ID)); err != nil {
+			errs = append(errs, fmt.Errorf("organization %s: %w", org.Slug, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
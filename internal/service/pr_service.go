@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"pr-reviewer-service/internal/domain"
@@ -13,6 +15,8 @@ type PullRequestService struct {
 	prRepo   domain.PullRequestRepository
 	userRepo domain.UserRepository
 	teamRepo domain.TeamRepository
+	ruleRepo domain.ReviewRuleRepository
+	repoRepo domain.RepoRepository
 	rand     random.Rand
 }
 
@@ -21,102 +25,286 @@ func NewPullRequestService(
 	prRepo domain.PullRequestRepository,
 	userRepo domain.UserRepository,
 	teamRepo domain.TeamRepository,
+	ruleRepo domain.ReviewRuleRepository,
+	repoRepo domain.RepoRepository,
 	rand random.Rand,
 ) *PullRequestService {
 	return &PullRequestService{
 		prRepo:   prRepo,
 		userRepo: userRepo,
 		teamRepo: teamRepo,
+		ruleRepo: ruleRepo,
+		repoRepo: repoRepo,
 		rand:     rand,
 	}
 }
 
 // CreatePR создаёт pull request и автоматически назначает ревьюеров.
-func (s *PullRequestService) CreatePR(ctx context.Context, id, name, authorID string) (domain.PullRequest, error) {
+// Сначала применяются правила команды (обязательные и запрещённые ревьюеры),
+// затем оставшиеся места заполняются выбором из остальных кандидатов.
+// При explain объяснение дополняется разбором всех участников команды.
+// PR репозитория repository идентифицируется номером number в нём: его id — "<repository>#<number>".
+func (s *PullRequestService) CreatePR(
+	ctx context.Context,
+	id, name, authorID, repository string,
+	number int,
+	labels []string,
+	explain bool,
+) (domain.PullRequest, domain.AssignmentExplanation, error) {
+	if err := authorizeUser(ctx, s.userRepo, authorID); err != nil {
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+	}
+
+	id, err := pullRequestID(id, repository, number)
+
+	if err != nil {
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+	}
+
+	// быстрый отказ без подбора ревьюверов; одновременные создания различает ограничение
+	// уникальности при вставке
 	exists, err := s.prRepo.PRExists(ctx, id)
 
 	if err != nil {
-		return domain.PullRequest{}, err
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
 	}
 
 	if exists {
-		return domain.PullRequest{}, domain.NewDomainError(domain.ErrorCodePRExists, domain.ErrPRExists)
+		return domain.PullRequest{}, domain.AssignmentExplanation{},
+			domain.NewDomainError(domain.ErrorCodePRExists, domain.ErrPRExists)
 	}
 
-	author, err := s.userRepo.GetByID(ctx, authorID)
+	// PR записывается в одной транзакции со сдвигом курсора ротации, поэтому неудачное создание
+	// (например, PR_EXISTS от конкурентного запроса) не сдвигает очередь
+	plan, err := s.planAssignment(ctx, authorID, repository, labels, explain,
+		func(ctx context.Context, reviewers []string, at time.Time) error {
+			return s.prRepo.Create(ctx, domain.PullRequest{
+				ID:                id,
+				Name:              name,
+				Repository:        repository,
+				Number:            number,
+				AuthorID:          authorID,
+				Status:            domain.PRStatusOpen,
+				AssignedReviewers: reviewers,
+				Labels:            labels,
+				CreatedAt:         &at,
+				MergedAt:          nil,
+			})
+		})
 
 	if err != nil {
-		if err == domain.ErrNotFound {
-			return domain.PullRequest{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		if err == domain.ErrPRExists {
+			return domain.PullRequest{}, domain.AssignmentExplanation{},
+				domain.NewDomainError(domain.ErrorCodePRExists, err)
 		}
 
-		return domain.PullRequest{}, err
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+	}
+
+	created, err := s.prRepo.GetByID(ctx, id)
+
+	if err != nil {
+		return domain.PullRequest{}, domain.AssignmentExplanation{}, err
+	}
+
+	return created, plan.explanation, nil
+}
+
+// pullRequestID возвращает идентификатор нового PR. Для PR репозитория он выводится
+// из репозитория и номера; переданный id должен с ним совпадать или быть пустым.
+func pullRequestID(id, repository string, number int) (string, error) {
+	if repository == "" {
+		if number != 0 {
+			return "", domain.NewDomainError(domain.ErrorCodeValidation,
+				fmt.Errorf("number requires repository: %w", domain.ErrInvalidInput))
+		}
+
+		return id, nil
+	}
+
+	if number <= 0 {
+		return "", domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("number must be positive: %w", domain.ErrInvalidInput))
 	}
 
-	teamName := author.TeamName
+	derived := domain.PullRequestIDFor(repository, number)
 
-	if teamName == "" {
-		return domain.PullRequest{}, domain.NewDomainError(domain.ErrorCodeNotFound, domain.ErrNotFound)
+	if id != "" && id != derived {
+		return "", domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("pull_request_id of %s #%d must be %s: %w", repository, number, derived, domain.ErrInvalidInput))
 	}
 
-	candidates, err := s.userRepo.GetActiveTeamMembersExcept(ctx, teamName, authorID)
+	return derived, nil
+}
+
+// PreviewReviewers показывает, кто был бы назначен на PR автора authorID с метками labels
+// в репозитории repository (может быть пустым) по текущей стратегии команды, ничего не записывая
+// (курсор ротации тоже не сдвигается). Для случайных стратегий фактический выбор при создании может отличаться.
+func (s *PullRequestService) PreviewReviewers(
+	ctx context.Context,
+	authorID, repository string,
+	labels []string,
+	explain bool,
+) ([]string, domain.AssignmentExplanation, error) {
+	plan, err := s.planAssignment(ctx, authorID, repository, labels, explain, nil)
 
 	if err != nil {
-		return domain.PullRequest{}, err
+		return nil, domain.AssignmentExplanation{}, err
 	}
 
-	assigned := chooseReviewers(candidates, 2, s.rand)
+	return plan.reviewers, plan.explanation, nil
+}
+
+// assignmentPlan — ревьюеры, выбранные для нового PR, и объяснение выбора.
+type assignmentPlan struct {
+	reviewers   []string
+	explanation domain.AssignmentExplanation
+	at          time.Time
+}
 
+// planAssignment выбирает ревьюеров для нового PR автора authorID в репозитории repository
+// и передаёт их в persist (в одной транзакции со сдвигом курсора ротации ROUND_ROBIN).
+// Без persist (предпросмотр) курсор только читается.
+func (s *PullRequestService) planAssignment(
+	ctx context.Context,
+	authorID, repository string,
+	labels []string,
+	explain bool,
+	persist func(ctx context.Context, reviewers []string, at time.Time) error,
+) (assignmentPlan, error) {
+	author, err := s.userRepo.GetByID(ctx, authorID)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return assignmentPlan{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return assignmentPlan{}, err
+	}
+
+	if author.TeamName == "" {
+		return assignmentPlan{}, domain.NewDomainError(domain.ErrorCodeNotFound, domain.ErrNotFound)
+	}
+
+	scope, err := s.reviewScopeFor(ctx, repository, author.TeamName)
+
+	if err != nil {
+		return assignmentPlan{}, err
+	}
+
+	teamName := scope.team
 	now := time.Now().UTC()
-	pr := domain.PullRequest{
-		ID:                id,
-		Name:              name,
-		AuthorID:          authorID,
-		Status:            domain.PRStatusOpen,
-		AssignedReviewers: assigned,
-		CreatedAt:         &now,
-		MergedAt:          nil,
+	candidates, err := s.userRepo.GetAvailableTeamMembersExcept(ctx, teamName, authorID, now)
+
+	if err != nil {
+		return assignmentPlan{}, err
 	}
 
-	if err := s.prRepo.Create(ctx, pr); err != nil {
-		return domain.PullRequest{}, err
+	rules, err := s.ruleRepo.ListByTeam(ctx, teamName)
+
+	if err != nil {
+		return assignmentPlan{}, err
 	}
 
-	created, err := s.prRepo.GetByID(ctx, id)
+	// правила применяет applyRules, поэтому здесь проверяются только базовые инварианты
+	policy := newEligibilityPolicy(author, teamName, nil).withAvailability(candidates)
+	candidates = policy.filter(candidates)
+
+	pool, err := s.loadSelectionPool(ctx, scope, authorID, candidates, now)
 
 	if err != nil {
-		return domain.PullRequest{}, err
+		return assignmentPlan{}, err
 	}
 
-	return created, nil
-}
+	var (
+		outcome  ruleOutcome
+		selected []string
+	)
 
-func chooseReviewers(users []domain.User, max int, rand random.Rand) []string {
-	if len(users) == 0 || max <= 0 {
-		return nil
+	var save func(ctx context.Context) error
+
+	if persist != nil {
+		save = func(ctx context.Context) error {
+			return persist(ctx, append(userIDs(outcome.required), selected...), now)
+		}
 	}
 
-	// копируем, чтобы не мутировать исходный слайс
-	tmp := make([]domain.User, len(users))
-	copy(tmp, users)
+	err = s.withRotation(ctx, teamName, &pool, persist == nil, func() ([]string, error) {
+		outcome = applyRules(rules, author, labels, candidates, func(users []domain.User) (domain.User, bool) {
+			return s.pickOne(pool, users)
+		})
 
-	// Fisher–Yates
-	for i := len(tmp) - 1; i > 0; i-- {
-		j := rand.Intn(i + 1)
-		tmp[i], tmp[j] = tmp[j], tmp[i]
+		var err error
+		selected, err = s.pickWithinCapacity(pool, outcome.remaining, pool.settings.MaxReviewers-len(outcome.required))
+
+		// обязательные ревьюеры уже назначены, поэтому политика FAIL не должна отменять создание PR
+		if err != nil && len(outcome.required) > 0 && errors.Is(err, domain.ErrCapacityExceeded) {
+			err = nil
+		}
+
+		return selected, err
+	}, save)
+
+	if err != nil {
+		return assignmentPlan{}, err
 	}
 
-	if len(tmp) > max {
-		tmp = tmp[:max]
+	plan := assignmentPlan{
+		reviewers:   make([]string, 0, pool.settings.MaxReviewers),
+		explanation: domain.AssignmentExplanation{Rules: outcome.decisions, Strategy: pool.settings.Strategy},
+		at:          now,
 	}
 
-	result := make([]string, 0, len(tmp))
+	for _, u := range outcome.required {
+		plan.reviewers = append(plan.reviewers, u.ID)
+		plan.explanation.Reviewers = append(plan.explanation.Reviewers, domain.ReviewerAssignment{
+			UserID: u.ID,
+			Reason: domain.AssignmentReasonRule,
+			RuleID: outcome.requiredBy[u.ID],
+		})
+	}
+
+	for _, id := range selected {
+		plan.reviewers = append(plan.reviewers, id)
+		plan.explanation.Reviewers = append(plan.explanation.Reviewers, domain.ReviewerAssignment{
+			UserID: id,
+			Reason: domain.AssignmentReasonSelected,
+		})
+	}
+
+	if explain {
+		team, err := s.teamRepo.GetTeamWithMembers(ctx, teamName)
+
+		if err != nil {
+			return assignmentPlan{}, err
+		}
+
+		selectedSet := make(map[string]struct{}, len(plan.reviewers))
+
+		for _, id := range plan.reviewers {
+			selectedSet[id] = struct{}{}
+		}
+
+		plan.explanation.Candidates = explainCandidates(candidateInput{
+			members:  team.Members,
+			policy:   policy.withRules(rules, labels),
+			selected: selectedSet,
+			pool:     pool,
+		})
+	}
+
+	return plan, nil
+}
+
+// GetPR возвращает pull request по идентификатору.
+func (s *PullRequestService) GetPR(ctx context.Context, id string) (domain.PullRequest, error) {
+	pr, err := s.prRepo.GetByID(ctx, id)
 
-	for _, u := range tmp {
-		result = append(result, u.ID)
+	if err == domain.ErrNotFound {
+		return domain.PullRequest{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
 	}
 
-	return result
+	return pr, err
 }
 
 // MergePR помечает pull request как merged (идемпотентно).
@@ -131,7 +319,15 @@ func (s *PullRequestService) MergePR(ctx context.Context, id string) (domain.Pul
 		return domain.PullRequest{}, err
 	}
 
+	if err := authorizeUser(ctx, s.userRepo, pr.AuthorID); err != nil {
+		return domain.PullRequest{}, err
+	}
+
 	if pr.Status == domain.PRStatusMerged {
+		if err := domain.CheckVersion(ctx, pr.Version); err != nil {
+			return domain.PullRequest{}, domain.NewDomainError(domain.ErrorCodePreconditionFailed, err)
+		}
+
 		return pr, nil
 	}
 
@@ -139,21 +335,44 @@ func (s *PullRequestService) MergePR(ctx context.Context, id string) (domain.Pul
 	merged, err := s.prRepo.MarkMerged(ctx, id, now)
 
 	if err != nil {
-		if err == domain.ErrNotFound {
-			return domain.PullRequest{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
-		}
+		return domain.PullRequest{}, mapReviewerChangeError(err)
+	}
 
+	return merged, nil
+}
+
+// MarkReviewed отмечает, что ревьювер взялся за ревью PR: после этого назначение
+// не считается просроченным по SLA команды.
+func (s *PullRequestService) MarkReviewed(ctx context.Context, prID, reviewerID string) (domain.PullRequest, error) {
+	pr, author, err := s.loadOpenPR(ctx, prID)
+
+	if err != nil {
 		return domain.PullRequest{}, err
 	}
 
-	return merged, nil
+	if err := authorizeTeam(ctx, s.userRepo, author.TeamName); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	// отметка ревью не меняет версию PR, но устаревший If-Match всё равно отклоняется
+	if err := domain.CheckVersion(ctx, pr.Version); err != nil {
+		return domain.PullRequest{}, domain.NewDomainError(domain.ErrorCodePreconditionFailed, err)
+	}
+
+	if err := s.prRepo.MarkReviewed(ctx, prID, reviewerID, time.Now().UTC()); err != nil {
+		return domain.PullRequest{}, mapReviewerChangeError(err)
+	}
+
+	return pr, nil
 }
 
 // ReassignReviewer переназначает ревьюера в pull request на другого активного участника команды.
+// Если newReviewerID задан, замена не выбирается по стратегии, а проверяется так же,
+// как при ручном назначении (активен, из команды автора, не автор, ещё не назначен).
 // nolint:gocyclo
 func (s *PullRequestService) ReassignReviewer(
 	ctx context.Context,
-	prID, oldReviewerID string,
+	prID, oldReviewerID, newReviewerID string,
 ) (pr domain.PullRequest, replacedBy string, err error) {
 	pr, err = s.prRepo.GetByID(ctx, prID)
 
@@ -165,6 +384,13 @@ func (s *PullRequestService) ReassignReviewer(
 		return
 	}
 
+	// ревьюер может передать своё ревью сам, даже если PR из другой команды (команда ревьюверов репозитория)
+	if !isCaller(ctx, oldReviewerID) {
+		if err = authorizeUser(ctx, s.userRepo, pr.AuthorID); err != nil {
+			return
+		}
+	}
+
 	// ensure user exists
 	if _, uerr := s.userRepo.GetByID(ctx, oldReviewerID); uerr != nil {
 		if uerr == domain.ErrNotFound {
@@ -182,11 +408,8 @@ func (s *PullRequestService) ReassignReviewer(
 	}
 
 	isAssigned := false
-	assignedSet := make(map[string]struct{}, len(pr.AssignedReviewers))
 
 	for _, id := range pr.AssignedReviewers {
-		assignedSet[id] = struct{}{}
-
 		if id == oldReviewerID {
 			isAssigned = true
 		}
@@ -197,50 +420,149 @@ func (s *PullRequestService) ReassignReviewer(
 		return
 	}
 
-	teamName, err := s.userRepo.GetTeamByUserID(ctx, oldReviewerID)
+	if newReviewerID != "" {
+		return s.reassignTo(ctx, pr, oldReviewerID, newReviewerID)
+	}
+
+	var updated domain.PullRequest
+
+	newReviewer, err := s.pickReplacement(ctx, pr, oldReviewerID, func(ctx context.Context, replacement string) error {
+		var err error
+		updated, err = s.prRepo.ReassignReviewer(ctx, prID, oldReviewerID, replacement)
+		return err
+	})
+
+	if err != nil {
+		err = mapReviewerChangeError(err)
+		return
+	}
+
+	return updated, newReviewer, nil
+}
+
+// pickReplacement выбирает замену ревьюеру oldReviewerID из его команды (или из команды ревьюверов
+// репозитория PR) по стратегии команды с учётом ёмкости; NO_CANDIDATE — если заменить некем.
+// Выбранная замена сохраняется через persist: для ROUND_ROBIN — в одной транзакции со сдвигом курсора,
+// чтобы неудачная запись (устаревший If-Match, слитый PR) не сдвигала очередь.
+func (s *PullRequestService) pickReplacement(
+	ctx context.Context,
+	pr domain.PullRequest,
+	oldReviewerID string,
+	persist func(ctx context.Context, replacement string) error,
+) (string, error) {
+	reviewerTeam, err := s.userRepo.GetTeamByUserID(ctx, oldReviewerID)
 
 	if err != nil {
 		if err == domain.ErrNotFound {
-			err = domain.NewDomainError(domain.ErrorCodeNotFound, err)
+			return "", domain.NewDomainError(domain.ErrorCodeNotFound, err)
 		}
 
-		return
+		return "", err
 	}
 
-	candidates, err := s.userRepo.GetActiveTeamMembersExcept(ctx, teamName, oldReviewerID)
+	scope, err := s.reviewScopeFor(ctx, pr.Repository, reviewerTeam)
+
 	if err != nil {
-		return
+		return "", err
 	}
 
-	// исключаем уже назначенных ревьюверов
-	filtered := make([]domain.User, 0, len(candidates))
+	teamName := scope.team
+	now := time.Now().UTC()
+	candidates, err := s.userRepo.GetAvailableTeamMembersExcept(ctx, teamName, oldReviewerID, now)
 
-	for _, c := range candidates {
-		if _, ok := assignedSet[c.ID]; ok {
-			continue
-		}
+	if err != nil {
+		return "", err
+	}
+
+	rules, err := s.ruleRepo.ListByTeam(ctx, teamName)
 
-		filtered = append(filtered, c)
+	if err != nil {
+		return "", err
 	}
 
-	if len(filtered) == 0 {
-		err = domain.NewDomainError(domain.ErrorCodeNoCandidate, domain.ErrNoCandidate)
-		return
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+
+	if err != nil {
+		return "", err
 	}
 
-	// выбираем случайного кандидата
-	idx := s.rand.Intn(len(filtered))
-	newReviewer := filtered[idx].ID
+	declined, err := s.prRepo.ListDecliners(ctx, pr.ID)
 
-	updated, err := s.prRepo.ReassignReviewer(ctx, prID, oldReviewerID, newReviewer)
+	if err != nil {
+		return "", err
+	}
+
+	// исключаем автора, уже назначенных и отказавшихся ревьюверов и запрещённых правилами команды
+	filtered := newEligibilityPolicy(author, teamName, pr.AssignedReviewers).
+		withAvailability(candidates).
+		withDeclined(declined).
+		withRules(rules, pr.Labels).
+		filter(candidates)
+
+	if len(filtered) == 0 {
+		return "", domain.NewDomainError(domain.ErrorCodeNoCandidate, domain.ErrNoCandidate)
+	}
+
+	pool, err := s.loadSelectionPool(ctx, scope, pr.AuthorID, filtered, now)
 
 	if err != nil {
-		if err == domain.ErrReviewerNotAssigned {
-			err = domain.NewDomainError(domain.ErrorCodeNotAssigned, err)
+		return "", err
+	}
+
+	// выбираем кандидата по стратегии команды с учётом ёмкости
+	var replacement string
+
+	err = s.withRotation(ctx, teamName, &pool, false, func() ([]string, error) {
+		picked, err := s.pickWithinCapacity(pool, filtered, 1)
+
+		if err == nil && len(picked) == 0 {
+			err = domain.NewDomainError(domain.ErrorCodeNoCandidate, domain.ErrNoCandidate)
 		}
 
-		return
+		if err != nil {
+			return nil, err
+		}
+
+		replacement = picked[0]
+		return picked, nil
+	}, func(ctx context.Context) error {
+		return persist(ctx, replacement)
+	})
+
+	if err != nil {
+		return "", err
 	}
 
-	return updated, newReviewer, nil
+	return replacement, nil
+}
+
+// reassignTo заменяет oldReviewerID на явно указанного newReviewerID.
+func (s *PullRequestService) reassignTo(
+	ctx context.Context,
+	pr domain.PullRequest,
+	oldReviewerID, newReviewerID string,
+) (domain.PullRequest, string, error) {
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+
+	if err != nil {
+		return domain.PullRequest{}, "", err
+	}
+
+	scope, err := s.reviewScopeFor(ctx, pr.Repository, author.TeamName)
+
+	if err != nil {
+		return domain.PullRequest{}, "", err
+	}
+
+	if err := s.checkManualReviewer(ctx, pr, author, scope, newReviewerID); err != nil {
+		return domain.PullRequest{}, "", err
+	}
+
+	updated, err := s.prRepo.ReassignReviewer(ctx, pr.ID, oldReviewerID, newReviewerID)
+
+	if err != nil {
+		return domain.PullRequest{}, "", mapReviewerChangeError(err)
+	}
+
+	return updated, newReviewerID, nil
 }
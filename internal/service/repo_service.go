@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// repoNamePattern — допустимое имя репозитория: без пробелов и '#', который отделяет номер PR.
+var repoNamePattern = regexp.MustCompile(`^[^\s#]+$`)
+
+// RepoService управляет репозиториями кода команд.
+type RepoService struct {
+	repoRepo domain.RepoRepository
+	teamRepo domain.TeamRepository
+	userRepo domain.UserRepository
+}
+
+// NewRepoService создаёт новый RepoService.
+func NewRepoService(
+	repoRepo domain.RepoRepository,
+	teamRepo domain.TeamRepository,
+	userRepo domain.UserRepository,
+) *RepoService {
+	return &RepoService{
+		repoRepo: repoRepo,
+		teamRepo: teamRepo,
+		userRepo: userRepo,
+	}
+}
+
+// AddRepository заводит репозиторий команды repo.TeamName. Тимлид может завести репозиторий
+// только своей команде и отдать его ревью только своей команде.
+func (s *RepoService) AddRepository(ctx context.Context, repo domain.Repo) (domain.Repo, error) {
+	repo.Name = strings.TrimSpace(repo.Name)
+
+	if !repoNamePattern.MatchString(repo.Name) {
+		return domain.Repo{}, domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("name must be non-empty and contain no spaces or '#': %w", domain.ErrInvalidInput))
+	}
+
+	if !repo.CodeHost.Valid() {
+		return domain.Repo{}, domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("unknown code_host %q: %w", repo.CodeHost, domain.ErrInvalidInput))
+	}
+
+	if err := s.checkTeam(ctx, repo.TeamName); err != nil {
+		return domain.Repo{}, err
+	}
+
+	if err := s.checkOverrides(ctx, repo, true); err != nil {
+		return domain.Repo{}, err
+	}
+
+	_, err := s.repoRepo.GetByName(ctx, repo.Name)
+
+	if err == nil {
+		return domain.Repo{}, domain.NewDomainError(domain.ErrorCodeRepositoryExists,
+			fmt.Errorf("%s: %w", repo.Name, domain.ErrRepositoryExists))
+	}
+
+	if err != domain.ErrNotFound {
+		return domain.Repo{}, err
+	}
+
+	return s.repoRepo.Create(ctx, repo)
+}
+
+// GetRepository возвращает репозиторий по имени.
+func (s *RepoService) GetRepository(ctx context.Context, name string) (domain.Repo, error) {
+	repo, err := s.repoRepo.GetByName(ctx, name)
+
+	if err == domain.ErrNotFound {
+		return domain.Repo{}, domain.NewDomainError(domain.ErrorCodeNotFound,
+			fmt.Errorf("repository %s: %w", name, err))
+	}
+
+	return repo, err
+}
+
+// ListRepositories возвращает репозитории команды teamName (пусто — всех команд).
+func (s *RepoService) ListRepositories(ctx context.Context, teamName string) ([]domain.Repo, error) {
+	return s.repoRepo.List(ctx, teamName)
+}
+
+// SetOverrides меняет команду ревьюверов и стратегию выбора репозитория name.
+func (s *RepoService) SetOverrides(
+	ctx context.Context,
+	name string,
+	update domain.RepoOverridesUpdate,
+) (domain.Repo, error) {
+	current, err := s.GetRepository(ctx, name)
+
+	if err != nil {
+		return domain.Repo{}, err
+	}
+
+	if err := authorizeTeam(ctx, s.userRepo, current.TeamName); err != nil {
+		return domain.Repo{}, err
+	}
+
+	repo := update.Apply(current)
+
+	if err := s.checkOverrides(ctx, repo, update.ReviewerTeam.Set); err != nil {
+		return domain.Repo{}, err
+	}
+
+	return s.repoRepo.UpdateOverrides(ctx, repo)
+}
+
+// checkOverrides проверяет переопределённую в репозитории стратегию и, если reviewerTeamChanged,
+// команду ревьюверов.
+func (s *RepoService) checkOverrides(ctx context.Context, repo domain.Repo, reviewerTeamChanged bool) error {
+	if repo.Strategy != "" && !repo.Strategy.Valid() {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("unknown selection_strategy %q: %w", repo.Strategy, domain.ErrInvalidInput))
+	}
+
+	if !reviewerTeamChanged || repo.ReviewerTeam == "" {
+		return nil
+	}
+
+	return s.checkTeam(ctx, repo.ReviewerTeam)
+}
+
+// checkTeam проверяет, что команда teamName существует и вызывающий может ей управлять.
+func (s *RepoService) checkTeam(ctx context.Context, teamName string) error {
+	if teamName == "" {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("team_name is required: %w", domain.ErrInvalidInput))
+	}
+
+	if err := authorizeTeam(ctx, s.userRepo, teamName); err != nil {
+		return err
+	}
+
+	exists, err := s.teamRepo.TeamExists(ctx, teamName)
+
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return domain.NewDomainError(domain.ErrorCodeNotFound,
+			fmt.Errorf("team %s: %w", teamName, domain.ErrNotFound))
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// DeclineReview снимает ревьюера reviewerID с PR по его собственному отказу с причиной reason
+// и подбирает замену так же, как при переназначении. Если заменить некем (нет кандидатов
+// или у всех исчерпана ёмкость), отказ всё равно принимается, а replacedBy остаётся пустым.
+// Отказаться может только сам ревьюер (в том числе из команды ревьюверов репозитория);
+// ADMIN и BOT отказываются по его поручению, и в истории записывается вызывающий.
+func (s *PullRequestService) DeclineReview(
+	ctx context.Context,
+	prID, reviewerID, reason string,
+) (pr domain.PullRequest, replacedBy string, err error) {
+	reason = strings.TrimSpace(reason)
+
+	if reason == "" {
+		err = domain.NewDomainError(domain.ErrorCodeValidation, fmt.Errorf("reason is required: %w", domain.ErrInvalidInput))
+		return
+	}
+
+	pr, _, err = s.loadOpenPR(ctx, prID)
+
+	if err != nil {
+		return
+	}
+
+	actorID, err := s.declineActor(ctx, reviewerID)
+
+	if err != nil {
+		return
+	}
+
+	isAssigned := false
+
+	for _, id := range pr.AssignedReviewers {
+		if id == reviewerID {
+			isAssigned = true
+		}
+	}
+
+	if !isAssigned {
+		err = domain.NewDomainError(domain.ErrorCodeNotAssigned, domain.ErrReviewerNotAssigned)
+		return
+	}
+
+	decline := func(ctx context.Context, replacement string) error {
+		var err error
+		pr, err = s.prRepo.DeclineReview(ctx, domain.ReviewDecline{
+			PRID:       prID,
+			ReviewerID: reviewerID,
+			Reason:     reason,
+			ReplacedBy: replacement,
+			ActorID:    actorID,
+		})
+
+		return err
+	}
+
+	// отказ с заменой сохраняется вместе со сдвигом курсора ротации; без замены курсор не сдвигается
+	replacedBy, err = s.pickReplacement(ctx, pr, reviewerID, decline)
+
+	if errors.Is(err, domain.ErrNoCandidate) || errors.Is(err, domain.ErrCapacityExceeded) {
+		replacedBy, err = "", decline(ctx, "")
+	}
+
+	if err != nil {
+		err = mapReviewerChangeError(err)
+		return
+	}
+
+	return pr, replacedBy, nil
+}
+
+// declineActor проверяет, что вызывающий может отказаться от ревью за reviewerID, и возвращает,
+// от чьего имени записать отказ: вызывающего или, если он не привязан к пользователю, самого ревьюера.
+func (s *PullRequestService) declineActor(ctx context.Context, reviewerID string) (string, error) {
+	if caller, ok := domain.CallerFrom(ctx); ok && !isCaller(ctx, reviewerID) &&
+		!caller.HasRole(domain.RoleAdmin, domain.RoleBot) {
+		return "", domain.NewDomainError(domain.ErrorCodeForbidden,
+			fmt.Errorf("only reviewer %s may decline the review: %w", reviewerID, domain.ErrForbidden))
+	}
+
+	actorID, err := s.resolveActor(ctx, "")
+
+	if err != nil || actorID != "" {
+		return actorID, err
+	}
+
+	return reviewerID, nil
+}
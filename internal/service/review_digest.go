@@ -0,0 +1,216 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+//go:embed templates/digest.txt.tmpl templates/digest.html.tmpl
+var digestTemplates embed.FS
+
+var (
+	digestFuncs = map[string]any{"join": strings.Join}
+
+	digestText = texttemplate.Must(texttemplate.New("digest.txt.tmpl").
+			Funcs(digestFuncs).ParseFS(digestTemplates, "templates/digest.txt.tmpl"))
+	digestHTML = htmltemplate.Must(htmltemplate.New("digest.html.tmpl").
+			Funcs(digestFuncs).ParseFS(digestTemplates, "templates/digest.html.tmpl"))
+)
+
+// DigestService рассылает ежедневную сводку по ревью: какие PR ждут ревью пользователя
+// и какие его PR ждут других. Сводка уходит не раньше sendHour по часовому поясу пользователя
+// и не чаще раза в его календарные сутки; пустые сводки не отправляются.
+type DigestService struct {
+	prRepo   domain.PullRequestRepository
+	userRepo domain.UserRepository
+	mailer   domain.Mailer
+	sendHour int
+}
+
+// NewDigestService создаёт новый DigestService.
+func NewDigestService(
+	prRepo domain.PullRequestRepository,
+	userRepo domain.UserRepository,
+	mailer domain.Mailer,
+	sendHour int,
+) *DigestService {
+	return &DigestService{
+		prRepo:   prRepo,
+		userRepo: userRepo,
+		mailer:   mailer,
+		sendHour: sendHour,
+	}
+}
+
+// Run отправляет сводки, которым подошло время на момент at, и возвращает число отправленных писем.
+func (s *DigestService) Run(ctx context.Context, at time.Time) (int, error) {
+	users, err := s.userRepo.ListDigestRecipients(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+
+	for _, u := range users {
+		if !s.due(u, at) {
+			continue
+		}
+
+		digest, err := s.Build(ctx, u, at)
+
+		if err != nil {
+			return sent, err
+		}
+
+		if digest.Empty() {
+			continue
+		}
+
+		msg, err := renderDigest(digest)
+
+		if err != nil {
+			return sent, err
+		}
+
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			return sent, err
+		}
+
+		if err := s.userRepo.MarkDigestSent(ctx, u.ID, at); err != nil {
+			return sent, err
+		}
+
+		sent++
+	}
+
+	return sent, nil
+}
+
+// due сообщает, пора ли отправлять сводку пользователю: по его времени уже sendHour или позже,
+// и сегодня (по его календарю) сводка ещё не отправлялась.
+func (s *DigestService) due(u domain.User, at time.Time) bool {
+	local := at.In(u.Location())
+
+	if local.Hour() < s.sendHour {
+		return false
+	}
+
+	if u.DigestLastSentAt == nil {
+		return true
+	}
+
+	last := u.DigestLastSentAt.In(u.Location())
+
+	return last.YearDay() != local.YearDay() || last.Year() != local.Year()
+}
+
+// Build собирает сводку пользователя на момент at.
+func (s *DigestService) Build(ctx context.Context, u domain.User, at time.Time) (domain.ReviewDigest, error) {
+	digest := domain.ReviewDigest{User: u, At: at}
+
+	prs, err := s.prRepo.ListByReviewer(ctx, u.ID)
+
+	if err != nil {
+		return digest, err
+	}
+
+	for _, pr := range prs {
+		if pr.Status == domain.PRStatusOpen {
+			digest.Pending = append(digest.Pending, pr)
+		}
+	}
+
+	digest.Awaiting, err = s.prRepo.ListAwaitingByAuthor(ctx, u.ID)
+
+	if err != nil {
+		return digest, err
+	}
+
+	return digest, nil
+}
+
+// digestItem — строка сводки в шаблоне.
+type digestItem struct {
+	ID        string
+	Name      string
+	AuthorID  string
+	Age       string
+	Reviewers []string
+}
+
+// renderDigest формирует письмо по встроенным шаблонам.
+func renderDigest(d domain.ReviewDigest) (domain.EmailMessage, error) {
+	data := struct {
+		Name     string
+		Date     string
+		Pending  []digestItem
+		Awaiting []digestItem
+	}{
+		Name: d.User.Username,
+		Date: d.At.In(d.User.Location()).Format("02.01.2006"),
+	}
+
+	if data.Name == "" {
+		data.Name = d.User.ID
+	}
+
+	for _, pr := range d.Pending {
+		data.Pending = append(data.Pending, digestItem{
+			ID: pr.ID, Name: pr.Name, AuthorID: pr.AuthorID, Age: formatAge(pr.CreatedAt, d.At),
+		})
+	}
+
+	for _, a := range d.Awaiting {
+		data.Awaiting = append(data.Awaiting, digestItem{
+			ID: a.PR.ID, Name: a.PR.Name, Age: formatAge(a.PR.CreatedAt, d.At), Reviewers: a.Reviewers,
+		})
+	}
+
+	var subject, text, html bytes.Buffer
+
+	if err := digestText.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return domain.EmailMessage{}, fmt.Errorf("render digest subject: %w", err)
+	}
+
+	if err := digestText.Execute(&text, data); err != nil {
+		return domain.EmailMessage{}, fmt.Errorf("render digest text: %w", err)
+	}
+
+	if err := digestHTML.Execute(&html, data); err != nil {
+		return domain.EmailMessage{}, fmt.Errorf("render digest html: %w", err)
+	}
+
+	return domain.EmailMessage{
+		To:      d.User.Email,
+		Subject: subject.String(),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// formatAge возвращает возраст PR в днях или часах (пусто, если время создания неизвестно).
+func formatAge(createdAt *time.Time, at time.Time) string {
+	if createdAt == nil {
+		return ""
+	}
+
+	age := at.Sub(*createdAt)
+
+	switch {
+	case age >= 24*time.Hour:
+		return fmt.Sprintf("%d дн. назад", int(age/(24*time.Hour)))
+	case age >= time.Hour:
+		return fmt.Sprintf("%d ч назад", int(age/time.Hour))
+	default:
+		return "меньше часа назад"
+	}
+}
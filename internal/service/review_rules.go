@@ -0,0 +1,167 @@
+package service
+
+import "pr-reviewer-service/internal/domain"
+
+// ruleOutcome — результат применения правил команды к пулу кандидатов.
+type ruleOutcome struct {
+	// required — обязательные ревьюеры в порядке срабатывания правил.
+	required []domain.User
+	// requiredBy — какое правило сделало ревьюера обязательным.
+	requiredBy map[string]int64
+	// remaining — кандидаты, из которых стратегия добирает оставшиеся места.
+	remaining []domain.User
+	decisions []domain.RuleDecision
+}
+
+// applyRules применяет правила команды к кандидатам до основного выбора ревьюеров.
+// Исключения сильнее требований: исключённый пользователь не назначается, даже если он обязателен.
+// Обязательный ревьюер, которого нет среди кандидатов (неактивен, отсутствует, автор), отмечается
+// как UNSATISFIED. Для правил по уровню ревьювера одного подходящего кандидата выбирает pickOne;
+// правило считается выполненным, если такой ревьюер уже стал обязательным по другому правилу.
+func applyRules(
+	rules []domain.ReviewRule,
+	author domain.User,
+	labels []string,
+	candidates []domain.User,
+	pickOne func([]domain.User) (domain.User, bool),
+) ruleOutcome {
+	out := ruleOutcome{requiredBy: make(map[string]int64)}
+
+	byID := make(map[string]domain.User, len(candidates))
+
+	for _, c := range candidates {
+		byID[c.ID] = c
+	}
+
+	excluded := excludedByRules(rules, author, labels, candidates)
+
+	for _, rule := range rules {
+		if rule.Kind != domain.RuleKindExclude || !rule.Matches(author, labels) {
+			continue
+		}
+
+		for _, c := range candidates {
+			if rule.Targets(c) && excluded[c.ID] == rule.ID {
+				out.decisions = append(out.decisions, decisionFor(rule, c.ID, domain.RuleOutcomeExcluded))
+			}
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Kind != domain.RuleKindRequire || !rule.Matches(author, labels) {
+			continue
+		}
+
+		if rule.ReviewerLevel != "" {
+			out.requireLevel(rule, candidates, excluded, pickOne)
+			continue
+		}
+
+		if _, ok := out.requiredBy[rule.ReviewerID]; ok {
+			continue
+		}
+
+		u, ok := byID[rule.ReviewerID]
+
+		if _, isExcluded := excluded[rule.ReviewerID]; !ok || isExcluded {
+			out.decisions = append(out.decisions, decisionFor(rule, rule.ReviewerID, domain.RuleOutcomeUnsatisfied))
+			continue
+		}
+
+		out.require(rule, u)
+	}
+
+	for _, c := range candidates {
+		if _, ok := excluded[c.ID]; ok {
+			continue
+		}
+
+		if _, ok := out.requiredBy[c.ID]; ok {
+			continue
+		}
+
+		out.remaining = append(out.remaining, c)
+	}
+
+	return out
+}
+
+// requireLevel выполняет правило «нужен хотя бы один ревьюер уровня ReviewerLevel».
+func (out *ruleOutcome) requireLevel(
+	rule domain.ReviewRule,
+	candidates []domain.User,
+	excluded map[string]int64,
+	pickOne func([]domain.User) (domain.User, bool),
+) {
+	for _, u := range out.required {
+		if rule.Targets(u) {
+			out.decisions = append(out.decisions, decisionFor(rule, u.ID, domain.RuleOutcomeRequired))
+			return
+		}
+	}
+
+	var eligible []domain.User
+
+	for _, c := range candidates {
+		if _, ok := excluded[c.ID]; ok || !rule.Targets(c) {
+			continue
+		}
+
+		eligible = append(eligible, c)
+	}
+
+	u, ok := pickOne(eligible)
+
+	if !ok {
+		out.decisions = append(out.decisions, decisionFor(rule, "", domain.RuleOutcomeUnsatisfied))
+		return
+	}
+
+	out.require(rule, u)
+}
+
+func (out *ruleOutcome) require(rule domain.ReviewRule, u domain.User) {
+	out.required = append(out.required, u)
+	out.requiredBy[u.ID] = rule.ID
+	out.decisions = append(out.decisions, decisionFor(rule, u.ID, domain.RuleOutcomeRequired))
+}
+
+// excludedByRules возвращает пользователей из users, которым правила EXCLUDE запрещают ревьюить этот PR,
+// вместе с идентификатором первого сработавшего правила.
+func excludedByRules(
+	rules []domain.ReviewRule,
+	author domain.User,
+	labels []string,
+	users []domain.User,
+) map[string]int64 {
+	res := make(map[string]int64)
+
+	for _, rule := range rules {
+		if rule.Kind != domain.RuleKindExclude || !rule.Matches(author, labels) {
+			continue
+		}
+
+		for _, u := range users {
+			if !rule.Targets(u) {
+				continue
+			}
+
+			if _, ok := res[u.ID]; !ok {
+				res[u.ID] = rule.ID
+			}
+		}
+	}
+
+	return res
+}
+
+func decisionFor(rule domain.ReviewRule, userID string, outcome domain.RuleOutcome) domain.RuleDecision {
+	return domain.RuleDecision{
+		RuleID:        rule.ID,
+		Kind:          rule.Kind,
+		UserID:        userID,
+		ReviewerLevel: rule.ReviewerLevel,
+		Outcome:       outcome,
+		Description:   rule.Description,
+	}
+}
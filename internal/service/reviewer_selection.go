@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/random"
+)
+
+// selectionPool — всё, что нужно стратегии, чтобы выбрать ревьюеров на конкретный PR.
+type selectionPool struct {
+	settings domain.TeamSettings
+	// loads — число открытых ревью у каждого кандидата.
+	loads map[string]int
+	// pairings — сколько раз кандидат ревьюил автора за окно PairingWindowDays.
+	pairings map[string]int
+	at       time.Time
+	// cursor — последний выбранный по ротации ревьюер (только для ROUND_ROBIN).
+	cursor string
+}
+
+// reviewScope — команда, из которой подбираются ревьюеры PR, и стратегия, переопределённая
+// репозиторием PR (пусто — стратегия команды).
+type reviewScope struct {
+	team     string
+	strategy domain.SelectionStrategy
+}
+
+// reviewScopeFor возвращает область подбора ревьюеров PR репозитория repository (пусто — PR
+// без репозитория): репозиторий может заменить команду defaultTeam своей командой ревьюверов.
+func (s *PullRequestService) reviewScopeFor(ctx context.Context, repository, defaultTeam string) (reviewScope, error) {
+	scope := reviewScope{team: defaultTeam}
+
+	if repository == "" {
+		return scope, nil
+	}
+
+	repo, err := s.repoRepo.GetByName(ctx, repository)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return reviewScope{}, domain.NewDomainError(domain.ErrorCodeNotFound,
+				fmt.Errorf("repository %s: %w", repository, err))
+		}
+
+		return reviewScope{}, err
+	}
+
+	if repo.ReviewerTeam != "" {
+		scope.team = repo.ReviewerTeam
+	}
+
+	scope.strategy = repo.Strategy
+	return scope, nil
+}
+
+// loadSelectionPool загружает настройки команды scope, текущую загрузку кандидатов и историю их пар с автором.
+func (s *PullRequestService) loadSelectionPool(
+	ctx context.Context,
+	scope reviewScope,
+	authorID string,
+	candidates []domain.User,
+	at time.Time,
+) (selectionPool, error) {
+	settings, err := s.teamRepo.GetSettings(ctx, scope.team)
+
+	if err != nil {
+		return selectionPool{}, err
+	}
+
+	if scope.strategy != "" {
+		settings.Strategy = scope.strategy
+	}
+
+	pool := selectionPool{settings: settings, at: at}
+
+	if len(candidates) == 0 {
+		return pool, nil
+	}
+
+	ids := make([]string, 0, len(candidates))
+
+	for _, c := range candidates {
+		ids = append(ids, c.ID)
+	}
+
+	pool.loads, err = s.prRepo.CountOpenReviews(ctx, ids)
+
+	if err != nil {
+		return selectionPool{}, err
+	}
+
+	if settings.PairingWindowDays > 0 {
+		since := at.AddDate(0, 0, -settings.PairingWindowDays)
+		pool.pairings, err = s.prRepo.CountPairings(ctx, authorID, ids, since)
+
+		if err != nil {
+			return selectionPool{}, err
+		}
+	}
+
+	return pool, nil
+}
+
+// pick выбирает до n ревьюеров из users по стратегии команды.
+// В режиме PreferWorkingHours сначала выбираются те, кто в момент at находится в рабочих часах,
+// а внутри каждой группы предпочтение отдаётся тем, кто реже ревьюил автора.
+func (s *PullRequestService) pick(pool selectionPool, users []domain.User, n int) []string {
+	if !pool.settings.PreferWorkingHours {
+		return chooseReviewers(users, n, s.rand, pool)
+	}
+
+	return chooseReviewersPreferring(users, n, s.rand, pool, func(u domain.User) bool {
+		return u.InWorkingHours(pool.at)
+	})
+}
+
+// withRotation выполняет выбор choose; для стратегии ROUND_ROBIN — под блокировкой курсора команды,
+// после чего курсор сдвигается на последнего выбранного по очереди ревьюера.
+// При dryRun курсор только читается. choose возвращает ревьюеров, выбранных стратегией
+// (без обязательных по правилам). persist (может быть nil) сохраняет результат выбора: для ROUND_ROBIN —
+// в одной транзакции со сдвигом курсора, чтобы курсор не сдвигался, если сохранить не удалось.
+func (s *PullRequestService) withRotation(
+	ctx context.Context,
+	teamName string,
+	pool *selectionPool,
+	dryRun bool,
+	choose func() ([]string, error),
+	persist func(ctx context.Context) error,
+) error {
+	if persist == nil {
+		persist = func(context.Context) error { return nil }
+	}
+
+	if pool.settings.Strategy != domain.SelectionStrategyRoundRobin {
+		if _, err := choose(); err != nil {
+			return err
+		}
+
+		return persist(ctx)
+	}
+
+	if dryRun {
+		cursor, err := s.teamRepo.GetRoundRobinCursor(ctx, teamName)
+
+		if err != nil {
+			return err
+		}
+
+		pool.cursor = cursor
+
+		if _, err := choose(); err != nil {
+			return err
+		}
+
+		return persist(ctx)
+	}
+
+	return s.prRepo.WithTx(ctx, func(ctx context.Context, _ *sql.Tx) error {
+		err := s.teamRepo.AdvanceRoundRobin(ctx, teamName, func(cursor string) (string, error) {
+			pool.cursor = cursor
+			selected, err := choose()
+
+			if err != nil {
+				return "", err
+			}
+
+			return lastInRotation(cursor, selected), nil
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return persist(ctx)
+	})
+}
+
+// userIDs возвращает идентификаторы пользователей users в том же порядке.
+func userIDs(users []domain.User) []string {
+	res := make([]string, 0, len(users))
+
+	for _, u := range users {
+		res = append(res, u.ID)
+	}
+
+	return res
+}
+
+// pickOne выбирает одного ревьюера, по возможности из тех, у кого есть свободная ёмкость.
+func (s *PullRequestService) pickOne(pool selectionPool, users []domain.User) (domain.User, bool) {
+	free, full := splitByCapacity(users, pool.loads, pool.settings)
+
+	if len(free) == 0 {
+		free = full
+	}
+
+	ids := s.pick(pool, free, 1)
+
+	if len(ids) == 0 {
+		return domain.User{}, false
+	}
+
+	for _, u := range free {
+		if u.ID == ids[0] {
+			return u, true
+		}
+	}
+
+	return domain.User{}, false
+}
+
+// pickWithinCapacity выбирает до max ревьюеров, пропуская тех, кто уже достиг лимита открытых ревью.
+// Если свободных кандидатов не хватает, поведение определяется политикой ёмкости команды.
+func (s *PullRequestService) pickWithinCapacity(pool selectionPool, candidates []domain.User, max int) ([]string, error) {
+	if len(candidates) == 0 || max <= 0 {
+		return nil, nil
+	}
+
+	free, full := splitByCapacity(candidates, pool.loads, pool.settings)
+	assigned := s.pick(pool, free, max)
+
+	if len(assigned) >= max || len(full) == 0 {
+		return assigned, nil
+	}
+
+	switch pool.settings.CapacityPolicy {
+	case domain.CapacityPolicyAssignAnyway:
+		assigned = append(assigned, s.pick(pool, full, max-len(assigned))...)
+
+	case domain.CapacityPolicyFail:
+		if len(assigned) == 0 {
+			return nil, domain.NewDomainError(domain.ErrorCodeCapacityExceeded, domain.ErrCapacityExceeded)
+		}
+	}
+
+	return assigned, nil
+}
+
+// chooseReviewers выбирает до max ревьюеров в порядке, заданном стратегией: случайном,
+// взвешенно-случайном или по очереди от курсора. Для случайных стратегий при наличии истории пар
+// (сколько раз кандидат ревьюил автора) сначала выбираются кандидаты с меньшим числом пар,
+// а при равенстве сохраняется порядок стратегии. Ротация детерминирована и историю пар не учитывает.
+func chooseReviewers(users []domain.User, max int, rand random.Rand, pool selectionPool) []string {
+	if len(users) == 0 || max <= 0 {
+		return nil
+	}
+
+	// копируем, чтобы не мутировать исходный слайс
+	tmp := make([]domain.User, len(users))
+	copy(tmp, users)
+
+	switch pool.settings.Strategy {
+	case domain.SelectionStrategyRoundRobin:
+		rotate(tmp, pool.cursor)
+
+	case domain.SelectionStrategyWeighted:
+		weightedShuffle(tmp, rand)
+
+	default:
+		shuffle(tmp, rand)
+	}
+
+	if len(pool.pairings) > 0 && pool.settings.Strategy != domain.SelectionStrategyRoundRobin {
+		sort.SliceStable(tmp, func(i, j int) bool {
+			return pool.pairings[tmp[i].ID] < pool.pairings[tmp[j].ID]
+		})
+	}
+
+	if len(tmp) > max {
+		tmp = tmp[:max]
+	}
+
+	result := make([]string, 0, len(tmp))
+
+	for _, u := range tmp {
+		result = append(result, u.ID)
+	}
+
+	return result
+}
+
+// shuffle перемешивает пользователей равновероятно (Fisher–Yates).
+func shuffle(users []domain.User, rand random.Rand) {
+	for i := len(users) - 1; i > 0; i-- {
+		j := rand.Intn(i + 1)
+		users[i], users[j] = users[j], users[i]
+	}
+}
+
+// rotate упорядочивает пользователей по user_id, начиная со следующего после cursor
+// и переходя в начало списка после последнего.
+func rotate(users []domain.User, cursor string) {
+	sort.Slice(users, func(i, j int) bool {
+		return rotationLess(cursor, users[i].ID, users[j].ID)
+	})
+}
+
+// rotationLess сравнивает позиции a и b в очереди, которая начинается сразу после cursor.
+func rotationLess(cursor, a, b string) bool {
+	aWrapped, bWrapped := a <= cursor, b <= cursor
+
+	if aWrapped != bWrapped {
+		return !aWrapped
+	}
+
+	return a < b
+}
+
+// lastInRotation возвращает новый курсор: самого дальнего по очереди из выбранных.
+// Если никто не выбран, курсор не меняется.
+func lastInRotation(cursor string, selected []string) string {
+	next := cursor
+
+	for i, id := range selected {
+		if i == 0 || rotationLess(cursor, next, id) {
+			next = id
+		}
+	}
+
+	return next
+}
+
+// weightedShuffle упорядочивает пользователей взвешенной выборкой без возвращения:
+// очередная позиция достаётся кандидату с вероятностью, пропорциональной его весу.
+// Пользователи с нулевым весом оказываются в конце в случайном порядке.
+func weightedShuffle(users []domain.User, rand random.Rand) {
+	for i := 0; i < len(users)-1; i++ {
+		total := 0
+
+		for _, u := range users[i:] {
+			total += u.SelectionWeight()
+		}
+
+		if total <= 0 {
+			shuffle(users[i:], rand)
+			return
+		}
+
+		r := rand.Intn(total)
+
+		for j := i; j < len(users); j++ {
+			r -= users[j].SelectionWeight()
+
+			if r < 0 {
+				users[i], users[j] = users[j], users[i]
+				break
+			}
+		}
+	}
+}
+
+// chooseReviewersPreferring выбирает до max ревьюеров, сначала среди тех, для кого preferred
+// возвращает true, и добирает недостающих из остальных.
+func chooseReviewersPreferring(
+	users []domain.User,
+	max int,
+	rand random.Rand,
+	pool selectionPool,
+	preferred func(domain.User) bool,
+) []string {
+	var primary, fallback []domain.User
+
+	for _, u := range users {
+		if preferred(u) {
+			primary = append(primary, u)
+		} else {
+			fallback = append(fallback, u)
+		}
+	}
+
+	result := chooseReviewers(primary, max, rand, pool)
+	return append(result, chooseReviewers(fallback, max-len(result), rand, pool)...)
+}
+
+// splitByCapacity делит кандидатов на тех, у кого есть свободная ёмкость, и тех, кто уже загружен.
+func splitByCapacity(
+	users []domain.User,
+	loads map[string]int,
+	settings domain.TeamSettings,
+) (free, full []domain.User) {
+	for _, u := range users {
+		limit := settings.CapacityFor(u)
+
+		if limit != nil && loads[u.ID] >= *limit {
+			full = append(full, u)
+			continue
+		}
+
+		free = append(free, u)
+	}
+
+	return free, full
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// StaleReviewService находит ревью, по которым ревьювер не отметился в срок SLA команды,
+// и в зависимости от настроек команды переназначает их или эскалирует тимлиду событием.
+type StaleReviewService struct {
+	prRepo    domain.PullRequestRepository
+	teamRepo  domain.TeamRepository
+	eventRepo domain.EventRepository
+	prSvc     *PullRequestService
+}
+
+// NewStaleReviewService создаёт новый StaleReviewService.
+func NewStaleReviewService(
+	prRepo domain.PullRequestRepository,
+	teamRepo domain.TeamRepository,
+	eventRepo domain.EventRepository,
+	prSvc *PullRequestService,
+) *StaleReviewService {
+	return &StaleReviewService{
+		prRepo:    prRepo,
+		teamRepo:  teamRepo,
+		eventRepo: eventRepo,
+		prSvc:     prSvc,
+	}
+}
+
+// StaleReviewReport — итог одного прохода по просроченным ревью.
+type StaleReviewReport struct {
+	Reassigned int
+	Escalated  int
+	// Skipped — назначения, которые изменились во время прохода (PR слит, ревьювер снят).
+	Skipped int
+}
+
+// Run обрабатывает назначения, просроченные на момент at. При действии REASSIGN ревью
+// передаётся другому участнику команды; если заменить некем, оно эскалируется, как при ESCALATE.
+// Эскалация публикует событие REVIEW_ESCALATED для тимлида и больше не повторяется для этого назначения.
+func (s *StaleReviewService) Run(ctx context.Context, at time.Time) (StaleReviewReport, error) {
+	var report StaleReviewReport
+
+	stale, err := s.prRepo.ListStaleAssignments(ctx, at)
+
+	if err != nil {
+		return report, err
+	}
+
+	settingsByTeam := make(map[string]domain.TeamSettings)
+
+	for _, a := range stale {
+		settings, ok := settingsByTeam[a.TeamName]
+
+		if !ok {
+			if settings, err = s.teamRepo.GetSettings(ctx, a.TeamName); err != nil {
+				return report, err
+			}
+
+			settingsByTeam[a.TeamName] = settings
+		}
+
+		if settings.StaleAction == domain.StaleActionReassign {
+			_, replacedBy, err := s.prSvc.ReassignReviewer(ctx, a.PRID, a.ReviewerID, "")
+
+			switch {
+			case err == nil:
+				if _, err := s.eventRepo.Publish(ctx, domain.Event{
+					Type:     domain.EventStaleReviewReassigned,
+					TeamName: a.TeamName,
+					PRID:     a.PRID,
+					UserID:   replacedBy,
+					Payload:  staleEventPayload(a, settings),
+				}); err != nil {
+					return report, err
+				}
+
+				report.Reassigned++
+				continue
+
+			case isAssignmentGone(err):
+				report.Skipped++
+				continue
+
+			case !errors.Is(err, domain.ErrNoCandidate) && !errors.Is(err, domain.ErrCapacityExceeded):
+				return report, err
+			}
+		}
+
+		if err := s.escalate(ctx, a, settings, at); err != nil {
+			return report, err
+		}
+
+		report.Escalated++
+	}
+
+	return report, nil
+}
+
+// escalate публикует событие для тимлида команды и отмечает назначение эскалированным.
+// Если тимлид не задан, событие адресуется участнику команды уровня LEAD (первому по user_id),
+// а если такого нет — всей команде (без user_id).
+func (s *StaleReviewService) escalate(
+	ctx context.Context,
+	a domain.StaleAssignment,
+	settings domain.TeamSettings,
+	at time.Time,
+) error {
+	lead := settings.LeadID
+
+	if lead == "" {
+		team, err := s.teamRepo.GetTeamWithMembers(ctx, a.TeamName)
+
+		if err != nil {
+			return err
+		}
+
+		lead = defaultLead(team.Members)
+	}
+
+	if _, err := s.eventRepo.Publish(ctx, domain.Event{
+		Type:     domain.EventReviewEscalated,
+		TeamName: a.TeamName,
+		PRID:     a.PRID,
+		UserID:   lead,
+		Payload:  staleEventPayload(a, settings),
+	}); err != nil {
+		return err
+	}
+
+	return s.prRepo.MarkEscalated(ctx, a.PRID, a.ReviewerID, at)
+}
+
+// defaultLead возвращает активного участника уровня LEAD с наименьшим user_id.
+func defaultLead(members []domain.User) string {
+	sorted := make([]domain.User, len(members))
+	copy(sorted, members)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	for _, m := range sorted {
+		if m.IsActive && m.Seniority == domain.SeniorityLead {
+			return m.ID
+		}
+	}
+
+	return ""
+}
+
+func staleEventPayload(a domain.StaleAssignment, settings domain.TeamSettings) map[string]string {
+	return map[string]string{
+		"reviewer_id": a.ReviewerID,
+		"assigned_at": a.AssignedAt.UTC().Format(time.RFC3339),
+		"sla_hours":   strconv.Itoa(settings.ReviewSLAHours),
+	}
+}
+
+// isAssignmentGone сообщает, что назначение изменилось после выборки просроченных.
+func isAssignmentGone(err error) bool {
+	return errors.Is(err, domain.ErrPRMerged) ||
+		errors.Is(err, domain.ErrReviewerNotAssigned) ||
+		errors.Is(err, domain.ErrNotFound)
+}
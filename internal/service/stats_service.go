@@ -8,17 +8,26 @@ import (
 
 // StatsService содержит бизнес-логику, связанную со статистикой по ревью.
 type StatsService struct {
-	prRepo domain.PullRequestRepository
+	prRepo   domain.PullRequestRepository
+	userRepo domain.UserRepository
 }
 
 // NewStatsService создаёт новый StatsService.
-func NewStatsService(prRepo domain.PullRequestRepository) *StatsService {
+func NewStatsService(prRepo domain.PullRequestRepository, userRepo domain.UserRepository) *StatsService {
 	return &StatsService{
-		prRepo: prRepo,
+		prRepo:   prRepo,
+		userRepo: userRepo,
 	}
 }
 
-// GetAssignmentsByUser возвращает статистику назначений на ревью по пользователям.
-func (s *StatsService) GetAssignmentsByUser(ctx context.Context) ([]domain.AssignmentStatByUser, error) {
-	return s.prRepo.GetAssignmentStatsByUser(ctx)
+// GetAssignmentsByUser возвращает статистику назначений на ревью по пользователям команды teamName
+// (пусто — всех команд; тимлиду — только его команды).
+func (s *StatsService) GetAssignmentsByUser(ctx context.Context, teamName string) ([]domain.AssignmentStatByUser, error) {
+	teamName, err := scopeTeam(ctx, s.userRepo, teamName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.prRepo.GetAssignmentStatsByUser(ctx, teamName)
 }
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// Тимлид (TEAM_LEAD) управляет только своей командой — той, в которой состоит сам: её настройками
+// и правилами, её участниками и PR её авторов, и видит статистику только по ней. Так же ограничен
+// участник, вызывающий сервис из чата (MEMBER). Остальные роли ограничиваются на уровне маршрутов,
+// а вызовы без вызывающего в контексте (фоновые задачи) не ограничиваются.
+
+// callerTeam возвращает команду тимлида или участника из контекста; scoped == false, если вызывающий
+// не ограничен своей командой.
+func callerTeam(ctx context.Context, userRepo domain.UserRepository) (team string, scoped bool, err error) {
+	caller, ok := domain.CallerFrom(ctx)
+
+	if !ok || !caller.HasRole(domain.RoleTeamLead, domain.RoleMember) {
+		return "", false, nil
+	}
+
+	if caller.UserID == "" {
+		return "", true, domain.NewDomainError(domain.ErrorCodeForbidden,
+			fmt.Errorf("caller %s is not bound to a user: %w", caller.Name, domain.ErrForbidden))
+	}
+
+	lead, err := userRepo.GetByID(ctx, caller.UserID)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return "", true, domain.NewDomainError(domain.ErrorCodeForbidden,
+				fmt.Errorf("caller %s is not a member of any team: %w", caller.UserID, domain.ErrForbidden))
+		}
+
+		return "", true, err
+	}
+
+	return lead.TeamName, true, nil
+}
+
+// authorizeTeam проверяет, что вызывающий может управлять командой teamName.
+func authorizeTeam(ctx context.Context, userRepo domain.UserRepository, teamName string) error {
+	own, scoped, err := callerTeam(ctx, userRepo)
+
+	if err != nil || !scoped {
+		return err
+	}
+
+	if own != teamName {
+		return domain.NewDomainError(domain.ErrorCodeForbidden,
+			fmt.Errorf("team %s is managed by its own lead: %w", teamName, domain.ErrForbidden))
+	}
+
+	return nil
+}
+
+// scopeTeam возвращает команду, которой ограничен запрос на чтение статистики или событий
+// с фильтром teamName (пусто — все команды): тимлиду без фильтра отдаётся его команда,
+// а чужая команда запрещена.
+func scopeTeam(ctx context.Context, userRepo domain.UserRepository, teamName string) (string, error) {
+	own, scoped, err := callerTeam(ctx, userRepo)
+
+	if err != nil || !scoped {
+		return teamName, err
+	}
+
+	if teamName != "" && teamName != own {
+		return "", domain.NewDomainError(domain.ErrorCodeForbidden,
+			fmt.Errorf("team lead may only see team %s: %w", own, domain.ErrForbidden))
+	}
+
+	return own, nil
+}
+
+// authorizeUser проверяет, что вызывающий может управлять пользователем userID.
+func authorizeUser(ctx context.Context, userRepo domain.UserRepository, userID string) error {
+	own, scoped, err := callerTeam(ctx, userRepo)
+
+	if err != nil || !scoped {
+		return err
+	}
+
+	return checkMember(ctx, userRepo, own, userID)
+}
+
+// checkMember возвращает FORBIDDEN, если userID (в том числе несуществующий) не состоит в команде own.
+func checkMember(ctx context.Context, userRepo domain.UserRepository, own, userID string) error {
+	team, err := userRepo.GetTeamByUserID(ctx, userID)
+
+	if err != nil && err != domain.ErrNotFound {
+		return err
+	}
+
+	if err == domain.ErrNotFound || team != own {
+		return domain.NewDomainError(domain.ErrorCodeForbidden,
+			fmt.Errorf("user %s is not a member of team %s: %w", userID, own, domain.ErrForbidden))
+	}
+
+	return nil
+}
+
+// isCaller сообщает, что вызывающий действует от имени пользователя userID.
+func isCaller(ctx context.Context, userID string) bool {
+	caller, ok := domain.CallerFrom(ctx)
+	return ok && caller.UserID != "" && caller.UserID == userID
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 
 	"pr-reviewer-service/internal/domain"
 )
@@ -10,18 +12,40 @@ import (
 type TeamService struct {
 	teamRepo domain.TeamRepository
 	userRepo domain.UserRepository
+	ruleRepo domain.ReviewRuleRepository
 }
 
 // NewTeamService создаёт новый TeamService.
-func NewTeamService(teamRepo domain.TeamRepository, userRepo domain.UserRepository) *TeamService {
+func NewTeamService(
+	teamRepo domain.TeamRepository,
+	userRepo domain.UserRepository,
+	ruleRepo domain.ReviewRuleRepository,
+) *TeamService {
 	return &TeamService{
 		teamRepo: teamRepo,
 		userRepo: userRepo,
+		ruleRepo: ruleRepo,
 	}
 }
 
-// CreateTeam создаёт команду и добавляет/обновляет её участников.
+// CreateTeam создаёт команду и добавляет/обновляет её участников. Тимлид не может создать команду:
+// участники переходят в неё из других команд, которыми он не управляет.
 func (s *TeamService) CreateTeam(ctx context.Context, teamName string, members []domain.User) (domain.Team, error) {
+	if err := authorizeTeam(ctx, s.userRepo, teamName); err != nil {
+		return domain.Team{}, err
+	}
+
+	for _, m := range members {
+		if m.MaxOpenReviews != nil && *m.MaxOpenReviews < 0 {
+			return domain.Team{}, domain.NewDomainError(domain.ErrorCodeValidation,
+				fmt.Errorf("max_open_reviews of %s must be non-negative: %w", m.ID, domain.ErrInvalidInput))
+		}
+
+		if err := validateSeniority(m.Seniority, m.Weight); err != nil {
+			return domain.Team{}, err
+		}
+	}
+
 	exists, err := s.teamRepo.TeamExists(ctx, teamName)
 
 	if err != nil {
@@ -64,3 +88,242 @@ func (s *TeamService) GetTeam(ctx context.Context, teamName string) (domain.Team
 
 	return team, nil
 }
+
+// UpdateSettings частично изменяет настройки назначения ревьюверов команды.
+func (s *TeamService) UpdateSettings(
+	ctx context.Context,
+	teamName string,
+	update domain.TeamSettingsUpdate,
+) (domain.TeamSettings, error) {
+	if err := authorizeTeam(ctx, s.userRepo, teamName); err != nil {
+		return domain.TeamSettings{}, err
+	}
+
+	current, err := s.teamRepo.GetSettings(ctx, teamName)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.TeamSettings{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return domain.TeamSettings{}, err
+	}
+
+	if err := domain.CheckVersion(ctx, current.Version); err != nil {
+		return domain.TeamSettings{}, domain.NewDomainError(domain.ErrorCodePreconditionFailed, err)
+	}
+
+	settings := update.Apply(current)
+
+	if err := validateTeamSettings(settings); err != nil {
+		return domain.TeamSettings{}, err
+	}
+
+	if update.LeadID.Set && settings.LeadID != "" {
+		lead, err := s.userRepo.GetByID(ctx, settings.LeadID)
+
+		if err != nil {
+			if err == domain.ErrNotFound {
+				return domain.TeamSettings{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+			}
+
+			return domain.TeamSettings{}, err
+		}
+
+		if lead.TeamName != teamName {
+			return domain.TeamSettings{}, domain.NewDomainError(domain.ErrorCodeValidation,
+				fmt.Errorf("lead_id must be a member of the team: %w", domain.ErrInvalidInput))
+		}
+	}
+
+	updated, err := s.teamRepo.UpdateSettings(ctx, teamName, settings)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.TeamSettings{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		if err == domain.ErrVersionMismatch {
+			return domain.TeamSettings{}, domain.NewDomainError(domain.ErrorCodePreconditionFailed, err)
+		}
+
+		return domain.TeamSettings{}, err
+	}
+
+	return updated, nil
+}
+
+func validateTeamSettings(settings domain.TeamSettings) error {
+	if !settings.CapacityPolicy.Valid() {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("unknown capacity_policy %q: %w", settings.CapacityPolicy, domain.ErrInvalidInput))
+	}
+
+	if settings.DefaultMaxOpenReviews != nil && *settings.DefaultMaxOpenReviews < 0 {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("default_max_open_reviews must be non-negative: %w", domain.ErrInvalidInput))
+	}
+
+	if settings.PairingWindowDays < 0 {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("pairing_window_days must be non-negative: %w", domain.ErrInvalidInput))
+	}
+
+	if settings.MaxReviewers < 1 {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("max_reviewers must be positive: %w", domain.ErrInvalidInput))
+	}
+
+	if !settings.Strategy.Valid() {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("unknown selection_strategy %q: %w", settings.Strategy, domain.ErrInvalidInput))
+	}
+
+	if settings.ReviewSLAHours < 0 {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("review_sla_hours must be non-negative: %w", domain.ErrInvalidInput))
+	}
+
+	if !settings.StaleAction.Valid() {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("unknown stale_action %q: %w", settings.StaleAction, domain.ErrInvalidInput))
+	}
+
+	if settings.ChatWebhookURL != "" {
+		u, err := url.Parse(settings.ChatWebhookURL)
+
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return domain.NewDomainError(domain.ErrorCodeValidation,
+				fmt.Errorf("chat_webhook_url must be an absolute http(s) URL: %w", domain.ErrInvalidInput))
+		}
+	}
+
+	return nil
+}
+
+// AddRule добавляет правило назначения ревьюверов в команду.
+// Целью правила является либо конкретный ревьюер из команды, либо уровень ревьювера;
+// автор (если указан) должен существовать.
+func (s *TeamService) AddRule(ctx context.Context, rule domain.ReviewRule) (domain.ReviewRule, error) {
+	if err := validateRule(rule); err != nil {
+		return domain.ReviewRule{}, err
+	}
+
+	if err := authorizeTeam(ctx, s.userRepo, rule.TeamName); err != nil {
+		return domain.ReviewRule{}, err
+	}
+
+	exists, err := s.teamRepo.TeamExists(ctx, rule.TeamName)
+
+	if err != nil {
+		return domain.ReviewRule{}, err
+	}
+
+	if !exists {
+		return domain.ReviewRule{}, domain.NewDomainError(domain.ErrorCodeNotFound, domain.ErrNotFound)
+	}
+
+	if rule.ReviewerID != "" {
+		reviewer, err := s.userRepo.GetByID(ctx, rule.ReviewerID)
+
+		if err != nil {
+			if err == domain.ErrNotFound {
+				return domain.ReviewRule{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+			}
+
+			return domain.ReviewRule{}, err
+		}
+
+		if reviewer.TeamName != rule.TeamName {
+			return domain.ReviewRule{}, domain.NewDomainError(domain.ErrorCodeValidation,
+				fmt.Errorf("reviewer %s is not a member of team %s: %w", reviewer.ID, rule.TeamName, domain.ErrInvalidInput))
+		}
+	}
+
+	if rule.AuthorID != "" {
+		if _, err := s.userRepo.GetByID(ctx, rule.AuthorID); err != nil {
+			if err == domain.ErrNotFound {
+				return domain.ReviewRule{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+			}
+
+			return domain.ReviewRule{}, err
+		}
+	}
+
+	return s.ruleRepo.Create(ctx, rule)
+}
+
+func validateRule(rule domain.ReviewRule) error {
+	if rule.Kind != domain.RuleKindRequire && rule.Kind != domain.RuleKindExclude {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("unknown rule kind %q: %w", rule.Kind, domain.ErrInvalidInput))
+	}
+
+	if (rule.ReviewerID == "") == (rule.ReviewerLevel == "") {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("exactly one of reviewer_id and reviewer_level is required: %w", domain.ErrInvalidInput))
+	}
+
+	if rule.ReviewerID != "" && rule.ReviewerID == rule.AuthorID {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("reviewer_id must differ from author_id: %w", domain.ErrInvalidInput))
+	}
+
+	if rule.AuthorID != "" && rule.AuthorLevel != "" {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("author_id and author_level are mutually exclusive: %w", domain.ErrInvalidInput))
+	}
+
+	for _, level := range []domain.Seniority{rule.AuthorLevel, rule.ReviewerLevel} {
+		if level != "" && !level.Valid() {
+			return domain.NewDomainError(domain.ErrorCodeValidation,
+				fmt.Errorf("unknown seniority %q: %w", level, domain.ErrInvalidInput))
+		}
+	}
+
+	return nil
+}
+
+// ListRules возвращает правила назначения ревьюверов команды.
+func (s *TeamService) ListRules(ctx context.Context, teamName string) ([]domain.ReviewRule, error) {
+	exists, err := s.teamRepo.TeamExists(ctx, teamName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, domain.ErrNotFound)
+	}
+
+	return s.ruleRepo.ListByTeam(ctx, teamName)
+}
+
+// DeleteRule удаляет правило и возвращает удалённую запись.
+func (s *TeamService) DeleteRule(ctx context.Context, id int64) (domain.ReviewRule, error) {
+	existing, err := s.ruleRepo.GetByID(ctx, id)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.ReviewRule{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return domain.ReviewRule{}, err
+	}
+
+	if err := authorizeTeam(ctx, s.userRepo, existing.TeamName); err != nil {
+		return domain.ReviewRule{}, err
+	}
+
+	rule, err := s.ruleRepo.Delete(ctx, id)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.ReviewRule{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return domain.ReviewRule{}, err
+	}
+
+	return rule, nil
+}
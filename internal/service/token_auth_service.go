@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/oidc"
+)
+
+// TokenVerifier проверяет подпись и срок действия JWT и возвращает его утверждения.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string, now time.Time) (oidc.Claims, error)
+}
+
+// ClaimMapping описывает, как утверждения токена превращаются в вызывающего.
+type ClaimMapping struct {
+	// UserClaim — утверждение с идентификатором пользователя (обычно "sub").
+	UserClaim string
+	// RoleClaim — утверждение со списком ролей или групп; путь через точку ("realm_access.roles").
+	RoleClaim string
+	// RoleMap сопоставляет значения RoleClaim ролям сервиса. Значения без сопоставления роли не дают,
+	// даже если совпадают с её названием: группы в IdP может заводить не только администратор сервиса.
+	RoleMap map[string]domain.Role
+	// DefaultRole — роль, если в токене нет ни одной подходящей (пусто — доступ запрещён).
+	DefaultRole domain.Role
+	// OrgID — организация, в которой работают пользователи портала.
+	OrgID int64
+}
+
+// rolePriority упорядочивает роли по ширине прав: из нескольких ролей токена выбирается старшая.
+var rolePriority = map[domain.Role]int{
+	domain.RoleReader:   1,
+	domain.RoleBot:      2,
+	domain.RoleTeamLead: 3,
+	domain.RoleAdmin:    4,
+}
+
+// TokenAuthService аутентифицирует вызывающих по JWT, выпущенным внутренним порталом (OIDC).
+type TokenAuthService struct {
+	verifier TokenVerifier
+	userRepo domain.UserRepository
+	mapping  ClaimMapping
+}
+
+// NewTokenAuthService создаёт новый TokenAuthService.
+func NewTokenAuthService(verifier TokenVerifier, userRepo domain.UserRepository, mapping ClaimMapping) *TokenAuthService {
+	return &TokenAuthService{
+		verifier: verifier,
+		userRepo: userRepo,
+		mapping:  mapping,
+	}
+}
+
+// Authenticate проверяет токен на момент at и возвращает вызывающего. UserID заполняется,
+// только если пользователь из токена заведён в сервисе: иначе его нельзя записать в историю.
+func (s *TokenAuthService) Authenticate(ctx context.Context, token string, at time.Time) (domain.Caller, error) {
+	claims, err := s.verifier.Verify(ctx, token, at)
+
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidToken) {
+			return domain.Caller{}, domain.NewDomainError(domain.ErrorCodeUnauthorized,
+				fmt.Errorf("%v: %w", err, domain.ErrUnauthorized))
+		}
+
+		return domain.Caller{}, err
+	}
+
+	subject := claims.String(s.mapping.UserClaim)
+
+	if subject == "" {
+		return domain.Caller{}, domain.NewDomainError(domain.ErrorCodeUnauthorized,
+			fmt.Errorf("token has no %q claim: %w", s.mapping.UserClaim, domain.ErrUnauthorized))
+	}
+
+	role := s.resolveRole(claims.Strings(s.mapping.RoleClaim))
+
+	if role == "" {
+		return domain.Caller{}, domain.NewDomainError(domain.ErrorCodeForbidden,
+			fmt.Errorf("token of %s grants no role: %w", subject, domain.ErrForbidden))
+	}
+
+	caller := domain.Caller{Name: "oidc:" + subject, OrgID: s.mapping.OrgID, Role: role}
+	ctx = domain.WithOrganization(ctx, caller.OrgID)
+
+	if _, err := s.userRepo.GetByID(ctx, subject); err == nil {
+		caller.UserID = subject
+	} else if err != domain.ErrNotFound {
+		return domain.Caller{}, err
+	}
+
+	return caller, nil
+}
+
+// resolveRole выбирает старшую из ролей, которые дают значения values.
+func (s *TokenAuthService) resolveRole(values []string) domain.Role {
+	best := s.mapping.DefaultRole
+
+	for _, v := range values {
+		role, ok := s.mapping.RoleMap[v]
+
+		if ok && role.Valid() && rolePriority[role] > rolePriority[best] {
+			best = role
+		}
+	}
+
+	return best
+}
@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"net/mail"
+	"strings"
 
 	"pr-reviewer-service/internal/domain"
 )
@@ -10,18 +13,28 @@ import (
 type UserService struct {
 	userRepo domain.UserRepository
 	prRepo   domain.PullRequestRepository
+	teamRepo domain.TeamRepository
 }
 
 // NewUserService создаёт новый UserService.
-func NewUserService(userRepo domain.UserRepository, prRepo domain.PullRequestRepository) *UserService {
+func NewUserService(
+	userRepo domain.UserRepository,
+	prRepo domain.PullRequestRepository,
+	teamRepo domain.TeamRepository,
+) *UserService {
 	return &UserService{
 		userRepo: userRepo,
 		prRepo:   prRepo,
+		teamRepo: teamRepo,
 	}
 }
 
 // SetIsActive изменяет флаг активности пользователя и возвращает обновлённую сущность.
 func (s *UserService) SetIsActive(ctx context.Context, userID string, isActive bool) (domain.User, error) {
+	if err := authorizeUser(ctx, s.userRepo, userID); err != nil {
+		return domain.User{}, err
+	}
+
 	user, err := s.userRepo.SetIsActive(ctx, userID, isActive)
 
 	if err != nil {
@@ -35,23 +48,224 @@ func (s *UserService) SetIsActive(ctx context.Context, userID string, isActive b
 	return user, nil
 }
 
-// GetReviewPRs возвращает список PR для ревью указанного пользователя.
-func (s *UserService) GetReviewPRs(ctx context.Context, userID string) (string, []domain.PullRequestShort, error) {
+// SetMaxOpenReviews задаёт личный лимит открытых ревью пользователя (nil — значение команды).
+func (s *UserService) SetMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) (domain.User, error) {
+	if maxOpenReviews != nil && *maxOpenReviews < 0 {
+		return domain.User{}, domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("max_open_reviews must be non-negative: %w", domain.ErrInvalidInput))
+	}
+
+	if err := authorizeUser(ctx, s.userRepo, userID); err != nil {
+		return domain.User{}, err
+	}
+
+	user, err := s.userRepo.SetMaxOpenReviews(ctx, userID, maxOpenReviews)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.User{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+// SetSeniority задаёт уровень пользователя и явный вес при взвешенном выборе (nil — вес по уровню).
+func (s *UserService) SetSeniority(
+	ctx context.Context,
+	userID string,
+	seniority domain.Seniority,
+	weight *int,
+) (domain.User, error) {
+	if err := validateSeniority(seniority, weight); err != nil {
+		return domain.User{}, err
+	}
+
+	if err := authorizeUser(ctx, s.userRepo, userID); err != nil {
+		return domain.User{}, err
+	}
+
+	user, err := s.userRepo.SetSeniority(ctx, userID, seniority, weight)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.User{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func validateSeniority(seniority domain.Seniority, weight *int) error {
+	if seniority != "" && !seniority.Valid() {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("unknown seniority %q: %w", seniority, domain.ErrInvalidInput))
+	}
+
+	if weight != nil && *weight < 0 {
+		return domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("selection_weight must be non-negative: %w", domain.ErrInvalidInput))
+	}
+
+	return nil
+}
+
+// SetNotifications задаёт адрес, имя в чате для @-упоминаний и подписку на ежедневную сводку.
+// Неуказанные поля не меняются; email или chat_handle, сброшенные в null или пустые, удаляются.
+func (s *UserService) SetNotifications(
+	ctx context.Context,
+	userID string,
+	email, chatHandle domain.Optional[string],
+	digestEnabled *bool,
+) (domain.User, error) {
+	if err := authorizeUser(ctx, s.userRepo, userID); err != nil {
+		return domain.User{}, err
+	}
+
 	user, err := s.userRepo.GetByID(ctx, userID)
 
 	if err != nil {
 		if err == domain.ErrNotFound {
-			return "", nil, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+			return domain.User{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
 		}
 
-		return "", nil, err
+		return domain.User{}, err
+	}
+
+	if email.Set {
+		user.Email = ""
+
+		if email.Value != nil {
+			user.Email = strings.TrimSpace(*email.Value)
+		}
+	}
+
+	if user.Email != "" {
+		addr, err := mail.ParseAddress(user.Email)
+
+		if err != nil || addr.Address != user.Email {
+			return domain.User{}, domain.NewDomainError(domain.ErrorCodeValidation,
+				fmt.Errorf("invalid email %q: %w", user.Email, domain.ErrInvalidInput))
+		}
+	}
+
+	if chatHandle.Set {
+		user.ChatHandle = ""
+
+		if chatHandle.Value != nil {
+			user.ChatHandle = strings.TrimPrefix(strings.TrimSpace(*chatHandle.Value), "@")
+		}
+	}
+
+	if strings.ContainsAny(user.ChatHandle, " \t\n") {
+		return domain.User{}, domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("chat_handle must not contain spaces: %w", domain.ErrInvalidInput))
+	}
+
+	if digestEnabled != nil {
+		user.DigestEnabled = *digestEnabled
+	}
+
+	user, err = s.userRepo.SetNotifications(ctx, userID, user.Email, user.ChatHandle, user.DigestEnabled)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.User{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		if err == domain.ErrChatHandleTaken {
+			return domain.User{}, domain.NewDomainError(domain.ErrorCodeValidation,
+				fmt.Errorf("chat_handle %q: %w", user.ChatHandle, err))
+		}
+
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+// SetWorkSchedule задаёт часовой пояс и рабочие часы пользователя (hours == nil — без графика).
+func (s *UserService) SetWorkSchedule(
+	ctx context.Context,
+	userID, timezone string,
+	hours *domain.WorkHours,
+) (domain.User, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	if _, err := domain.LoadLocation(timezone); err != nil {
+		return domain.User{}, domain.NewDomainError(domain.ErrorCodeValidation,
+			fmt.Errorf("unknown timezone %q: %w", timezone, domain.ErrInvalidInput))
+	}
+
+	if hours != nil {
+		if hours.StartMinute < 0 || hours.StartMinute >= 24*60 ||
+			hours.EndMinute < 0 || hours.EndMinute > 24*60 ||
+			hours.StartMinute == hours.EndMinute {
+			return domain.User{}, domain.NewDomainError(domain.ErrorCodeValidation,
+				fmt.Errorf("invalid working hours: %w", domain.ErrInvalidInput))
+		}
+	}
+
+	if err := authorizeUser(ctx, s.userRepo, userID); err != nil {
+		return domain.User{}, err
+	}
+
+	user, err := s.userRepo.SetWorkSchedule(ctx, userID, timezone, hours)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.User{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+// GetReviewPRs возвращает список PR для ревью указанного пользователя и его текущую загрузку.
+func (s *UserService) GetReviewPRs(
+	ctx context.Context,
+	userID string,
+) (string, []domain.PullRequestShort, domain.ReviewLoad, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return "", nil, domain.ReviewLoad{}, domain.NewDomainError(domain.ErrorCodeNotFound, err)
+		}
+
+		return "", nil, domain.ReviewLoad{}, err
 	}
 
 	prs, err := s.prRepo.ListByReviewer(ctx, userID)
 
 	if err != nil {
-		return "", nil, err
+		return "", nil, domain.ReviewLoad{}, err
+	}
+
+	loads, err := s.prRepo.CountOpenReviews(ctx, []string{user.ID})
+
+	if err != nil {
+		return "", nil, domain.ReviewLoad{}, err
+	}
+
+	settings, err := s.teamRepo.GetSettings(ctx, user.TeamName)
+
+	if err != nil {
+		return "", nil, domain.ReviewLoad{}, err
+	}
+
+	load := domain.ReviewLoad{
+		OpenReviews:    loads[user.ID],
+		MaxOpenReviews: settings.CapacityFor(user),
 	}
 
-	return user.ID, prs, nil
+	return user.ID, prs, load, nil
 }
@@ -7,6 +7,7 @@ export const options = {
 };
 
 const BASE_URL = __ENV.BASE_URL || 'http://localhost:8080';
+const HEADERS = { 'Content-Type': 'application/json', 'X-API-Key': __ENV.API_KEY || '' };
 
 export default function () {
   // health
@@ -23,7 +24,7 @@ export default function () {
       { user_id: 'u2', username: 'Bob',   is_active: true },
       { user_id: 'u3', username: 'Eve',   is_active: true },
     ],
-  }), { headers: { 'Content-Type': 'application/json' } });
+  }), { headers: HEADERS });
 
   check(res, {
     'team add 201 or 400': (r) => r.status === 201 || r.status === 400,
@@ -35,7 +36,7 @@ export default function () {
     pull_request_id: prId,
     pull_request_name: 'Load test PR',
     author_id: 'u1',
-  }), { headers: { 'Content-Type': 'application/json' } });
+  }), { headers: HEADERS });
 
   check(res, {
     'create pr 201 or 409': (r) => r.status === 201 || r.status === 409,
@@ -44,7 +45,7 @@ export default function () {
   // merge PR
   res = http.post(`${BASE_URL}/pullRequest/merge`, JSON.stringify({
     pull_request_id: prId,
-  }), { headers: { 'Content-Type': 'application/json' } });
+  }), { headers: HEADERS });
 
   check(res, {
     'merge pr 200 or 404': (r) => r.status === 200 || r.status === 404,
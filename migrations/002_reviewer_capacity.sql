@@ -0,0 +1,9 @@
+-- Личный лимит одновременно открытых ревью (NULL — используется значение команды)
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS max_open_reviews INT CHECK (max_open_reviews >= 0);
+
+-- Настройки ёмкости на уровне команды
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS default_max_open_reviews INT CHECK (default_max_open_reviews >= 0),
+    ADD COLUMN IF NOT EXISTS capacity_policy TEXT NOT NULL DEFAULT 'ASSIGN_FEWER'
+        CHECK (capacity_policy IN ('ASSIGN_ANYWAY', 'ASSIGN_FEWER', 'FAIL'));
@@ -0,0 +1,22 @@
+-- Плановые отсутствия пользователей (отпуска, больничные и т.п.).
+-- Интервал полуоткрытый: [starts_at, ends_at).
+CREATE TABLE IF NOT EXISTS user_absences (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    starts_at    TIMESTAMPTZ NOT NULL,
+    ends_at      TIMESTAMPTZ NOT NULL,
+    reason       TEXT NOT NULL DEFAULT '',
+    source       TEXT NOT NULL DEFAULT 'MANUAL' CHECK (source IN ('MANUAL', 'ICS')),
+    external_uid TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_absences_user_period
+    ON user_absences (user_id, starts_at, ends_at);
+
+-- UID события из iCalendar: повторный импорт обновляет запись, а не дублирует её
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_absences_external_uid
+    ON user_absences (user_id, external_uid)
+    WHERE external_uid IS NOT NULL;
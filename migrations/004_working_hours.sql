@@ -0,0 +1,12 @@
+-- Часовой пояс и рабочие часы пользователя.
+-- Рабочее время задаётся минутами от полуночи в локальном времени пользователя,
+-- work_days — битовая маска дней недели (бит 0 — воскресенье, по умолчанию пн–пт).
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS timezone       TEXT NOT NULL DEFAULT 'UTC',
+    ADD COLUMN IF NOT EXISTS work_start_min SMALLINT CHECK (work_start_min BETWEEN 0 AND 1439),
+    ADD COLUMN IF NOT EXISTS work_end_min   SMALLINT CHECK (work_end_min BETWEEN 0 AND 1440),
+    ADD COLUMN IF NOT EXISTS work_days      SMALLINT NOT NULL DEFAULT 62 CHECK (work_days BETWEEN 0 AND 127);
+
+-- Режим выбора ревьюверов: предпочитать тех, кто сейчас в рабочих часах
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS prefer_working_hours BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,26 @@
+-- История назначений ревьюверов: каждое назначение и снятие с PR
+CREATE TABLE IF NOT EXISTS review_assignment_history (
+    id          BIGSERIAL PRIMARY KEY,
+    pr_id       TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    author_id   TEXT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    action      TEXT NOT NULL CHECK (action IN ('ASSIGNED', 'UNASSIGNED')),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_history_pair
+    ON review_assignment_history (author_id, reviewer_id, created_at)
+    WHERE action = 'ASSIGNED';
+
+CREATE INDEX IF NOT EXISTS idx_assignment_history_pr
+    ON review_assignment_history (pr_id, created_at);
+
+-- Переносим текущие назначения, чтобы история не начиналась с нуля
+INSERT INTO review_assignment_history (pr_id, reviewer_id, author_id, action, created_at)
+SELECT r.pr_id, r.reviewer_id, p.author_id, 'ASSIGNED', COALESCE(p.created_at, NOW())
+  FROM pr_reviewers r
+  JOIN pull_requests p ON p.id = r.pr_id;
+
+-- Окно (в днях), за которое учитываются пары автор–ревьювер; 0 отключает ротацию пар
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS pairing_window_days INT NOT NULL DEFAULT 30 CHECK (pairing_window_days >= 0);
@@ -0,0 +1,24 @@
+-- Метки pull request-ов (используются в условиях правил)
+CREATE TABLE IF NOT EXISTS pr_labels (
+    pr_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    label TEXT NOT NULL,
+    PRIMARY KEY (pr_id, label)
+);
+
+-- Правила назначения ревьюверов в команде.
+-- REQUIRE: если условие выполнено, reviewer_id обязательно назначается ревьювером.
+-- EXCLUDE: если условие выполнено, reviewer_id никогда не назначается ревьювером.
+-- Условие: автор (author_id) и/или метка PR (label); NULL означает «любой».
+CREATE TABLE IF NOT EXISTS team_review_rules (
+    id          BIGSERIAL PRIMARY KEY,
+    team_name   TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    kind        TEXT NOT NULL CHECK (kind IN ('REQUIRE', 'EXCLUDE')),
+    author_id   TEXT REFERENCES users(user_id) ON DELETE CASCADE,
+    label       TEXT,
+    reviewer_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_review_rules_team
+    ON team_review_rules (team_name);
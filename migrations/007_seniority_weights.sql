@@ -0,0 +1,18 @@
+-- Уровень и вес пользователя для взвешенного выбора ревьюверов
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS seniority TEXT CHECK (seniority IN ('JUNIOR', 'MIDDLE', 'SENIOR', 'LEAD')),
+    ADD COLUMN IF NOT EXISTS selection_weight INT CHECK (selection_weight >= 0);
+
+-- Стратегия выбора ревьюверов команды
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS selection_strategy TEXT NOT NULL DEFAULT 'RANDOM'
+        CHECK (selection_strategy IN ('RANDOM', 'WEIGHTED'));
+
+-- Правила могут ссылаться на уровень автора и уровень ревьювера,
+-- например «в каждом PR джуна должен быть сеньор»
+ALTER TABLE team_review_rules
+    ADD COLUMN IF NOT EXISTS author_level TEXT CHECK (author_level IN ('JUNIOR', 'MIDDLE', 'SENIOR', 'LEAD')),
+    ADD COLUMN IF NOT EXISTS reviewer_level TEXT CHECK (reviewer_level IN ('JUNIOR', 'MIDDLE', 'SENIOR', 'LEAD')),
+    ALTER COLUMN reviewer_id DROP NOT NULL,
+    ADD CONSTRAINT team_review_rules_reviewer_target
+        CHECK ((reviewer_id IS NULL) <> (reviewer_level IS NULL));
@@ -0,0 +1,6 @@
+-- Стратегия ROUND_ROBIN: курсор ротации хранит последнего выбранного по очереди ревьювера
+ALTER TABLE teams
+    DROP CONSTRAINT IF EXISTS teams_selection_strategy_check,
+    ADD CONSTRAINT teams_selection_strategy_check
+        CHECK (selection_strategy IN ('RANDOM', 'WEIGHTED', 'ROUND_ROBIN')),
+    ADD COLUMN IF NOT EXISTS rr_cursor TEXT;
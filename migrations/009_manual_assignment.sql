@@ -0,0 +1,7 @@
+-- Максимальное число ревьюверов на PR (автоматический выбор и ручное добавление)
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS max_reviewers INT NOT NULL DEFAULT 2 CHECK (max_reviewers >= 1);
+
+-- Кто изменил список ревьюверов (NULL — автоматическое назначение)
+ALTER TABLE review_assignment_history
+    ADD COLUMN IF NOT EXISTS actor_id TEXT REFERENCES users(user_id) ON DELETE SET NULL;
@@ -0,0 +1,12 @@
+-- Отказы ревьюверов от назначенного ревью с причиной и выбранной заменой
+CREATE TABLE IF NOT EXISTS review_declines (
+    id          BIGSERIAL PRIMARY KEY,
+    pr_id       TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    reason      TEXT NOT NULL CHECK (reason <> ''),
+    replaced_by TEXT REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_declines_reviewer
+    ON review_declines (reviewer_id, created_at);
@@ -0,0 +1,36 @@
+-- Когда ревьювер назначен, когда отметил ревью и когда по нему была эскалация
+ALTER TABLE pr_reviewers
+    ADD COLUMN IF NOT EXISTS assigned_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS reviewed_at  TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMPTZ;
+
+UPDATE pr_reviewers r
+   SET assigned_at = p.created_at
+  FROM pull_requests p
+ WHERE p.id = r.pr_id
+   AND p.created_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_pending
+    ON pr_reviewers (assigned_at)
+    WHERE reviewed_at IS NULL AND escalated_at IS NULL;
+
+-- SLA на ревью (0 — не отслеживается), действие при его нарушении и тимлид команды
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS review_sla_hours INT NOT NULL DEFAULT 0 CHECK (review_sla_hours >= 0),
+    ADD COLUMN IF NOT EXISTS stale_action TEXT NOT NULL DEFAULT 'REASSIGN'
+        CHECK (stale_action IN ('REASSIGN', 'ESCALATE')),
+    ADD COLUMN IF NOT EXISTS lead_id TEXT REFERENCES users(user_id) ON DELETE SET NULL;
+
+-- События для внешних потребителей (уведомления, интеграции)
+CREATE TABLE IF NOT EXISTS events (
+    id         BIGSERIAL PRIMARY KEY,
+    type       TEXT NOT NULL,
+    team_name  TEXT NOT NULL,
+    pr_id      TEXT,
+    user_id    TEXT,
+    payload    JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_team
+    ON events (team_name, id);
@@ -0,0 +1,5 @@
+-- Адрес для уведомлений, отказ от ежедневной сводки и время последней отправленной сводки
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email                 TEXT,
+    ADD COLUMN IF NOT EXISTS digest_enabled        BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS digest_last_sent_at   TIMESTAMPTZ;
@@ -0,0 +1,16 @@
+-- Транзакция, в которой изменён список ревьюверов: по ней изменения группируются в одно уведомление
+ALTER TABLE review_assignment_history
+    ADD COLUMN IF NOT EXISTS tx_id BIGINT NOT NULL DEFAULT txid_current();
+
+-- Входящий вебхук чата команды (Slack/Mattermost), канал и состояние отправки:
+-- курсор по истории назначений и окно ограничения частоты сообщений
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS chat_webhook_url  TEXT,
+    ADD COLUMN IF NOT EXISTS chat_channel      TEXT,
+    ADD COLUMN IF NOT EXISTS chat_cursor       BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS chat_window_start TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS chat_window_count INT NOT NULL DEFAULT 0;
+
+-- Имя пользователя в чате для @-упоминаний
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS chat_handle TEXT;
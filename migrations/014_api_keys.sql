@@ -0,0 +1,12 @@
+-- Ключи API: хранится только SHA-256 ключа, префикс — для отображения в списке
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           BIGSERIAL PRIMARY KEY,
+    name         TEXT NOT NULL CHECK (name <> ''),
+    role         TEXT NOT NULL CHECK (role IN ('ADMIN', 'TEAM_LEAD', 'BOT', 'READER')),
+    user_id      TEXT REFERENCES users(user_id) ON DELETE CASCADE,
+    key_prefix   TEXT NOT NULL,
+    key_hash     TEXT NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
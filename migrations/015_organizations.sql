@@ -0,0 +1,131 @@
+-- Организации: данные компаний изолированы, имена команд, идентификаторы пользователей и PR
+-- уникальны только в пределах организации. Существующие данные переходят в организацию default.
+CREATE TABLE IF NOT EXISTS organizations (
+    id           BIGSERIAL PRIMARY KEY,
+    slug         TEXT NOT NULL UNIQUE CHECK (slug ~ '^[a-z0-9][a-z0-9-]*$'),
+    name         TEXT NOT NULL CHECK (name <> ''),
+    chat_team_id TEXT UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO organizations (id, slug, name)
+VALUES (1, 'default', 'Default')
+ON CONFLICT (id) DO NOTHING;
+
+SELECT setval(pg_get_serial_sequence('organizations', 'id'), GREATEST((SELECT MAX(id) FROM organizations), 1));
+
+-- Внешние ключи на глобальные идентификаторы заменяются составными (org_id, ...)
+ALTER TABLE users                     DROP CONSTRAINT IF EXISTS users_team_name_fkey;
+ALTER TABLE pull_requests             DROP CONSTRAINT IF EXISTS pull_requests_author_id_fkey;
+ALTER TABLE pr_reviewers              DROP CONSTRAINT IF EXISTS pr_reviewers_pr_id_fkey,
+                                      DROP CONSTRAINT IF EXISTS pr_reviewers_reviewer_id_fkey;
+ALTER TABLE user_absences             DROP CONSTRAINT IF EXISTS user_absences_user_id_fkey;
+ALTER TABLE review_assignment_history DROP CONSTRAINT IF EXISTS review_assignment_history_pr_id_fkey,
+                                      DROP CONSTRAINT IF EXISTS review_assignment_history_reviewer_id_fkey,
+                                      DROP CONSTRAINT IF EXISTS review_assignment_history_author_id_fkey,
+                                      DROP CONSTRAINT IF EXISTS review_assignment_history_actor_id_fkey;
+ALTER TABLE pr_labels                 DROP CONSTRAINT IF EXISTS pr_labels_pr_id_fkey;
+ALTER TABLE team_review_rules         DROP CONSTRAINT IF EXISTS team_review_rules_team_name_fkey,
+                                      DROP CONSTRAINT IF EXISTS team_review_rules_author_id_fkey,
+                                      DROP CONSTRAINT IF EXISTS team_review_rules_reviewer_id_fkey;
+ALTER TABLE review_declines           DROP CONSTRAINT IF EXISTS review_declines_pr_id_fkey,
+                                      DROP CONSTRAINT IF EXISTS review_declines_reviewer_id_fkey,
+                                      DROP CONSTRAINT IF EXISTS review_declines_replaced_by_fkey;
+ALTER TABLE teams                     DROP CONSTRAINT IF EXISTS teams_lead_id_fkey;
+ALTER TABLE api_keys                  DROP CONSTRAINT IF EXISTS api_keys_user_id_fkey;
+
+-- org_id во всех таблицах; DEFAULT нужен только для переноса существующих строк
+ALTER TABLE teams                     ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE users                     ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE pull_requests             ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE pr_reviewers              ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE user_absences             ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE review_assignment_history ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE pr_labels                 ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE team_review_rules         ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE review_declines           ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE events                    ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE api_keys                  ADD COLUMN IF NOT EXISTS org_id BIGINT NOT NULL DEFAULT 1;
+
+ALTER TABLE teams                     ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE users                     ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pull_requests             ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pr_reviewers              ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE user_absences             ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE review_assignment_history ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pr_labels                 ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE team_review_rules         ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE review_declines           ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE events                    ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE api_keys                  ALTER COLUMN org_id DROP DEFAULT;
+
+-- Уникальность в пределах организации
+ALTER TABLE teams         DROP CONSTRAINT IF EXISTS teams_pkey,         ADD PRIMARY KEY (org_id, team_name);
+ALTER TABLE users         DROP CONSTRAINT IF EXISTS users_pkey,         ADD PRIMARY KEY (org_id, user_id);
+ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_pkey, ADD PRIMARY KEY (org_id, id);
+ALTER TABLE pr_reviewers  DROP CONSTRAINT IF EXISTS pr_reviewers_pkey,  ADD PRIMARY KEY (org_id, pr_id, reviewer_id);
+ALTER TABLE pr_labels     DROP CONSTRAINT IF EXISTS pr_labels_pkey,     ADD PRIMARY KEY (org_id, pr_id, label);
+
+ALTER TABLE teams
+    ADD FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE RESTRICT,
+    ADD FOREIGN KEY (org_id, lead_id) REFERENCES users(org_id, user_id) ON DELETE SET NULL (lead_id);
+
+ALTER TABLE users
+    ADD FOREIGN KEY (org_id, team_name) REFERENCES teams(org_id, team_name) ON DELETE RESTRICT;
+
+ALTER TABLE pull_requests
+    ADD FOREIGN KEY (org_id, author_id) REFERENCES users(org_id, user_id) ON DELETE RESTRICT;
+
+ALTER TABLE pr_reviewers
+    ADD FOREIGN KEY (org_id, pr_id) REFERENCES pull_requests(org_id, id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (org_id, reviewer_id) REFERENCES users(org_id, user_id) ON DELETE RESTRICT;
+
+ALTER TABLE user_absences
+    ADD FOREIGN KEY (org_id, user_id) REFERENCES users(org_id, user_id) ON DELETE CASCADE;
+
+ALTER TABLE review_assignment_history
+    ADD FOREIGN KEY (org_id, pr_id) REFERENCES pull_requests(org_id, id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (org_id, reviewer_id) REFERENCES users(org_id, user_id) ON DELETE RESTRICT,
+    ADD FOREIGN KEY (org_id, author_id) REFERENCES users(org_id, user_id) ON DELETE RESTRICT,
+    ADD FOREIGN KEY (org_id, actor_id) REFERENCES users(org_id, user_id) ON DELETE SET NULL (actor_id);
+
+ALTER TABLE pr_labels
+    ADD FOREIGN KEY (org_id, pr_id) REFERENCES pull_requests(org_id, id) ON DELETE CASCADE;
+
+ALTER TABLE team_review_rules
+    ADD FOREIGN KEY (org_id, team_name) REFERENCES teams(org_id, team_name) ON DELETE CASCADE,
+    ADD FOREIGN KEY (org_id, author_id) REFERENCES users(org_id, user_id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (org_id, reviewer_id) REFERENCES users(org_id, user_id) ON DELETE CASCADE;
+
+ALTER TABLE review_declines
+    ADD FOREIGN KEY (org_id, pr_id) REFERENCES pull_requests(org_id, id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (org_id, reviewer_id) REFERENCES users(org_id, user_id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (org_id, replaced_by) REFERENCES users(org_id, user_id) ON DELETE SET NULL (replaced_by);
+
+ALTER TABLE events
+    ADD FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE api_keys
+    ADD FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (org_id, user_id) REFERENCES users(org_id, user_id) ON DELETE CASCADE;
+
+-- Индексы с учётом организации
+DROP INDEX IF EXISTS idx_users_team_active;
+CREATE INDEX IF NOT EXISTS idx_users_team_active
+    ON users (org_id, team_name, is_active);
+
+DROP INDEX IF EXISTS idx_pr_reviewers_reviewer;
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_reviewer
+    ON pr_reviewers (org_id, reviewer_id);
+
+DROP INDEX IF EXISTS idx_user_absences_external_uid;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_absences_external_uid
+    ON user_absences (org_id, user_id, external_uid)
+    WHERE external_uid IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_events_team;
+CREATE INDEX IF NOT EXISTS idx_events_team
+    ON events (org_id, team_name, id);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_org
+    ON api_keys (org_id, id);
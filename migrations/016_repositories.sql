@@ -0,0 +1,32 @@
+-- Репозитории кода: PR из разных репозиториев различаются по репозиторию и номеру,
+-- а репозиторий может переопределить команду ревьюверов и стратегию выбора.
+CREATE TABLE IF NOT EXISTS repositories (
+    id                 BIGSERIAL PRIMARY KEY,
+    org_id             BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name               TEXT NOT NULL CHECK (name <> ''),
+    code_host          TEXT NOT NULL CHECK (code_host IN ('GITHUB', 'GITLAB', 'BITBUCKET', 'OTHER')),
+    team_name          TEXT NOT NULL,
+    reviewer_team      TEXT,
+    selection_strategy TEXT CHECK (selection_strategy IN ('RANDOM', 'WEIGHTED', 'ROUND_ROBIN')),
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, name),
+    FOREIGN KEY (org_id, team_name) REFERENCES teams(org_id, team_name) ON DELETE RESTRICT,
+    FOREIGN KEY (org_id, reviewer_team) REFERENCES teams(org_id, team_name) ON DELETE SET NULL (reviewer_team)
+);
+
+CREATE INDEX IF NOT EXISTS idx_repositories_team
+    ON repositories (org_id, team_name);
+
+-- PR репозитория идентифицируется номером в нём; PR без репозитория остаются как раньше
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS repository_id BIGINT REFERENCES repositories(id) ON DELETE RESTRICT,
+    ADD COLUMN IF NOT EXISTS number        INTEGER CHECK (number > 0);
+
+ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_repository_number_check;
+ALTER TABLE pull_requests
+    ADD CONSTRAINT pull_requests_repository_number_check CHECK ((repository_id IS NULL) = (number IS NULL));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pull_requests_repository_number
+    ON pull_requests (repository_id, number)
+    WHERE repository_id IS NOT NULL;
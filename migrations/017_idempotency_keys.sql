@@ -0,0 +1,15 @@
+-- Ключи идемпотентности POST-запросов: хеш запроса и сохранённый ответ для повторов.
+-- Пока status_code пуст, запрос с этим ключом ещё выполняется.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    org_id          BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    idempotency_key TEXT NOT NULL CHECK (idempotency_key <> ''),
+    request_hash    TEXT NOT NULL,
+    status_code     INTEGER,
+    content_type    TEXT,
+    response_body   BYTEA,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created
+    ON idempotency_keys (org_id, created_at);
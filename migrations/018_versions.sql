@@ -0,0 +1,7 @@
+-- Версии PR и команд для оптимистичной блокировки: версия отдаётся в ETag, а изменение
+-- с If-Match выполняется, только если версия не изменилась.
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,7 @@
+-- Ключи идемпотентности принадлежат вызывающему (ключу API или пользователю токена), а не всей
+-- организации; вместе с ответом хранится его ETag.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS caller TEXT NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS etag TEXT;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (org_id, caller, idempotency_key);
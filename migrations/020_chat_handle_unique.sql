@@ -0,0 +1,15 @@
+-- Имя в чате однозначно определяет пользователя организации: по нему команды из чата выполняются
+-- от имени пользователя. У повторяющихся имён остаётся только первый по user_id владелец.
+UPDATE users u
+   SET chat_handle = NULL
+ WHERE chat_handle IS NOT NULL
+   AND EXISTS (
+       SELECT 1
+         FROM users o
+        WHERE o.org_id = u.org_id
+          AND o.chat_handle = u.chat_handle
+          AND o.user_id < u.user_id
+   );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_org_chat_handle
+    ON users (org_id, chat_handle) WHERE chat_handle IS NOT NULL;
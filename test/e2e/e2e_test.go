@@ -3,19 +3,38 @@ package e2e
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
+	"pr-reviewer-service/internal/chat"
 	"pr-reviewer-service/internal/config"
+	"pr-reviewer-service/internal/domain"
 	httpapi "pr-reviewer-service/internal/http"
 	"pr-reviewer-service/internal/logging"
+	"pr-reviewer-service/internal/mail"
+	"pr-reviewer-service/internal/mail/smtptest"
+	"pr-reviewer-service/internal/oidc"
 	"pr-reviewer-service/internal/random"
 	"pr-reviewer-service/internal/repository/postgres"
 	"pr-reviewer-service/internal/service"
@@ -34,7 +53,19 @@ type teamCreateResp struct {
 }
 
 type createPRResp struct {
-	PR pullRequestDTO `json:"pr"`
+	PR         pullRequestDTO `json:"pr"`
+	Assignment struct {
+		Reviewers []struct {
+			UserID string `json:"user_id"`
+			Reason string `json:"reason"`
+			RuleID int64  `json:"rule_id"`
+		} `json:"reviewers"`
+		Rules []struct {
+			RuleID  int64  `json:"rule_id"`
+			UserID  string `json:"user_id"`
+			Outcome string `json:"outcome"`
+		} `json:"rules"`
+	} `json:"assignment"`
 }
 
 type mergePRResp struct {
@@ -50,6 +81,8 @@ type pullRequestDTO struct {
 	PullRequestID     string     `json:"pull_request_id"`
 	PullRequestName   string     `json:"pull_request_name"`
 	AuthorID          string     `json:"author_id"`
+	Repository        string     `json:"repository"`
+	Number            int        `json:"number"`
 	Status            string     `json:"status"`
 	AssignedReviewers []string   `json:"assigned_reviewers"`
 	CreatedAt         *time.Time `json:"createdAt"`
@@ -71,12 +104,25 @@ type userReviewResp struct {
 		AuthorID        string `json:"author_id"`
 		Status          string `json:"status"`
 	} `json:"pull_requests"`
+	OpenReviews    int  `json:"open_reviews"`
+	MaxOpenReviews *int `json:"max_open_reviews"`
+}
+
+type absenceListResp struct {
+	UserID   string `json:"user_id"`
+	Absences []struct {
+		AbsenceID int64     `json:"absence_id"`
+		StartsAt  time.Time `json:"starts_at"`
+		EndsAt    time.Time `json:"ends_at"`
+		Source    string    `json:"source"`
+	} `json:"absences"`
 }
 
 type statsResp struct {
 	Stats []struct {
 		UserID      string `json:"user_id"`
 		Assignments int64  `json:"assignments"`
+		Declines    int64  `json:"declines"`
 	} `json:"stats"`
 }
 
@@ -86,8 +132,29 @@ type testEnv struct {
 	server *httptest.Server
 	client *http.Client
 	base   string
+	stale  *service.StaleReviewService
+	users  *postgres.UserRepository
+	prs    *postgres.PullRequestRepository
+	chats  *postgres.ChatRepository
+	// apiKey — ключ, с которым хелперы отправляют запросы (по умолчанию — ключ администратора).
+	apiKey string
+	// token — JWT для заголовка Authorization вместо ключа; oidcKey подписывает токены портала.
+	token   string
+	oidcKey *ecdsa.PrivateKey
+	// chatTeamID — рабочее пространство чата, из которого приходят slash-команды.
+	chatTeamID string
 }
 
+// Секреты тестового окружения: подпись slash-команд и ключ администратора из конфигурации.
+const (
+	slackSigningSecret = "e2e-signing-secret"
+	adminAPIKey        = "e2e-admin-key"
+	oidcIssuer         = "https://portal.e2e"
+	oidcAudience       = "pr-reviewer"
+	// defaultChatTeamID — рабочее пространство чата, связанное с организацией по умолчанию.
+	defaultChatTeamID = "T-DEFAULT"
+)
+
 func setupTestEnv(t *testing.T) *testEnv {
 	t.Helper()
 
@@ -115,24 +182,62 @@ func setupTestEnv(t *testing.T) *testEnv {
 	teamRepo := postgres.NewTeamRepository(db)
 	userRepo := postgres.NewUserRepository(db)
 	prRepo := postgres.NewPullRequestRepository(db)
+	absenceRepo := postgres.NewAbsenceRepository(db)
+	ruleRepo := postgres.NewReviewRuleRepository(db)
+	eventRepo := postgres.NewEventRepository(db)
+	repoRepo := postgres.NewRepoRepository(db)
+	idemRepo := postgres.NewIdempotencyRepository(db)
 
 	randSource := random.NewCryptoRand()
 	logger := logging.NewLogger("test")
 
-	teamSvc := service.NewTeamService(teamRepo, userRepo)
-	userSvc := service.NewUserService(userRepo, prRepo)
-	prSvc := service.NewPullRequestService(prRepo, userRepo, teamRepo, randSource)
-	statsSvc := service.NewStatsService(prRepo)
+	teamSvc := service.NewTeamService(teamRepo, userRepo, ruleRepo)
+	userSvc := service.NewUserService(userRepo, prRepo, teamRepo)
+	prSvc := service.NewPullRequestService(prRepo, userRepo, teamRepo, ruleRepo, repoRepo, randSource)
+	statsSvc := service.NewStatsService(prRepo, userRepo)
+	absenceSvc := service.NewAbsenceService(absenceRepo, userRepo)
+	eventSvc := service.NewEventService(eventRepo, userRepo)
+	repoSvc := service.NewRepoService(repoRepo, teamRepo, userRepo)
+	idemSvc := service.NewIdempotencyService(idemRepo, 24*time.Hour, time.Minute)
+
+	chatOpsSvc := service.NewChatOpsService(userRepo, userSvc, prSvc, absenceSvc)
+	orgRepo := postgres.NewOrganizationRepository(db)
+	keySvc := service.NewAPIKeyService(postgres.NewAPIKeyRepository(db), userRepo, orgRepo, adminAPIKey)
+	orgSvc := service.NewOrganizationService(orgRepo)
+
+	oidcKey, jwksFile := writeJWKS(t)
+	keys, err := oidc.LoadJWKSFile(jwksFile)
 
-	router := httpapi.NewRouter(teamSvc, userSvc, prSvc, statsSvc, logger)
+	if err != nil {
+		t.Fatalf("failed to load JWKS: %v", err)
+	}
+
+	tokenSvc := service.NewTokenAuthService(oidc.NewVerifier(keys, oidcIssuer, oidcAudience, time.Minute), userRepo,
+		service.ClaimMapping{
+			UserClaim: "sub",
+			RoleClaim: "groups",
+			RoleMap:   map[string]domain.Role{"pr-leads": domain.RoleTeamLead, "pr-readers": domain.RoleReader},
+			OrgID:     domain.DefaultOrganizationID,
+		})
+
+	router := httpapi.NewRouter(teamSvc, userSvc, prSvc, repoSvc, statsSvc, absenceSvc, eventSvc,
+		chatOpsSvc, keySvc, orgSvc, idemSvc, tokenSvc, true, slackSigningSecret, logger)
 	ts := httptest.NewServer(router)
 
 	return &testEnv{
-		t:      t,
-		db:     db,
-		server: ts,
-		client: ts.Client(),
-		base:   ts.URL,
+		t:       t,
+		db:      db,
+		server:  ts,
+		client:  ts.Client(),
+		base:    ts.URL,
+		stale:   service.NewStaleReviewService(prRepo, teamRepo, eventRepo, prSvc),
+		users:   userRepo,
+		prs:     prRepo,
+		chats:   postgres.NewChatRepository(db),
+		apiKey:  adminAPIKey,
+		oidcKey: oidcKey,
+
+		chatTeamID: defaultChatTeamID,
 	}
 }
 
@@ -147,17 +252,52 @@ func cleanDB(t *testing.T, db *sql.DB) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	tables := []string{"pr_reviewers", "pull_requests", "users", "teams"}
+	tables := []string{"events", "review_declines", "team_review_rules", "pr_labels", "review_assignment_history", "user_absences", "pr_reviewers", "pull_requests", "repositories", "api_keys", "idempotency_keys", "users", "teams"}
 
 	for _, tbl := range tables {
 		if _, err := db.ExecContext(ctx, "DELETE FROM "+tbl); err != nil {
 			t.Fatalf("failed to clean table %s: %v", tbl, err)
 		}
 	}
+
+	if _, err := db.ExecContext(ctx, "DELETE FROM organizations WHERE id <> $1", domain.DefaultOrganizationID); err != nil {
+		t.Fatalf("failed to clean organizations: %v", err)
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE organizations SET chat_team_id = $2 WHERE id = $1",
+		domain.DefaultOrganizationID, defaultChatTeamID); err != nil {
+		t.Fatalf("failed to link default organization to chat: %v", err)
+	}
 }
 
 // ==== Хелперы HTTP-запросов ====
 
+// authorize добавляет в запрос ключ API или JWT окружения.
+func (env *testEnv) authorize(req *http.Request) {
+	if env.apiKey != "" {
+		req.Header.Set("X-API-Key", env.apiKey)
+	}
+
+	if env.token != "" {
+		req.Header.Set("Authorization", "Bearer "+env.token)
+	}
+}
+
+// as возвращает копию окружения, отправляющую запросы с ключом apiKey (пусто — без ключа).
+func (env *testEnv) as(apiKey string) *testEnv {
+	cp := *env
+	cp.apiKey = apiKey
+	return &cp
+}
+
+// withToken возвращает копию окружения, отправляющую запросы с JWT вместо ключа API.
+func (env *testEnv) withToken(token string) *testEnv {
+	cp := *env
+	cp.apiKey = ""
+	cp.token = token
+	return &cp
+}
+
 func (env *testEnv) postJSON(path string, reqBody any, expectedStatus int, out any) {
 	env.t.Helper()
 
@@ -171,13 +311,21 @@ func (env *testEnv) postJSON(path string, reqBody any, expectedStatus int, out a
 			env.t.Fatalf("failed to marshal request: %v", err)
 		}
 	}
+
+	env.postRaw(path, "application/json", bodyBytes, expectedStatus, out)
+}
+
+func (env *testEnv) postRaw(path, contentType string, bodyBytes []byte, expectedStatus int, out any) {
+	env.t.Helper()
+
 	req, err := http.NewRequest(http.MethodPost, env.base+path, bytes.NewReader(bodyBytes))
 
 	if err != nil {
 		env.t.Fatalf("failed to create request: %v", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
+	env.authorize(req)
 
 	resp, err := env.client.Do(req)
 
@@ -213,6 +361,8 @@ func (env *testEnv) get(path string, expectedStatus int, out any) {
 		env.t.Fatalf("failed to create GET request: %v", err)
 	}
 
+	env.authorize(req)
+
 	resp, err := env.client.Do(req)
 
 	if err != nil {
@@ -473,3 +623,2854 @@ func TestEndToEnd_NoReviewerCandidates(t *testing.T) {
 		t.Fatalf("expected 0 reviewers (no candidates), got %d", len(prCreate.PR.AssignedReviewers))
 	}
 }
+
+// Тест на лимит открытых ревью: при политике FAIL загруженная команда не получает новых назначений.
+func TestEndToEnd_ReviewerCapacity(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	teamReq := map[string]any{
+		"team_name": "capacity",
+		"members": []map[string]any{
+			{"user_id": "c1", "username": "Author", "is_active": true},
+			{"user_id": "c2", "username": "Reviewer1", "is_active": true},
+			{"user_id": "c3", "username": "Reviewer2", "is_active": true},
+		},
+	}
+
+	var teamResp teamCreateResp
+	env.postJSON("/team/add", teamReq, http.StatusCreated, &teamResp)
+
+	settingsReq := map[string]any{
+		"team_name":                "capacity",
+		"default_max_open_reviews": 1,
+		"capacity_policy":          "FAIL",
+	}
+
+	env.postJSON("/team/setSettings", settingsReq, http.StatusOK, nil)
+
+	var prCreate createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-cap-1",
+		"pull_request_name": "First",
+		"author_id":         "c1",
+	}, http.StatusCreated, &prCreate)
+
+	if len(prCreate.PR.AssignedReviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %d", len(prCreate.PR.AssignedReviewers))
+	}
+
+	var errBody errorResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-cap-2",
+		"pull_request_name": "Second",
+		"author_id":         "c1",
+	}, http.StatusConflict, &errBody)
+
+	if errBody.Error.Code != "CAPACITY_EXCEEDED" {
+		t.Fatalf("expected error code CAPACITY_EXCEEDED, got %s", errBody.Error.Code)
+	}
+
+	var reviewResp userReviewResp
+	env.get("/users/getReview?user_id=c2", http.StatusOK, &reviewResp)
+
+	if reviewResp.OpenReviews != 1 {
+		t.Fatalf("expected 1 open review for c2, got %d", reviewResp.OpenReviews)
+	}
+
+	if reviewResp.MaxOpenReviews == nil || *reviewResp.MaxOpenReviews != 1 {
+		t.Fatalf("expected max_open_reviews = 1 for c2, got %v", reviewResp.MaxOpenReviews)
+	}
+}
+
+// Тест на плановые отсутствия: пользователь в отпуске из импортированного календаря не назначается.
+func TestEndToEnd_AbsentReviewerSkipped(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	teamReq := map[string]any{
+		"team_name": "vacations",
+		"members": []map[string]any{
+			{"user_id": "v1", "username": "Author", "is_active": true},
+			{"user_id": "v2", "username": "OnVacation", "is_active": true},
+			{"user_id": "v3", "username": "AtWork", "is_active": true},
+		},
+	}
+
+	var teamResp teamCreateResp
+	env.postJSON("/team/add", teamReq, http.StatusCreated, &teamResp)
+
+	now := time.Now().UTC()
+	calendar := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:vacation-1\r\nSUMMARY:Vacation\r\n" +
+		"DTSTART;VALUE=DATE:" + now.AddDate(0, 0, -1).Format("20060102") + "\r\n" +
+		"DTEND;VALUE=DATE:" + now.AddDate(0, 0, 2).Format("20060102") + "\r\n" +
+		"END:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	var imported absenceListResp
+	env.postRaw("/users/absences/import?user_id=v2", "text/calendar", []byte(calendar), http.StatusOK, &imported)
+
+	if len(imported.Absences) != 1 || imported.Absences[0].Source != "ICS" {
+		t.Fatalf("expected 1 imported absence, got %+v", imported.Absences)
+	}
+
+	// повторный импорт того же календаря не создаёт дубликат
+	env.postRaw("/users/absences/import?user_id=v2", "text/calendar", []byte(calendar), http.StatusOK, nil)
+
+	var listed absenceListResp
+	env.get("/users/absences/list?user_id=v2", http.StatusOK, &listed)
+
+	if len(listed.Absences) != 1 {
+		t.Fatalf("expected 1 absence after re-import, got %d", len(listed.Absences))
+	}
+
+	var prCreate createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-vacation-1",
+		"pull_request_name": "While on vacation",
+		"author_id":         "v1",
+	}, http.StatusCreated, &prCreate)
+
+	if len(prCreate.PR.AssignedReviewers) != 1 || prCreate.PR.AssignedReviewers[0] != "v3" {
+		t.Fatalf("expected only v3 to be assigned, got %v", prCreate.PR.AssignedReviewers)
+	}
+
+	env.postJSON("/users/absences/delete", map[string]any{
+		"absence_id": listed.Absences[0].AbsenceID,
+	}, http.StatusOK, nil)
+}
+
+// Тест на импорт календаря: дни отсутствия отсчитываются в поясе пользователя, повторения отклоняются.
+func TestEndToEnd_ImportCalendarInUserTimezone(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "calendars",
+		"members": []map[string]any{
+			{"user_id": "ic1", "username": "Tokyo", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	env.postJSON("/users/setWorkSchedule", map[string]any{
+		"user_id":  "ic1",
+		"timezone": "Asia/Tokyo",
+	}, http.StatusOK, nil)
+
+	calendar := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:day-off-1\r\nSUMMARY:Day off\r\n" +
+		"DTSTART;VALUE=DATE:20300301\r\nDTEND;VALUE=DATE:20300302\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	var imported absenceListResp
+	env.postRaw("/users/absences/import?user_id=ic1", "text/calendar", []byte(calendar), http.StatusOK, &imported)
+
+	// полночь 1 марта в Токио (UTC+9) — 15:00 UTC 28 февраля
+	wantStart := time.Date(2030, time.February, 28, 15, 0, 0, 0, time.UTC)
+
+	if len(imported.Absences) != 1 || !imported.Absences[0].StartsAt.Equal(wantStart) ||
+		!imported.Absences[0].EndsAt.Equal(wantStart.AddDate(0, 0, 1)) {
+		t.Fatalf("expected absence from %v for one day, got %+v", wantStart, imported.Absences)
+	}
+
+	recurring := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:weekly-1\r\nSUMMARY:Every Friday\r\n" +
+		"DTSTART;VALUE=DATE:20300301\r\nRRULE:FREQ=WEEKLY;BYDAY=FR\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	var errBody errorResp
+	env.postRaw("/users/absences/import?user_id=ic1", "text/calendar", []byte(recurring), http.StatusBadRequest, &errBody)
+
+	if errBody.Error.Code != "VALIDATION_ERROR" {
+		t.Fatalf("expected VALIDATION_ERROR for a recurring event, got %s", errBody.Error.Code)
+	}
+}
+
+// Тест на режим рабочих часов: ревьюверы вне рабочего времени выбираются только при нехватке остальных.
+func TestEndToEnd_PreferWorkingHours(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	teamReq := map[string]any{
+		"team_name": "timezones",
+		"members": []map[string]any{
+			{"user_id": "t1", "username": "Author", "is_active": true},
+			{"user_id": "t2", "username": "Moscow", "is_active": true},
+			{"user_id": "t3", "username": "Novosibirsk", "is_active": true},
+			{"user_id": "t4", "username": "Offline", "is_active": true},
+		},
+	}
+
+	var teamResp teamCreateResp
+	env.postJSON("/team/add", teamReq, http.StatusCreated, &teamResp)
+
+	// рабочее окно t4 гарантированно не содержит текущий момент
+	now := time.Now().UTC()
+	env.postJSON("/users/setWorkSchedule", map[string]any{
+		"user_id":  "t4",
+		"timezone": "UTC",
+		"work_hours": map[string]any{
+			"start": now.Add(6 * time.Hour).Format("15:00"),
+			"end":   now.Add(8 * time.Hour).Format("15:00"),
+			"days":  []string{"MON", "TUE", "WED", "THU", "FRI", "SAT", "SUN"},
+		},
+	}, http.StatusOK, nil)
+
+	env.postJSON("/team/setSettings", map[string]any{
+		"team_name":            "timezones",
+		"capacity_policy":      "ASSIGN_FEWER",
+		"prefer_working_hours": true,
+	}, http.StatusOK, nil)
+
+	for i := 0; i < 5; i++ {
+		var prCreate createPRResp
+		env.postJSON("/pullRequest/create", map[string]any{
+			"pull_request_id":   "pr-hours-" + string(rune('a'+i)),
+			"pull_request_name": "Evening PR",
+			"author_id":         "t1",
+		}, http.StatusCreated, &prCreate)
+
+		for _, rid := range prCreate.PR.AssignedReviewers {
+			if rid == "t4" {
+				t.Fatalf("offline reviewer t4 assigned while others are in working hours")
+			}
+		}
+	}
+}
+
+// Тест на ротацию пар: второй PR того же автора обязательно получает ревьювера, который ещё не ревьюил автора.
+func TestEndToEnd_PairingRotation(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	teamReq := map[string]any{
+		"team_name": "pairing",
+		"members": []map[string]any{
+			{"user_id": "p1", "username": "Author", "is_active": true},
+			{"user_id": "p2", "username": "Reviewer1", "is_active": true},
+			{"user_id": "p3", "username": "Reviewer2", "is_active": true},
+			{"user_id": "p4", "username": "Reviewer3", "is_active": true},
+		},
+	}
+
+	var teamResp teamCreateResp
+	env.postJSON("/team/add", teamReq, http.StatusCreated, &teamResp)
+
+	seen := make(map[string]int)
+
+	for _, id := range []string{"pr-pair-1", "pr-pair-2"} {
+		var prCreate createPRResp
+		env.postJSON("/pullRequest/create", map[string]any{
+			"pull_request_id":   id,
+			"pull_request_name": "Pairing",
+			"author_id":         "p1",
+		}, http.StatusCreated, &prCreate)
+
+		for _, rid := range prCreate.PR.AssignedReviewers {
+			seen[rid]++
+		}
+	}
+
+	if len(seen) != 3 {
+		t.Fatalf("expected all 3 candidates to review the author across 2 PRs, got %v", seen)
+	}
+}
+
+// Тест на правила команды: обязательный ревьювер по метке и запрет пары автор–ревьювер.
+func TestEndToEnd_ReviewRules(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	teamReq := map[string]any{
+		"team_name": "rules",
+		"members": []map[string]any{
+			{"user_id": "r-author", "username": "Author", "is_active": true},
+			{"user_id": "r-lead", "username": "TechLead", "is_active": true},
+			{"user_id": "r-banned", "username": "Banned", "is_active": true},
+			{"user_id": "r-other", "username": "Other", "is_active": true},
+		},
+	}
+
+	var teamResp teamCreateResp
+	env.postJSON("/team/add", teamReq, http.StatusCreated, &teamResp)
+
+	var requireRule struct {
+		Rule struct {
+			RuleID int64 `json:"rule_id"`
+		} `json:"rule"`
+	}
+
+	env.postJSON("/team/rules/add", map[string]any{
+		"team_name":   "rules",
+		"kind":        "REQUIRE",
+		"label":       "security",
+		"reviewer_id": "r-lead",
+		"description": "tech lead reviews security changes",
+	}, http.StatusCreated, &requireRule)
+
+	env.postJSON("/team/rules/add", map[string]any{
+		"team_name":   "rules",
+		"kind":        "EXCLUDE",
+		"author_id":   "r-author",
+		"reviewer_id": "r-banned",
+	}, http.StatusCreated, nil)
+
+	var prCreate createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-rules-1",
+		"pull_request_name": "Fix auth",
+		"author_id":         "r-author",
+		"labels":            []string{"security"},
+	}, http.StatusCreated, &prCreate)
+
+	assigned := make(map[string]bool)
+
+	for _, rid := range prCreate.PR.AssignedReviewers {
+		assigned[rid] = true
+	}
+
+	if !assigned["r-lead"] || !assigned["r-other"] || assigned["r-banned"] {
+		t.Fatalf("expected r-lead and r-other to be assigned, got %v", prCreate.PR.AssignedReviewers)
+	}
+
+	foundRuleReason := false
+
+	for _, r := range prCreate.Assignment.Reviewers {
+		if r.UserID == "r-lead" && r.Reason == "RULE" && r.RuleID == requireRule.Rule.RuleID {
+			foundRuleReason = true
+		}
+	}
+
+	if !foundRuleReason {
+		t.Fatalf("expected r-lead to be explained by rule %d, got %+v", requireRule.Rule.RuleID, prCreate.Assignment)
+	}
+}
+
+// Тест на уровни: правило «в PR джуна нужен сеньор» и взвешенный выбор с нулевым весом.
+func TestEndToEnd_SeniorityWeighted(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	teamReq := map[string]any{
+		"team_name": "levels",
+		"members": []map[string]any{
+			{"user_id": "l-junior", "username": "Junior", "is_active": true, "seniority": "JUNIOR"},
+			{"user_id": "l-senior", "username": "Senior", "is_active": true, "seniority": "SENIOR"},
+			{"user_id": "l-middle", "username": "Middle", "is_active": true, "seniority": "MIDDLE"},
+			{"user_id": "l-idle", "username": "Idle", "is_active": true, "seniority": "MIDDLE", "selection_weight": 0},
+		},
+	}
+
+	var teamResp teamCreateResp
+	env.postJSON("/team/add", teamReq, http.StatusCreated, &teamResp)
+
+	env.postJSON("/team/setSettings", map[string]any{
+		"team_name":           "levels",
+		"selection_strategy":  "WEIGHTED",
+		"pairing_window_days": 0,
+	}, http.StatusOK, nil)
+
+	env.postJSON("/team/rules/add", map[string]any{
+		"team_name":      "levels",
+		"kind":           "REQUIRE",
+		"author_level":   "JUNIOR",
+		"reviewer_level": "SENIOR",
+	}, http.StatusCreated, nil)
+
+	var prCreate createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-levels-1",
+		"pull_request_name": "Junior change",
+		"author_id":         "l-junior",
+	}, http.StatusCreated, &prCreate)
+
+	assigned := make(map[string]bool)
+
+	for _, rid := range prCreate.PR.AssignedReviewers {
+		assigned[rid] = true
+	}
+
+	if len(assigned) != 2 || !assigned["l-senior"] || !assigned["l-middle"] {
+		t.Fatalf("expected l-senior and l-middle to be assigned, got %v", prCreate.PR.AssignedReviewers)
+	}
+
+	var errBody errorResp
+	env.postJSON("/users/setSeniority", map[string]any{
+		"user_id":   "l-idle",
+		"seniority": "PRINCIPAL",
+	}, http.StatusBadRequest, &errBody)
+
+	if errBody.Error.Code != "VALIDATION_ERROR" {
+		t.Fatalf("expected VALIDATION_ERROR, got %s", errBody.Error.Code)
+	}
+}
+
+// Тест на повторное добавление участников: поля, не указанные в /team/add, сохраняют прежние значения.
+func TestEndToEnd_TeamReAddKeepsMemberSettings(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "old",
+		"members": []map[string]any{
+			{"user_id": "m1", "username": "Moved", "is_active": true,
+				"max_open_reviews": 2, "seniority": "SENIOR", "selection_weight": 5},
+		},
+	}, http.StatusCreated, nil)
+
+	// участник переходит в новую команду без лимита, уровня и веса
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "new",
+		"members":   []map[string]any{{"user_id": "m1", "username": "Moved", "is_active": true}},
+	}, http.StatusCreated, nil)
+
+	var team struct {
+		Members []struct {
+			UserID          string `json:"user_id"`
+			MaxOpenReviews  *int   `json:"max_open_reviews"`
+			Seniority       string `json:"seniority"`
+			SelectionWeight *int   `json:"selection_weight"`
+		} `json:"members"`
+	}
+	env.get("/team/get?team_name=new", http.StatusOK, &team)
+
+	if len(team.Members) != 1 {
+		t.Fatalf("expected m1 in the new team, got %+v", team.Members)
+	}
+
+	m := team.Members[0]
+
+	if m.MaxOpenReviews == nil || *m.MaxOpenReviews != 2 || m.Seniority != "SENIOR" ||
+		m.SelectionWeight == nil || *m.SelectionWeight != 5 {
+		t.Fatalf("expected member settings to be kept, got %+v", m)
+	}
+}
+
+// Тест на ротацию: последовательные PR получают ревьюверов строго по очереди,
+// а параллельные создания распределяют нагрузку поровну.
+func TestEndToEnd_RoundRobin(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	teamReq := map[string]any{
+		"team_name": "rotation",
+		"members": []map[string]any{
+			{"user_id": "rr-a", "username": "Author", "is_active": true},
+			{"user_id": "rr-b", "username": "B", "is_active": true},
+			{"user_id": "rr-c", "username": "C", "is_active": true},
+			{"user_id": "rr-d", "username": "D", "is_active": true},
+			{"user_id": "rr-e", "username": "Inactive", "is_active": false},
+		},
+	}
+
+	var teamResp teamCreateResp
+	env.postJSON("/team/add", teamReq, http.StatusCreated, &teamResp)
+
+	env.postJSON("/team/setSettings", map[string]any{
+		"team_name":          "rotation",
+		"selection_strategy": "ROUND_ROBIN",
+	}, http.StatusOK, nil)
+
+	expected := [][]string{{"rr-b", "rr-c"}, {"rr-d", "rr-b"}, {"rr-c", "rr-d"}}
+
+	for i, want := range expected {
+		var prCreate createPRResp
+		env.postJSON("/pullRequest/create", map[string]any{
+			"pull_request_id":   fmt.Sprintf("pr-rr-%d", i),
+			"pull_request_name": "Rotation",
+			"author_id":         "rr-a",
+		}, http.StatusCreated, &prCreate)
+
+		got := prCreate.PR.AssignedReviewers
+
+		if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+			t.Fatalf("PR %d: expected reviewers %v, got %v", i, want, got)
+		}
+	}
+
+	const parallel = 6
+
+	var wg sync.WaitGroup
+	errs := make(chan error, parallel)
+
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			body, _ := json.Marshal(map[string]any{
+				"pull_request_id":   fmt.Sprintf("pr-rr-par-%d", i),
+				"pull_request_name": "Rotation",
+				"author_id":         "rr-a",
+			})
+
+			resp, err := env.client.Post(env.base+"/pullRequest/create", "application/json", bytes.NewReader(body))
+
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != http.StatusCreated {
+				errs <- fmt.Errorf("create PR %d: status %d", i, resp.StatusCode)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"rr-b", "rr-c", "rr-d"} {
+		var review userReviewResp
+		env.get("/users/getReview?user_id="+id, http.StatusOK, &review)
+
+		// 3 + 6 PR по 2 ревьювера на 3 участника
+		if len(review.PullRequests) != 6 {
+			t.Fatalf("expected %s to review 6 PRs, got %d", id, len(review.PullRequests))
+		}
+	}
+}
+
+// Тест на объяснение выбора: причины исключения кандидатов при создании и для существующего PR.
+func TestEndToEnd_ExplainAssignment(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	teamReq := map[string]any{
+		"team_name": "explain",
+		"members": []map[string]any{
+			{"user_id": "x-author", "username": "Author", "is_active": true},
+			{"user_id": "x-free", "username": "Free", "is_active": true},
+			{"user_id": "x-inactive", "username": "Inactive", "is_active": false},
+			{"user_id": "x-away", "username": "Away", "is_active": true},
+		},
+	}
+
+	var teamResp teamCreateResp
+	env.postJSON("/team/add", teamReq, http.StatusCreated, &teamResp)
+
+	now := time.Now().UTC()
+	env.postJSON("/users/absences/add", map[string]any{
+		"user_id":   "x-away",
+		"starts_at": now.Add(-time.Hour),
+		"ends_at":   now.Add(24 * time.Hour),
+		"reason":    "vacation",
+	}, http.StatusCreated, nil)
+
+	var explained struct {
+		Assignment struct {
+			Strategy      string     `json:"strategy"`
+			ReevaluatedAt *time.Time `json:"reevaluated_at"`
+			Candidates    []struct {
+				UserID   string `json:"user_id"`
+				Eligible bool   `json:"eligible"`
+				Excluded string `json:"excluded_reason"`
+				Assigned bool   `json:"assigned"`
+			} `json:"candidates"`
+		} `json:"assignment"`
+	}
+
+	env.postJSON("/pullRequest/create?explain=true", map[string]any{
+		"pull_request_id":   "pr-explain-1",
+		"pull_request_name": "Explain",
+		"author_id":         "x-author",
+	}, http.StatusCreated, &explained)
+
+	reasons := func() map[string]string {
+		res := make(map[string]string)
+
+		for _, c := range explained.Assignment.Candidates {
+			res[c.UserID] = c.Excluded
+		}
+
+		return res
+	}
+
+	want := map[string]string{"x-author": "AUTHOR", "x-free": "", "x-inactive": "INACTIVE", "x-away": "ABSENT"}
+
+	if got := reasons(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("unexpected exclusion reasons on create: got %v, want %v", got, want)
+	}
+
+	if explained.Assignment.Strategy != "RANDOM" {
+		t.Fatalf("expected RANDOM strategy, got %q", explained.Assignment.Strategy)
+	}
+
+	if explained.Assignment.ReevaluatedAt != nil {
+		t.Fatalf("explanation on create must not be marked as re-evaluated")
+	}
+
+	env.get("/pullRequest/explainAssignment?pull_request_id=pr-explain-1", http.StatusOK, &explained)
+
+	if explained.Assignment.ReevaluatedAt == nil {
+		t.Fatalf("explainAssignment must be marked as re-evaluated")
+	}
+
+	want["x-free"] = "ALREADY_ASSIGNED"
+
+	if got := reasons(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("unexpected exclusion reasons on explain: got %v, want %v", got, want)
+	}
+}
+
+// Тест на предпросмотр: ничего не записывает и для ROUND_ROBIN совпадает с последующим созданием.
+func TestEndToEnd_PreviewReviewers(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	teamReq := map[string]any{
+		"team_name": "preview",
+		"members": []map[string]any{
+			{"user_id": "pv-a", "username": "Author", "is_active": true},
+			{"user_id": "pv-b", "username": "B", "is_active": true},
+			{"user_id": "pv-c", "username": "C", "is_active": true},
+			{"user_id": "pv-d", "username": "D", "is_active": true},
+		},
+	}
+
+	var teamResp teamCreateResp
+	env.postJSON("/team/add", teamReq, http.StatusCreated, &teamResp)
+
+	env.postJSON("/team/setSettings", map[string]any{
+		"team_name":          "preview",
+		"selection_strategy": "ROUND_ROBIN",
+	}, http.StatusOK, nil)
+
+	prReq := map[string]any{
+		"pull_request_id":   "pr-preview-1",
+		"pull_request_name": "Preview",
+		"author_id":         "pv-a",
+	}
+
+	var first, second struct {
+		AssignedReviewers []string `json:"assigned_reviewers"`
+	}
+
+	env.postJSON("/pullRequest/previewReviewers", prReq, http.StatusOK, &first)
+	env.postJSON("/pullRequest/previewReviewers", prReq, http.StatusOK, &second)
+
+	if fmt.Sprint(first.AssignedReviewers) != fmt.Sprint(second.AssignedReviewers) || len(first.AssignedReviewers) != 2 {
+		t.Fatalf("expected repeated previews to match, got %v and %v", first.AssignedReviewers, second.AssignedReviewers)
+	}
+
+	var review userReviewResp
+	env.get("/users/getReview?user_id="+first.AssignedReviewers[0], http.StatusOK, &review)
+
+	if len(review.PullRequests) != 0 {
+		t.Fatalf("preview must not create PRs, got %+v", review.PullRequests)
+	}
+
+	var prCreate createPRResp
+	env.postJSON("/pullRequest/create", prReq, http.StatusCreated, &prCreate)
+
+	if fmt.Sprint(prCreate.PR.AssignedReviewers) != fmt.Sprint(first.AssignedReviewers) {
+		t.Fatalf("expected created PR to match preview %v, got %v", first.AssignedReviewers, prCreate.PR.AssignedReviewers)
+	}
+}
+
+// Тест на ручное изменение ревьюверов: лимит max_reviewers, добровольцы и автор изменения в истории.
+func TestEndToEnd_ManualReviewers(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	teamReq := map[string]any{
+		"team_name": "manual",
+		"members": []map[string]any{
+			{"user_id": "mn-a", "username": "Author", "is_active": true},
+			{"user_id": "mn-b", "username": "B", "is_active": true},
+			{"user_id": "mn-c", "username": "C", "is_active": true},
+			{"user_id": "mn-d", "username": "D", "is_active": true},
+		},
+	}
+
+	var teamResp teamCreateResp
+	env.postJSON("/team/add", teamReq, http.StatusCreated, &teamResp)
+
+	var prCreate createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-manual-1",
+		"pull_request_name": "Manual",
+		"author_id":         "mn-a",
+	}, http.StatusCreated, &prCreate)
+
+	if len(prCreate.PR.AssignedReviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", prCreate.PR.AssignedReviewers)
+	}
+
+	assigned := map[string]bool{}
+
+	for _, id := range prCreate.PR.AssignedReviewers {
+		assigned[id] = true
+	}
+
+	var spare string
+
+	for _, id := range []string{"mn-b", "mn-c", "mn-d"} {
+		if !assigned[id] {
+			spare = id
+		}
+	}
+
+	var errBody errorResp
+	env.postJSON("/pullRequest/volunteer", map[string]any{
+		"pull_request_id": "pr-manual-1",
+		"user_id":         spare,
+	}, http.StatusConflict, &errBody)
+
+	if errBody.Error.Code != "TOO_MANY_REVIEWERS" {
+		t.Fatalf("expected TOO_MANY_REVIEWERS, got %s", errBody.Error.Code)
+	}
+
+	removed := prCreate.PR.AssignedReviewers[0]
+	env.postJSON("/pullRequest/removeReviewer", map[string]any{
+		"pull_request_id": "pr-manual-1",
+		"user_id":         removed,
+		"actor_id":        "mn-a",
+	}, http.StatusOK, nil)
+
+	var changed mergePRResp
+	env.postJSON("/pullRequest/volunteer", map[string]any{
+		"pull_request_id": "pr-manual-1",
+		"user_id":         spare,
+	}, http.StatusOK, &changed)
+
+	if len(changed.PR.AssignedReviewers) != 2 {
+		t.Fatalf("expected 2 reviewers after volunteering, got %v", changed.PR.AssignedReviewers)
+	}
+
+	env.postJSON("/pullRequest/addReviewer", map[string]any{
+		"pull_request_id": "pr-manual-1",
+		"user_id":         "mn-a",
+	}, http.StatusConflict, &errBody)
+
+	if errBody.Error.Code != "REVIEWER_IS_AUTHOR" {
+		t.Fatalf("expected REVIEWER_IS_AUTHOR, got %s", errBody.Error.Code)
+	}
+
+	var actor sql.NullString
+
+	if err := env.db.QueryRow(
+		`SELECT actor_id FROM review_assignment_history
+		  WHERE pr_id = $1 AND reviewer_id = $2 AND action = 'UNASSIGNED'`,
+		"pr-manual-1", removed,
+	).Scan(&actor); err != nil {
+		t.Fatalf("select history: %v", err)
+	}
+
+	if actor.String != "mn-a" {
+		t.Fatalf("expected actor mn-a in history, got %q", actor.String)
+	}
+}
+
+// Тест на переназначение на конкретного пользователя с проверкой кандидата.
+func TestEndToEnd_ReassignToSpecificUser(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "target",
+		"members": []map[string]any{
+			{"user_id": "tg-a", "username": "Author", "is_active": true},
+			{"user_id": "tg-b", "username": "B", "is_active": true},
+			{"user_id": "tg-c", "username": "C", "is_active": true},
+			{"user_id": "tg-d", "username": "D", "is_active": true},
+			{"user_id": "tg-off", "username": "Off", "is_active": false},
+		},
+	}, http.StatusCreated, nil)
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "target-other",
+		"members": []map[string]any{
+			{"user_id": "tg-x", "username": "X", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	var prCreate createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-target-1",
+		"pull_request_name": "Target",
+		"author_id":         "tg-a",
+	}, http.StatusCreated, &prCreate)
+
+	old := prCreate.PR.AssignedReviewers[0]
+	other := prCreate.PR.AssignedReviewers[1]
+
+	var spare string
+
+	for _, id := range []string{"tg-b", "tg-c", "tg-d"} {
+		if id != old && id != other {
+			spare = id
+		}
+	}
+
+	cases := []struct {
+		newUserID string
+		code      string
+	}{
+		{"tg-a", "REVIEWER_IS_AUTHOR"},
+		{"tg-off", "REVIEWER_INACTIVE"},
+		{"tg-x", "REVIEWER_NOT_IN_TEAM"},
+		{other, "ALREADY_ASSIGNED"},
+	}
+
+	for _, tc := range cases {
+		var errBody errorResp
+		env.postJSON("/pullRequest/reassign", map[string]any{
+			"pull_request_id": "pr-target-1",
+			"old_user_id":     old,
+			"new_user_id":     tc.newUserID,
+		}, http.StatusConflict, &errBody)
+
+		if errBody.Error.Code != tc.code {
+			t.Fatalf("new_user_id %s: expected %s, got %s", tc.newUserID, tc.code, errBody.Error.Code)
+		}
+	}
+
+	var reassign reassignResp
+	env.postJSON("/pullRequest/reassign", map[string]any{
+		"pull_request_id": "pr-target-1",
+		"old_user_id":     old,
+		"new_user_id":     spare,
+	}, http.StatusOK, &reassign)
+
+	if reassign.ReplacedBy != spare {
+		t.Fatalf("expected replaced_by %s, got %s", spare, reassign.ReplacedBy)
+	}
+}
+
+// Тест: при переназначении автор PR не может стать ревьювером.
+func TestEndToEnd_ReassignNeverPicksAuthor(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "small",
+		"members": []map[string]any{
+			{"user_id": "sm-a", "username": "Author", "is_active": true},
+			{"user_id": "sm-b", "username": "B", "is_active": true},
+			{"user_id": "sm-c", "username": "C", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	var prCreate createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-small-1",
+		"pull_request_name": "Small",
+		"author_id":         "sm-a",
+	}, http.StatusCreated, &prCreate)
+
+	if len(prCreate.PR.AssignedReviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", prCreate.PR.AssignedReviewers)
+	}
+
+	// единственный оставшийся активный участник — автор, поэтому замены нет
+	var errBody errorResp
+	env.postJSON("/pullRequest/reassign", map[string]any{
+		"pull_request_id": "pr-small-1",
+		"old_user_id":     "sm-b",
+	}, http.StatusConflict, &errBody)
+
+	if errBody.Error.Code != "NO_CANDIDATE" {
+		t.Fatalf("expected NO_CANDIDATE, got %s", errBody.Error.Code)
+	}
+}
+
+// Тест на отказ ревьювера: замена подбирается автоматически, отказ попадает в статистику.
+func TestEndToEnd_DeclineReview(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "decline",
+		"members": []map[string]any{
+			{"user_id": "dc-a", "username": "Author", "is_active": true},
+			{"user_id": "dc-b", "username": "B", "is_active": true},
+			{"user_id": "dc-c", "username": "C", "is_active": true},
+			{"user_id": "dc-d", "username": "D", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	var prCreate createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-decline-1",
+		"pull_request_name": "Decline",
+		"author_id":         "dc-a",
+	}, http.StatusCreated, &prCreate)
+
+	decliner := prCreate.PR.AssignedReviewers[0]
+	other := prCreate.PR.AssignedReviewers[1]
+
+	var errBody errorResp
+	env.postJSON("/pullRequest/decline", map[string]any{
+		"pull_request_id": "pr-decline-1",
+		"user_id":         decliner,
+		"reason":          "  ",
+	}, http.StatusBadRequest, &errBody)
+
+	if errBody.Error.Code != "VALIDATION_ERROR" {
+		t.Fatalf("expected VALIDATION_ERROR, got %s", errBody.Error.Code)
+	}
+
+	var declined reassignResp
+	env.postJSON("/pullRequest/decline", map[string]any{
+		"pull_request_id": "pr-decline-1",
+		"user_id":         decliner,
+		"reason":          "on vacation next week",
+	}, http.StatusOK, &declined)
+
+	if declined.ReplacedBy == "" || declined.ReplacedBy == decliner || declined.ReplacedBy == other ||
+		declined.ReplacedBy == "dc-a" {
+		t.Fatalf("unexpected replacement %q", declined.ReplacedBy)
+	}
+
+	// второй ревьювер отказывается, когда заменить уже некем
+	env.postJSON("/pullRequest/decline", map[string]any{
+		"pull_request_id": "pr-decline-1",
+		"user_id":         other,
+		"reason":          "not my area",
+	}, http.StatusOK, &declined)
+
+	if declined.ReplacedBy != "" || len(declined.PR.AssignedReviewers) != 1 {
+		t.Fatalf("expected decline without replacement, got %q and %v", declined.ReplacedBy, declined.PR.AssignedReviewers)
+	}
+
+	var stats statsResp
+	env.get("/stats/assignments", http.StatusOK, &stats)
+
+	for _, s := range stats.Stats {
+		if s.UserID == decliner && s.Declines != 1 {
+			t.Fatalf("expected 1 decline for %s, got %d", decliner, s.Declines)
+		}
+	}
+}
+
+type eventsResp struct {
+	Events []struct {
+		EventID       int64             `json:"event_id"`
+		Type          string            `json:"type"`
+		PullRequestID string            `json:"pull_request_id"`
+		UserID        string            `json:"user_id"`
+		Payload       map[string]string `json:"payload"`
+	} `json:"events"`
+}
+
+// Тест на обработку просроченных ревью: эскалация тимлиду и переназначение.
+func TestEndToEnd_StaleReviews(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "stale",
+		"members": []map[string]any{
+			{"user_id": "st-a", "username": "Author", "is_active": true},
+			{"user_id": "st-b", "username": "B", "is_active": true},
+			{"user_id": "st-c", "username": "C", "is_active": true},
+			{"user_id": "st-lead", "username": "Lead", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	env.postJSON("/team/setSettings", map[string]any{
+		"team_name":        "stale",
+		"review_sla_hours": 24,
+		"stale_action":     "ESCALATE",
+		"lead_id":          "st-lead",
+	}, http.StatusOK, nil)
+
+	var pr1 createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-stale-1",
+		"pull_request_name": "Stale 1",
+		"author_id":         "st-a",
+	}, http.StatusCreated, &pr1)
+
+	reviewed, ignored := pr1.PR.AssignedReviewers[0], pr1.PR.AssignedReviewers[1]
+
+	env.postJSON("/pullRequest/markReviewed", map[string]any{
+		"pull_request_id": "pr-stale-1",
+		"user_id":         reviewed,
+	}, http.StatusOK, nil)
+
+	ctx := domain.WithOrganization(context.Background(), domain.DefaultOrganizationID)
+	later := time.Now().UTC().Add(25 * time.Hour)
+
+	// до истечения SLA ничего не происходит
+	report, err := env.stale.Run(ctx, time.Now().UTC())
+
+	if err != nil || report != (service.StaleReviewReport{}) {
+		t.Fatalf("expected no stale reviews yet, got %+v, %v", report, err)
+	}
+
+	report, err = env.stale.Run(ctx, later)
+
+	if err != nil || report.Escalated != 1 || report.Reassigned != 0 {
+		t.Fatalf("expected 1 escalation, got %+v, %v", report, err)
+	}
+
+	// эскалация не повторяется
+	report, err = env.stale.Run(ctx, later)
+
+	if err != nil || report.Escalated != 0 {
+		t.Fatalf("expected escalation not to repeat, got %+v, %v", report, err)
+	}
+
+	var events eventsResp
+	env.get("/events/list?team_name=stale", http.StatusOK, &events)
+
+	if len(events.Events) != 1 || events.Events[0].Type != "REVIEW_ESCALATED" ||
+		events.Events[0].UserID != "st-lead" || events.Events[0].Payload["reviewer_id"] != ignored {
+		t.Fatalf("unexpected events: %+v", events.Events)
+	}
+
+	env.postJSON("/team/setSettings", map[string]any{
+		"team_name":    "stale",
+		"stale_action": "REASSIGN",
+	}, http.StatusOK, nil)
+
+	var pr2 createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-stale-2",
+		"pull_request_name": "Stale 2",
+		"author_id":         "st-a",
+	}, http.StatusCreated, &pr2)
+
+	report, err = env.stale.Run(ctx, later)
+
+	if err != nil || report.Reassigned != 2 || report.Escalated != 0 {
+		t.Fatalf("expected both reviewers of pr-stale-2 reassigned, got %+v, %v", report, err)
+	}
+
+	env.get(fmt.Sprintf("/events/list?team_name=stale&after_id=%d", events.Events[0].EventID), http.StatusOK, &events)
+
+	for _, e := range events.Events {
+		if e.Type != "STALE_REVIEW_REASSIGNED" || e.PullRequestID != "pr-stale-2" || e.UserID == "st-a" {
+			t.Fatalf("unexpected event after reassignment: %+v", e)
+		}
+	}
+}
+
+type userResp struct {
+	User struct {
+		UserID        string `json:"user_id"`
+		Email         string `json:"email"`
+		DigestEnabled bool   `json:"digest_enabled"`
+	} `json:"user"`
+}
+
+// Тест на ежедневную сводку: письма через SMTP, отписка и не более одной сводки в сутки.
+func TestEndToEnd_ReviewDigest(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	smtpServer, err := smtptest.NewServer()
+
+	if err != nil {
+		t.Fatalf("failed to start smtp server: %v", err)
+	}
+
+	defer func() {
+		_ = smtpServer.Close()
+	}()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "digest",
+		"members": []map[string]any{
+			{"user_id": "dg-a", "username": "Author", "is_active": true},
+			{"user_id": "dg-b", "username": "Bob", "is_active": true},
+			{"user_id": "dg-c", "username": "Carol", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	var errBody errorResp
+	env.postJSON("/users/setNotifications", map[string]any{
+		"user_id": "dg-a",
+		"email":   "not an email",
+	}, http.StatusBadRequest, &errBody)
+
+	for _, id := range []string{"dg-a", "dg-b", "dg-c"} {
+		var user userResp
+		env.postJSON("/users/setNotifications", map[string]any{
+			"user_id": id,
+			"email":   id + "@example.com",
+		}, http.StatusOK, &user)
+
+		if user.User.Email != id+"@example.com" || !user.User.DigestEnabled {
+			t.Fatalf("unexpected notifications of %s: %+v", id, user.User)
+		}
+	}
+
+	// dg-c отписывается, адрес при этом сохраняется
+	var optedOut userResp
+	env.postJSON("/users/setNotifications", map[string]any{
+		"user_id":        "dg-c",
+		"digest_enabled": false,
+	}, http.StatusOK, &optedOut)
+
+	if optedOut.User.Email != "dg-c@example.com" || optedOut.User.DigestEnabled {
+		t.Fatalf("expected dg-c opted out with email kept, got %+v", optedOut.User)
+	}
+
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-digest-1",
+		"pull_request_name": "Digest 1",
+		"author_id":         "dg-a",
+	}, http.StatusCreated, nil)
+
+	mailer := mail.NewSMTPMailer(config.SMTPConfig{
+		Host: smtpServer.Host(),
+		Port: smtpServer.Port(),
+		From: "pr-reviewer@example.com",
+	})
+	digest := service.NewDigestService(env.prs, env.users, mailer, 0)
+
+	ctx := domain.WithOrganization(context.Background(), domain.DefaultOrganizationID)
+	at := time.Now().UTC()
+
+	sent, err := digest.Run(ctx, at)
+
+	if err != nil || sent != 2 {
+		t.Fatalf("expected digests for dg-a and dg-b, got %d, %v", sent, err)
+	}
+
+	// повторный запуск в те же сутки ничего не отправляет
+	if sent, err := digest.Run(ctx, at.Add(time.Minute)); err != nil || sent != 0 {
+		t.Fatalf("expected no repeated digest, got %d, %v", sent, err)
+	}
+
+	messages := smtpServer.Messages()
+
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+
+	for _, m := range messages {
+		if len(m.To) != 1 || m.To[0] == "dg-c@example.com" {
+			t.Fatalf("unexpected recipients: %v", m.To)
+		}
+
+		parsed, err := m.Parse()
+
+		if err != nil {
+			t.Fatalf("failed to parse message: %v", err)
+		}
+
+		text, html := parsed.Bodies["text/plain"], parsed.Bodies["text/html"]
+
+		if !strings.HasPrefix(parsed.Subject, "Сводка по ревью") ||
+			!strings.Contains(text, "pr-digest-1") || !strings.Contains(html, "pr-digest-1") {
+			t.Fatalf("unexpected digest for %v: %q\n%s", m.To, parsed.Subject, text)
+		}
+
+		switch m.To[0] {
+		case "dg-a@example.com":
+			if !strings.Contains(text, "Ваши PR ждут ревью") || !strings.Contains(text, "dg-b") {
+				t.Fatalf("expected awaiting reviewers in author digest:\n%s", text)
+			}
+		case "dg-b@example.com":
+			if !strings.Contains(text, "Ждут вашего ревью") || !strings.Contains(text, "от dg-a") {
+				t.Fatalf("expected pending review in reviewer digest:\n%s", text)
+			}
+		}
+	}
+}
+
+// chatWebhook — входящий вебхук чата, запоминающий полученные сообщения.
+type chatWebhook struct {
+	mu       sync.Mutex
+	messages []map[string]string
+}
+
+func (c *chatWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var msg map[string]string
+
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	c.messages = append(c.messages, msg)
+	c.mu.Unlock()
+}
+
+func (c *chatWebhook) take() []map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res := c.messages
+	c.messages = nil
+	return res
+}
+
+// Тест на уведомления в чат: упоминания, переназначение, сводка при всплеске и ограничение частоты.
+func TestEndToEnd_ChatNotifications(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	webhook := &chatWebhook{}
+	webhookServer := httptest.NewServer(webhook)
+	defer webhookServer.Close()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "chat",
+		"members": []map[string]any{
+			{"user_id": "ch-a", "username": "Alice", "is_active": true},
+			{"user_id": "ch-b", "username": "Bob", "is_active": true},
+			{"user_id": "ch-c", "username": "Carol", "is_active": true},
+			{"user_id": "ch-d", "username": "Dave", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	env.postJSON("/users/setNotifications", map[string]any{
+		"user_id":     "ch-a",
+		"chat_handle": "@alice",
+	}, http.StatusOK, nil)
+
+	// изменения до настройки вебхука в чат не попадают
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-chat-0",
+		"pull_request_name": "Before webhook",
+		"author_id":         "ch-a",
+	}, http.StatusCreated, nil)
+
+	var errBody errorResp
+	env.postJSON("/team/setSettings", map[string]any{
+		"team_name":        "chat",
+		"chat_webhook_url": "not a url",
+	}, http.StatusBadRequest, &errBody)
+
+	var settings struct {
+		Settings struct {
+			ChatWebhookSet bool   `json:"chat_webhook_set"`
+			ChatChannel    string `json:"chat_channel"`
+		} `json:"settings"`
+	}
+	env.postJSON("/team/setSettings", map[string]any{
+		"team_name":        "chat",
+		"chat_webhook_url": webhookServer.URL,
+		"chat_channel":     "#reviews",
+	}, http.StatusOK, &settings)
+
+	if !settings.Settings.ChatWebhookSet || settings.Settings.ChatChannel != "#reviews" {
+		t.Fatalf("unexpected chat settings: %+v", settings.Settings)
+	}
+
+	var pr createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-chat-1",
+		"pull_request_name": "First",
+		"author_id":         "ch-a",
+	}, http.StatusCreated, &pr)
+
+	var reassign reassignResp
+	env.postJSON("/pullRequest/reassign", map[string]any{
+		"pull_request_id": "pr-chat-1",
+		"old_user_id":     pr.PR.AssignedReviewers[0],
+	}, http.StatusOK, &reassign)
+
+	notifier := service.NewChatNotificationService(env.chats, chat.NewWebhookClient(5*time.Second), 3, time.Minute)
+	ctx := domain.WithOrganization(context.Background(), domain.DefaultOrganizationID)
+	at := time.Now().UTC().Add(time.Minute)
+
+	sent, err := notifier.Run(ctx, at)
+
+	if err != nil || sent != 2 {
+		t.Fatalf("expected 2 chat messages, got %d, %v", sent, err)
+	}
+
+	messages := webhook.take()
+
+	if len(messages) != 2 || messages[0]["channel"] != "#reviews" ||
+		!strings.Contains(messages[0]["text"], "@alice") || !strings.Contains(messages[0]["text"], "pr-chat-1") ||
+		strings.Contains(messages[0]["text"], "pr-chat-0") {
+		t.Fatalf("unexpected assignment message: %+v", messages)
+	}
+
+	if !strings.Contains(messages[1]["text"], "передано") {
+		t.Fatalf("expected reassignment message, got %q", messages[1]["text"])
+	}
+
+	// всплеск PR: в окне осталось одно сообщение, все изменения сворачиваются в сводку
+	for i := 2; i <= 5; i++ {
+		env.postJSON("/pullRequest/create", map[string]any{
+			"pull_request_id":   fmt.Sprintf("pr-chat-%d", i),
+			"pull_request_name": "Burst",
+			"author_id":         "ch-a",
+		}, http.StatusCreated, nil)
+	}
+
+	if sent, err := notifier.Run(ctx, at); err != nil || sent != 1 {
+		t.Fatalf("expected 1 summary message, got %d, %v", sent, err)
+	}
+
+	messages = webhook.take()
+
+	for i := 2; i <= 5; i++ {
+		if !strings.Contains(messages[0]["text"], fmt.Sprintf("pr-chat-%d", i)) {
+			t.Fatalf("summary misses pr-chat-%d: %q", i, messages[0]["text"])
+		}
+	}
+
+	// лимит окна исчерпан: новое изменение ждёт следующего окна
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-chat-6",
+		"pull_request_name": "Next window",
+		"author_id":         "ch-a",
+	}, http.StatusCreated, nil)
+
+	if sent, err := notifier.Run(ctx, at.Add(30*time.Second)); err != nil || sent != 0 {
+		t.Fatalf("expected rate limit to hold messages, got %d, %v", sent, err)
+	}
+
+	if sent, err := notifier.Run(ctx, at.Add(2*time.Minute)); err != nil || sent != 1 {
+		t.Fatalf("expected message in the next window, got %d, %v", sent, err)
+	}
+
+	if messages = webhook.take(); len(messages) != 1 || !strings.Contains(messages[0]["text"], "pr-chat-6") {
+		t.Fatalf("unexpected message in the next window: %+v", messages)
+	}
+}
+
+type slashResp struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// slash отправляет slash-команду от имени пользователя чата, подписывая её секретом secret.
+func (env *testEnv) slash(secret, chatUserID, text string, expectedStatus int, out any) {
+	env.t.Helper()
+
+	body := url.Values{
+		"command":   {"/review"},
+		"user_id":   {chatUserID},
+		"user_name": {strings.ToLower(chatUserID)},
+		"text":      {text},
+		"team_id":   {env.chatTeamID},
+	}.Encode()
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte("v0:" + ts + ":" + body))
+
+	req, err := http.NewRequest(http.MethodPost, env.base+"/chat/slash", strings.NewReader(body))
+
+	if err != nil {
+		env.t.Fatalf("failed to build request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := env.client.Do(req)
+
+	if err != nil {
+		env.t.Fatalf("request failed: %v", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != expectedStatus {
+		env.t.Fatalf("POST /chat/slash %q: expected status %d, got %d", text, expectedStatus, resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			env.t.Fatalf("failed to decode response: %v", err)
+		}
+	}
+}
+
+// Тест на slash-команды чата: подпись, мои ревью, переназначение и отсутствие.
+func TestEndToEnd_ChatOpsSlashCommands(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "chatops",
+		"members": []map[string]any{
+			{"user_id": "co-a", "username": "Author", "is_active": true},
+			{"user_id": "co-b", "username": "B", "is_active": true},
+			{"user_id": "co-c", "username": "C", "is_active": true},
+			{"user_id": "co-d", "username": "D", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	// co-b сопоставлен по упоминанию Slack, co-c и co-d — по идентификатору в чате
+	env.postJSON("/users/setNotifications", map[string]any{"user_id": "co-b", "chat_handle": "<@UB>"}, http.StatusOK, nil)
+	env.postJSON("/users/setNotifications", map[string]any{"user_id": "co-c", "chat_handle": "UC"}, http.StatusOK, nil)
+	env.postJSON("/users/setNotifications", map[string]any{"user_id": "co-d", "chat_handle": "UD"}, http.StatusOK, nil)
+	// имя в чате меняет сам пользователь, поэтому по нему никто не сопоставляется
+	env.postJSON("/users/setNotifications", map[string]any{"user_id": "co-a", "chat_handle": "ux"}, http.StatusOK, nil)
+
+	// имя в чате одного пользователя нельзя присвоить другому: иначе команда выполнилась бы не от того
+	var taken errorResp
+	env.postJSON("/users/setNotifications", map[string]any{"user_id": "co-a", "chat_handle": "<@UB>"},
+		http.StatusBadRequest, &taken)
+
+	if taken.Error.Code != "VALIDATION_ERROR" {
+		t.Fatalf("expected VALIDATION_ERROR for a taken chat handle, got %s", taken.Error.Code)
+	}
+
+	var pr createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-chatops-1",
+		"pull_request_name": "ChatOps",
+		"author_id":         "co-a",
+	}, http.StatusCreated, &pr)
+
+	var errBody errorResp
+	env.slash("wrong-secret", "UB", "mine", http.StatusUnauthorized, &errBody)
+
+	if errBody.Error.Code != "UNAUTHORIZED" {
+		t.Fatalf("expected UNAUTHORIZED, got %s", errBody.Error.Code)
+	}
+
+	var resp slashResp
+	env.slash(slackSigningSecret, "UB", "help", http.StatusOK, &resp)
+
+	if resp.ResponseType != "ephemeral" || !strings.Contains(resp.Text, "/review mine") {
+		t.Fatalf("unexpected help response: %+v", resp)
+	}
+
+	env.slash(slackSigningSecret, "UNKNOWN", "mine", http.StatusOK, &resp)
+
+	if !strings.Contains(resp.Text, "no user with chat handle") {
+		t.Fatalf("expected unknown chat user error, got %q", resp.Text)
+	}
+
+	// user_name "ux" совпадает с chat_handle co-a, но вызвавший определяется только по user_id
+	env.slash(slackSigningSecret, "UX", "mine", http.StatusOK, &resp)
+
+	if !strings.Contains(resp.Text, "no user with chat handle") {
+		t.Fatalf("expected user_name to be ignored, got %q", resp.Text)
+	}
+
+	handles := map[string]string{"co-b": "UB", "co-c": "UC", "co-d": "UD"}
+	reviewer := pr.PR.AssignedReviewers[0]
+
+	env.slash(slackSigningSecret, handles[reviewer], "mine", http.StatusOK, &resp)
+
+	if !strings.Contains(resp.Text, "pr-chatops-1") {
+		t.Fatalf("expected pr-chatops-1 in mine, got %q", resp.Text)
+	}
+
+	env.slash(slackSigningSecret, handles[reviewer], "reassign pr-chatops-1", http.StatusOK, &resp)
+
+	var got userReviewResp
+	env.get("/users/getReview?user_id="+reviewer, http.StatusOK, &got)
+
+	if len(got.PullRequests) != 0 || !strings.Contains(resp.Text, "передано") {
+		t.Fatalf("expected reviewer reassigned via chat, got %q and %+v", resp.Text, got.PullRequests)
+	}
+
+	// переназначение из чата записывается от имени вызвавшего
+	var actor sql.NullString
+
+	if err := env.db.QueryRow(
+		`SELECT actor_id FROM review_assignment_history
+		  WHERE pr_id = $1 AND reviewer_id = $2 AND action = 'UNASSIGNED'`,
+		"pr-chatops-1", reviewer,
+	).Scan(&actor); err != nil {
+		t.Fatalf("select history: %v", err)
+	}
+
+	if actor.String != reviewer {
+		t.Fatalf("expected chat reassign recorded by %s, got %q", reviewer, actor.String)
+	}
+
+	// повторное переназначение — ошибка бизнес-логики текстом ответа
+	env.slash(slackSigningSecret, handles[reviewer], "reassign pr-chatops-1", http.StatusOK, &resp)
+
+	if !strings.Contains(resp.Text, "reviewer not assigned") {
+		t.Fatalf("expected NOT_ASSIGNED reply, got %q", resp.Text)
+	}
+
+	until := time.Now().UTC().AddDate(0, 0, 7).Format(time.DateOnly)
+	env.slash(slackSigningSecret, "UB", "away until "+until, http.StatusOK, &resp)
+
+	var absences absenceListResp
+	env.get("/users/absences/list?user_id=co-b", http.StatusOK, &absences)
+
+	if len(absences.Absences) != 1 || !strings.Contains(resp.Text, until) {
+		t.Fatalf("expected absence until %s, got %q and %+v", until, resp.Text, absences.Absences)
+	}
+
+	env.slash(slackSigningSecret, "UB", "away until yesterday", http.StatusOK, &resp)
+
+	if !strings.Contains(resp.Text, "invalid date") {
+		t.Fatalf("expected invalid date reply, got %q", resp.Text)
+	}
+}
+
+type apiKeyResp struct {
+	Key struct {
+		KeyID     int64      `json:"key_id"`
+		Role      string     `json:"role"`
+		Prefix    string     `json:"prefix"`
+		RevokedAt *time.Time `json:"revoked_at"`
+	} `json:"key"`
+	Secret string `json:"secret"`
+}
+
+// Тест на ключи API: аутентификация, проверка ролей по маршрутам и отзыв ключа.
+func TestEndToEnd_APIKeys(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	var errBody errorResp
+	env.as("").get("/team/get?team_name=any", http.StatusUnauthorized, &errBody)
+
+	if errBody.Error.Code != "UNAUTHORIZED" {
+		t.Fatalf("expected UNAUTHORIZED without key, got %s", errBody.Error.Code)
+	}
+
+	env.as("prs_not-a-real-key").get("/team/get?team_name=any", http.StatusUnauthorized, nil)
+
+	env.postJSON("/auth/keys/create", map[string]any{"name": "bad", "role": "ROOT"}, http.StatusBadRequest, nil)
+
+	var reader, bot apiKeyResp
+	env.postJSON("/auth/keys/create", map[string]any{"name": "dashboard", "role": "READER"}, http.StatusCreated, &reader)
+	env.postJSON("/auth/keys/create", map[string]any{"name": "ci", "role": "BOT"}, http.StatusCreated, &bot)
+
+	if !strings.HasPrefix(reader.Secret, "prs_") || !strings.HasPrefix(reader.Secret, reader.Key.Prefix) {
+		t.Fatalf("unexpected key: %+v", reader)
+	}
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "keys",
+		"members": []map[string]any{
+			{"user_id": "k1", "username": "K1", "is_active": true},
+			{"user_id": "k2", "username": "K2", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	// читатель только читает
+	env.as(reader.Secret).get("/team/get?team_name=keys", http.StatusOK, nil)
+	env.as(reader.Secret).postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-keys-1",
+		"pull_request_name": "Keys",
+		"author_id":         "k1",
+	}, http.StatusForbidden, &errBody)
+
+	if errBody.Error.Code != "FORBIDDEN" {
+		t.Fatalf("expected FORBIDDEN for reader, got %s", errBody.Error.Code)
+	}
+
+	// бот работает с PR, но не управляет командами и ключами
+	req, _ := http.NewRequest(http.MethodPost, env.base+"/pullRequest/create", strings.NewReader(
+		`{"pull_request_id":"pr-keys-1","pull_request_name":"Keys","author_id":"k1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+bot.Secret)
+
+	resp, err := env.client.Do(req)
+
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected bot to create PR with bearer key, got %d", resp.StatusCode)
+	}
+
+	env.as(bot.Secret).postJSON("/team/setSettings", map[string]any{"team_name": "keys", "max_reviewers": 1},
+		http.StatusForbidden, nil)
+	env.as(bot.Secret).get("/auth/keys/list", http.StatusForbidden, nil)
+
+	var keys struct {
+		Keys []struct {
+			KeyID  int64  `json:"key_id"`
+			Secret string `json:"secret"`
+		} `json:"keys"`
+	}
+	env.get("/auth/keys/list", http.StatusOK, &keys)
+
+	if len(keys.Keys) != 2 || keys.Keys[0].Secret != "" {
+		t.Fatalf("unexpected key list: %+v", keys.Keys)
+	}
+
+	var revoked apiKeyResp
+	env.postJSON("/auth/keys/revoke", map[string]any{"key_id": reader.Key.KeyID}, http.StatusOK, &revoked)
+
+	if revoked.Key.RevokedAt == nil {
+		t.Fatalf("expected revoked_at to be set: %+v", revoked.Key)
+	}
+
+	env.as(reader.Secret).get("/team/get?team_name=keys", http.StatusUnauthorized, nil)
+	env.postJSON("/auth/keys/revoke", map[string]any{"key_id": 999999}, http.StatusNotFound, nil)
+}
+
+// writeJWKS создаёт ключ подписи портала и записывает его открытую часть в JWKS-файл.
+func writeJWKS(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	jwks, err := json.Marshal(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "EC",
+			"kid": "e2e",
+			"use": "sig",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}},
+	})
+
+	if err != nil {
+		t.Fatalf("encode JWKS: %v", err)
+	}
+
+	path := t.TempDir() + "/jwks.json"
+
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("write JWKS: %v", err)
+	}
+
+	return key, path
+}
+
+// signToken выпускает JWT (ES256) с утверждениями claims, подписанный ключом key.
+func signToken(t *testing.T, key *ecdsa.PrivateKey, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "typ": "JWT", "kid": "e2e"})
+	body, err := json.Marshal(claims)
+
+	if err != nil {
+		t.Fatalf("encode claims: %v", err)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	digest := sha256.Sum256([]byte(signed))
+	r, sigS, err := ecdsa.Sign(rand.Reader, key, digest[:])
+
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	sig := append(r.FillBytes(make([]byte, 32)), sigS.FillBytes(make([]byte, 32))...)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// portalToken выпускает токен портала для пользователя sub с группами groups, действующий час.
+func (env *testEnv) portalToken(sub string, groups ...string) string {
+	return signToken(env.t, env.oidcKey, map[string]any{
+		"iss":    oidcIssuer,
+		"aud":    []string{oidcAudience},
+		"sub":    sub,
+		"groups": groups,
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	})
+}
+
+// Тест на вход по JWT портала: роли из групп, отказ для неверных токенов и автор изменений в истории.
+func TestEndToEnd_OIDCBearerTokens(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "oidc",
+		"members": []map[string]any{
+			{"user_id": "oi-lead", "username": "Lead", "is_active": true},
+			{"user_id": "oi-a", "username": "Author", "is_active": true},
+			{"user_id": "oi-r1", "username": "R1", "is_active": true},
+			{"user_id": "oi-r2", "username": "R2", "is_active": true},
+			{"user_id": "oi-r3", "username": "R3", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	lead := env.withToken(env.portalToken("oi-lead", "staff", "pr-leads"))
+	lead.get("/team/get?team_name=oidc", http.StatusOK, nil)
+
+	var created createPRResp
+	lead.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-oidc-1",
+		"pull_request_name": "OIDC",
+		"author_id":         "oi-a",
+	}, http.StatusCreated, &created)
+
+	if len(created.PR.AssignedReviewers) == 0 {
+		t.Fatalf("expected reviewers to be assigned: %+v", created.PR)
+	}
+
+	old := created.PR.AssignedReviewers[0]
+	lead.postJSON("/pullRequest/reassign", map[string]any{
+		"pull_request_id": "pr-oidc-1",
+		"old_user_id":     old,
+	}, http.StatusOK, nil)
+
+	var actor sql.NullString
+
+	if err := env.db.QueryRow(
+		`SELECT actor_id FROM review_assignment_history
+		  WHERE pr_id = $1 AND reviewer_id = $2 AND action = 'UNASSIGNED'`,
+		"pr-oidc-1", old,
+	).Scan(&actor); err != nil {
+		t.Fatalf("select history: %v", err)
+	}
+
+	if actor.String != "oi-lead" {
+		t.Fatalf("expected actor oi-lead in history, got %q", actor.String)
+	}
+
+	var errBody errorResp
+
+	// читатель не может менять PR
+	env.withToken(env.portalToken("oi-r1", "pr-readers")).postJSON("/pullRequest/merge",
+		map[string]any{"pull_request_id": "pr-oidc-1"}, http.StatusForbidden, &errBody)
+
+	if errBody.Error.Code != "FORBIDDEN" {
+		t.Fatalf("expected FORBIDDEN for reader token, got %s", errBody.Error.Code)
+	}
+
+	// токен без подходящей группы; совпадение с названием роли без сопоставления её не даёт
+	env.withToken(env.portalToken("oi-r2", "staff")).get("/team/get?team_name=oidc", http.StatusForbidden, nil)
+	env.withToken(env.portalToken("oi-r2", "ADMIN")).get("/team/get?team_name=oidc", http.StatusForbidden, nil)
+
+	rejected := map[string]string{
+		"expired": signToken(t, env.oidcKey, map[string]any{
+			"iss": oidcIssuer, "aud": oidcAudience, "sub": "oi-lead", "groups": "ADMIN",
+			"exp": time.Now().Add(-time.Hour).Unix(),
+		}),
+		"wrong audience": signToken(t, env.oidcKey, map[string]any{
+			"iss": oidcIssuer, "aud": "other-service", "sub": "oi-lead", "groups": "ADMIN",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}),
+		"tampered": env.portalToken("oi-lead", "ADMIN") + "x",
+	}
+
+	foreignKey, _ := writeJWKS(t)
+	rejected["foreign key"] = signToken(t, foreignKey, map[string]any{
+		"iss": oidcIssuer, "aud": oidcAudience, "sub": "oi-lead", "groups": "ADMIN",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	for name, token := range rejected {
+		errBody = errorResp{}
+		env.withToken(token).get("/team/get?team_name=oidc", http.StatusUnauthorized, &errBody)
+
+		if errBody.Error.Code != "UNAUTHORIZED" {
+			t.Fatalf("%s: expected UNAUTHORIZED, got %s", name, errBody.Error.Code)
+		}
+	}
+
+	// ключи API по-прежнему принимаются и в Authorization: Bearer
+	env.withToken(adminAPIKey).get("/team/get?team_name=oidc", http.StatusOK, nil)
+}
+
+// Тест на права тимлида: он управляет только своей командой, её участниками и PR и видит её статистику.
+func TestEndToEnd_TeamScopedAuthorization(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	for team, prefix := range map[string]string{"alpha": "al", "beta": "be"} {
+		env.postJSON("/team/add", map[string]any{
+			"team_name": team,
+			"members": []map[string]any{
+				{"user_id": prefix + "-lead", "username": "Lead", "is_active": true},
+				{"user_id": prefix + "-a", "username": "Author", "is_active": true},
+				{"user_id": prefix + "-r1", "username": "R1", "is_active": true},
+				{"user_id": prefix + "-r2", "username": "R2", "is_active": true},
+				{"user_id": prefix + "-r3", "username": "R3", "is_active": true},
+			},
+		}, http.StatusCreated, nil)
+
+		env.postJSON("/pullRequest/create", map[string]any{
+			"pull_request_id":   "pr-" + team,
+			"pull_request_name": team,
+			"author_id":         prefix + "-a",
+		}, http.StatusCreated, nil)
+	}
+
+	var betaRule struct {
+		Rule struct {
+			RuleID int64 `json:"rule_id"`
+		} `json:"rule"`
+	}
+	env.postJSON("/team/rules/add", map[string]any{
+		"team_name": "beta", "kind": "EXCLUDE", "author_id": "be-a", "reviewer_id": "be-r3",
+	}, http.StatusCreated, &betaRule)
+
+	// ключ тимлида обязан быть привязан к пользователю
+	env.postJSON("/auth/keys/create", map[string]any{"name": "lead", "role": "TEAM_LEAD"}, http.StatusBadRequest, nil)
+
+	var key apiKeyResp
+	env.postJSON("/auth/keys/create", map[string]any{"name": "alpha lead", "role": "TEAM_LEAD", "user_id": "al-lead"},
+		http.StatusCreated, &key)
+
+	lead := env.as(key.Secret)
+	forbidden := func(path string, body map[string]any) {
+		t.Helper()
+
+		var errBody errorResp
+		lead.postJSON(path, body, http.StatusForbidden, &errBody)
+
+		if errBody.Error.Code != "FORBIDDEN" {
+			t.Fatalf("%s: expected FORBIDDEN, got %s", path, errBody.Error.Code)
+		}
+	}
+
+	// своя команда
+	lead.postJSON("/team/setSettings", map[string]any{"team_name": "alpha", "max_reviewers": 1}, http.StatusOK, nil)
+	lead.postJSON("/users/setIsActive", map[string]any{"user_id": "al-r3", "is_active": false}, http.StatusOK, nil)
+	lead.postJSON("/pullRequest/reassign", map[string]any{
+		"pull_request_id": "pr-alpha",
+		"old_user_id":     mustReviewer(t, env, "pr-alpha"),
+	}, http.StatusOK, nil)
+	lead.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id": "pr-alpha-2", "pull_request_name": "alpha 2", "author_id": "al-a",
+	}, http.StatusCreated, nil)
+
+	// привязанный к пользователю вызывающий действует только от своего имени
+	alpha2Reviewer := mustReviewer(t, env, "pr-alpha-2")
+	forbidden("/pullRequest/removeReviewer", map[string]any{
+		"pull_request_id": "pr-alpha-2", "user_id": alpha2Reviewer, "actor_id": "al-a",
+	})
+	forbidden("/pullRequest/volunteer", map[string]any{"pull_request_id": "pr-alpha-2", "user_id": "al-r1"})
+	lead.postJSON("/pullRequest/removeReviewer", map[string]any{
+		"pull_request_id": "pr-alpha-2", "user_id": alpha2Reviewer,
+	}, http.StatusOK, nil)
+
+	var actor sql.NullString
+
+	if err := env.db.QueryRow(
+		`SELECT actor_id FROM review_assignment_history
+		  WHERE pr_id = 'pr-alpha-2' AND reviewer_id = $1 AND action = 'UNASSIGNED'`,
+		alpha2Reviewer,
+	).Scan(&actor); err != nil {
+		t.Fatalf("select history: %v", err)
+	}
+
+	if actor.String != "al-lead" {
+		t.Fatalf("expected removal recorded by al-lead, got %q", actor.String)
+	}
+
+	// администратор указывает автора изменения явно
+	env.postJSON("/pullRequest/addReviewer", map[string]any{
+		"pull_request_id": "pr-alpha-2", "user_id": alpha2Reviewer, "actor_id": "al-a",
+	}, http.StatusOK, nil)
+
+	// от ревью отказывается только сам ревьювер, даже если вызывающий из той же команды
+	teammate := "al-r1"
+
+	if alpha2Reviewer == teammate {
+		teammate = "al-r2"
+	}
+
+	decline := map[string]any{"pull_request_id": "pr-alpha-2", "user_id": alpha2Reviewer, "reason": "busy"}
+
+	var errBody errorResp
+	env.withToken(env.portalToken(teammate, "pr-leads")).postJSON("/pullRequest/decline", decline,
+		http.StatusForbidden, &errBody)
+
+	if errBody.Error.Code != "FORBIDDEN" {
+		t.Fatalf("expected FORBIDDEN for decline on behalf of another reviewer, got %s", errBody.Error.Code)
+	}
+
+	env.withToken(env.portalToken(alpha2Reviewer, "pr-leads")).postJSON("/pullRequest/decline", decline,
+		http.StatusOK, nil)
+
+	if err := env.db.QueryRow(
+		`SELECT actor_id FROM review_assignment_history
+		  WHERE pr_id = 'pr-alpha-2' AND reviewer_id = $1 AND action = 'UNASSIGNED'
+		  ORDER BY id DESC LIMIT 1`,
+		alpha2Reviewer,
+	).Scan(&actor); err != nil {
+		t.Fatalf("select history: %v", err)
+	}
+
+	if actor.String != alpha2Reviewer {
+		t.Fatalf("expected decline recorded by %s, got %q", alpha2Reviewer, actor.String)
+	}
+
+	// чужая команда
+	forbidden("/team/add", map[string]any{"team_name": "gamma", "members": []map[string]any{
+		{"user_id": "be-r1", "username": "R1", "is_active": true},
+	}})
+	forbidden("/team/setSettings", map[string]any{"team_name": "beta", "max_reviewers": 1})
+	forbidden("/team/rules/add", map[string]any{
+		"team_name": "beta", "kind": "EXCLUDE", "author_id": "be-a", "reviewer_id": "be-r2",
+	})
+	forbidden("/team/rules/delete", map[string]any{"rule_id": betaRule.Rule.RuleID})
+	forbidden("/users/setIsActive", map[string]any{"user_id": "be-r1", "is_active": false})
+	forbidden("/users/absences/add", map[string]any{
+		"user_id":   "be-r1",
+		"starts_at": time.Now().Add(time.Hour).Format(time.RFC3339),
+		"ends_at":   time.Now().Add(48 * time.Hour).Format(time.RFC3339),
+	})
+	forbidden("/pullRequest/create", map[string]any{
+		"pull_request_id": "pr-beta-2", "pull_request_name": "beta 2", "author_id": "be-a",
+	})
+	forbidden("/pullRequest/reassign", map[string]any{
+		"pull_request_id": "pr-beta",
+		"old_user_id":     mustReviewer(t, env, "pr-beta"),
+	})
+	forbidden("/pullRequest/merge", map[string]any{"pull_request_id": "pr-beta"})
+
+	// статистика — только по своей команде
+	var stats statsResp
+	lead.get("/stats/assignments", http.StatusOK, &stats)
+
+	if len(stats.Stats) == 0 {
+		t.Fatalf("expected alpha stats")
+	}
+
+	for _, s := range stats.Stats {
+		if !strings.HasPrefix(s.UserID, "al-") {
+			t.Fatalf("team lead must see only own team stats, got %s", s.UserID)
+		}
+	}
+
+	lead.get("/stats/assignments?team_name=beta", http.StatusForbidden, nil)
+	lead.get("/events/list?team_name=beta", http.StatusForbidden, nil)
+
+	env.get("/stats/assignments?team_name=beta", http.StatusOK, &stats)
+
+	for _, s := range stats.Stats {
+		if !strings.HasPrefix(s.UserID, "be-") {
+			t.Fatalf("expected only beta stats, got %s", s.UserID)
+		}
+	}
+
+	// тимлид по JWT ограничен так же
+	jwtLead := env.withToken(env.portalToken("al-lead", "pr-leads"))
+	jwtLead.postJSON("/team/setSettings", map[string]any{"team_name": "beta", "max_reviewers": 1},
+		http.StatusForbidden, nil)
+}
+
+// mustReviewer возвращает первого назначенного ревьюера PR.
+func mustReviewer(t *testing.T, env *testEnv, prID string) string {
+	t.Helper()
+
+	pr, err := env.prs.GetByID(domain.WithOrganization(context.Background(), domain.DefaultOrganizationID), prID)
+
+	if err != nil {
+		t.Fatalf("get PR %s: %v", prID, err)
+	}
+
+	if len(pr.AssignedReviewers) == 0 {
+		t.Fatalf("PR %s has no reviewers", prID)
+	}
+
+	return pr.AssignedReviewers[0]
+}
+
+// Тест на организации: одинаковые имена команд, пользователей и PR в разных организациях
+// не пересекаются, а заводить организации может только оператор платформы.
+func TestEndToEnd_Organizations(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	var errBody errorResp
+	env.postJSON("/orgs/create", map[string]any{"slug": "Acme Inc", "name": "Acme"}, http.StatusBadRequest, &errBody)
+
+	if errBody.Error.Code != "VALIDATION_ERROR" {
+		t.Fatalf("expected VALIDATION_ERROR, got %s", errBody.Error.Code)
+	}
+
+	env.postJSON("/orgs/create", map[string]any{"slug": "acme", "name": "Acme", "chat_team_id": "T-ACME"},
+		http.StatusCreated, nil)
+	env.postJSON("/orgs/create", map[string]any{"slug": "acme", "name": "Acme again"}, http.StatusConflict, &errBody)
+
+	if errBody.Error.Code != "ORG_EXISTS" {
+		t.Fatalf("expected ORG_EXISTS, got %s", errBody.Error.Code)
+	}
+
+	var orgs struct {
+		Organizations []struct {
+			Slug string `json:"slug"`
+		} `json:"organizations"`
+	}
+	env.get("/orgs/list", http.StatusOK, &orgs)
+
+	if len(orgs.Organizations) != 2 || orgs.Organizations[0].Slug != "default" || orgs.Organizations[1].Slug != "acme" {
+		t.Fatalf("unexpected organizations: %+v", orgs.Organizations)
+	}
+
+	env.postJSON("/auth/keys/create", map[string]any{"name": "acme", "role": "ADMIN", "org": "nope"},
+		http.StatusNotFound, nil)
+
+	var key apiKeyResp
+	env.postJSON("/auth/keys/create", map[string]any{"name": "acme admin", "role": "ADMIN", "org": "acme"},
+		http.StatusCreated, &key)
+
+	acme := env.as(key.Secret)
+
+	// администратор организации не оператор платформы
+	acme.postJSON("/orgs/create", map[string]any{"slug": "other", "name": "Other"}, http.StatusForbidden, nil)
+	acme.get("/orgs/list", http.StatusForbidden, nil)
+	acme.postJSON("/auth/keys/create", map[string]any{"name": "escape", "role": "ADMIN", "org": "default"},
+		http.StatusForbidden, nil)
+
+	// одни и те же идентификаторы в обеих организациях
+	for _, e := range []*testEnv{env, acme} {
+		e.postJSON("/team/add", map[string]any{
+			"team_name": "backend",
+			"members": []map[string]any{
+				{"user_id": "u1", "username": "Author", "is_active": true},
+				{"user_id": "u2", "username": "R2", "is_active": true},
+				{"user_id": "u3", "username": "R3", "is_active": true},
+			},
+		}, http.StatusCreated, nil)
+
+		e.postJSON("/pullRequest/create", map[string]any{
+			"pull_request_id":   "pr-1",
+			"pull_request_name": "Same id",
+			"author_id":         "u1",
+		}, http.StatusCreated, nil)
+	}
+
+	acme.postJSON("/users/setIsActive", map[string]any{"user_id": "u2", "is_active": false}, http.StatusOK, nil)
+	acme.postJSON("/pullRequest/merge", map[string]any{"pull_request_id": "pr-1"}, http.StatusOK, nil)
+
+	var team struct {
+		Members []struct {
+			UserID   string `json:"user_id"`
+			IsActive bool   `json:"is_active"`
+		} `json:"members"`
+	}
+	env.get("/team/get?team_name=backend", http.StatusOK, &team)
+
+	for _, m := range team.Members {
+		if !m.IsActive {
+			t.Fatalf("deactivation in acme leaked into default organization: %+v", team.Members)
+		}
+	}
+
+	var reviews userReviewResp
+	env.get("/users/getReview?user_id=u2", http.StatusOK, &reviews)
+
+	if len(reviews.PullRequests) != 1 || reviews.PullRequests[0].Status != "OPEN" {
+		t.Fatalf("merge in acme leaked into default organization: %+v", reviews.PullRequests)
+	}
+
+	// ключи видны только в своей организации
+	var keys struct {
+		Keys []struct {
+			Name string `json:"name"`
+		} `json:"keys"`
+	}
+	acme.get("/auth/keys/list", http.StatusOK, &keys)
+
+	if len(keys.Keys) != 1 || keys.Keys[0].Name != "acme admin" {
+		t.Fatalf("unexpected acme keys: %+v", keys.Keys)
+	}
+
+	env.get("/auth/keys/list", http.StatusOK, &keys)
+
+	if len(keys.Keys) != 0 {
+		t.Fatalf("acme keys leaked into default organization: %+v", keys.Keys)
+	}
+
+	// slash-команды выполняются в организации рабочего пространства чата
+	acme.postJSON("/users/setNotifications", map[string]any{"user_id": "u3", "chat_handle": "U3"}, http.StatusOK, nil)
+
+	var resp slashResp
+	env.slash(slackSigningSecret, "U3", "mine", http.StatusOK, &resp)
+
+	if !strings.Contains(resp.Text, "no user with chat handle") {
+		t.Fatalf("expected unknown chat user in default workspace, got %q", resp.Text)
+	}
+
+	inAcme := *env
+	inAcme.chatTeamID = "T-ACME"
+	inAcme.slash(slackSigningSecret, "U3", "mine", http.StatusOK, &resp)
+
+	if strings.Contains(resp.Text, "no user with chat handle") {
+		t.Fatalf("expected u3 to be found in acme workspace, got %q", resp.Text)
+	}
+
+	// пространство без организации не получает доступ ни к чьим данным
+	for _, chatTeamID := range []string{"T-UNKNOWN", ""} {
+		unmapped := *env
+		unmapped.chatTeamID = chatTeamID
+		unmapped.slash(slackSigningSecret, "U3", "mine", http.StatusForbidden, &errBody)
+
+		if errBody.Error.Code != "FORBIDDEN" {
+			t.Fatalf("expected FORBIDDEN for workspace %q, got %s", chatTeamID, errBody.Error.Code)
+		}
+	}
+
+	// привязку пространства меняет только оператор платформы, и одно пространство — одна организация
+	acme.postJSON("/orgs/setChatTeam", map[string]any{"slug": "acme", "chat_team_id": "T-NEW"},
+		http.StatusForbidden, nil)
+	env.postJSON("/orgs/setChatTeam", map[string]any{"slug": "default", "chat_team_id": "T-ACME"},
+		http.StatusConflict, nil)
+	env.postJSON("/orgs/setChatTeam", map[string]any{"slug": "acme", "chat_team_id": "T-NEW"},
+		http.StatusOK, nil)
+	inAcme.slash(slackSigningSecret, "U3", "mine", http.StatusForbidden, nil)
+
+	inAcme.chatTeamID = "T-NEW"
+	inAcme.slash(slackSigningSecret, "U3", "mine", http.StatusOK, &resp)
+}
+
+// ==== Репозитории ====
+
+func TestEndToEnd_Repositories(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "R2", "is_active": true},
+			{"user_id": "u3", "username": "R3", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "platform",
+		"members": []map[string]any{
+			{"user_id": "p1", "username": "P1", "is_active": true},
+			{"user_id": "p2", "username": "P2", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	var errBody errorResp
+	env.postJSON("/repositories/add", map[string]any{"name": "acme/api", "code_host": "SVN", "team_name": "backend"},
+		http.StatusBadRequest, &errBody)
+
+	if errBody.Error.Code != "VALIDATION_ERROR" {
+		t.Fatalf("expected VALIDATION_ERROR for unknown code host, got %s", errBody.Error.Code)
+	}
+
+	env.postJSON("/repositories/add", map[string]any{"name": "acme/api", "code_host": "GITHUB", "team_name": "nope"},
+		http.StatusNotFound, nil)
+
+	env.postJSON("/repositories/add", map[string]any{"name": "acme/api", "code_host": "GITHUB", "team_name": "backend"},
+		http.StatusCreated, nil)
+	env.postJSON("/repositories/add", map[string]any{"name": "acme/api", "code_host": "GITLAB", "team_name": "backend"},
+		http.StatusConflict, &errBody)
+
+	if errBody.Error.Code != "REPOSITORY_EXISTS" {
+		t.Fatalf("expected REPOSITORY_EXISTS, got %s", errBody.Error.Code)
+	}
+
+	// ревью web отдано команде platform
+	env.postJSON("/repositories/add", map[string]any{
+		"name":          "acme/web",
+		"code_host":     "GITHUB",
+		"team_name":     "backend",
+		"reviewer_team": "platform",
+	}, http.StatusCreated, nil)
+
+	// один и тот же номер в двух репозиториях не конфликтует
+	var api, web createPRResp
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_name": "API fix",
+		"author_id":         "u1",
+		"repository":        "acme/api",
+		"number":            1,
+	}, http.StatusCreated, &api)
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "acme/web#1",
+		"pull_request_name": "Web fix",
+		"author_id":         "u1",
+		"repository":        "acme/web",
+		"number":            1,
+	}, http.StatusCreated, &web)
+
+	if api.PR.PullRequestID != "acme/api#1" || api.PR.Repository != "acme/api" || api.PR.Number != 1 {
+		t.Fatalf("unexpected api PR: %+v", api.PR)
+	}
+
+	for _, id := range api.PR.AssignedReviewers {
+		if id != "u2" && id != "u3" {
+			t.Fatalf("expected backend reviewers on acme/api, got %v", api.PR.AssignedReviewers)
+		}
+	}
+
+	if len(web.PR.AssignedReviewers) != 2 {
+		t.Fatalf("expected two platform reviewers on acme/web, got %v", web.PR.AssignedReviewers)
+	}
+
+	for _, id := range web.PR.AssignedReviewers {
+		if id != "p1" && id != "p2" {
+			t.Fatalf("expected platform reviewers on acme/web, got %v", web.PR.AssignedReviewers)
+		}
+	}
+
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_name": "Again",
+		"author_id":         "u1",
+		"repository":        "acme/api",
+		"number":            1,
+	}, http.StatusConflict, &errBody)
+
+	if errBody.Error.Code != "PR_EXISTS" {
+		t.Fatalf("expected PR_EXISTS, got %s", errBody.Error.Code)
+	}
+
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-2",
+		"pull_request_name": "Mismatch",
+		"author_id":         "u1",
+		"repository":        "acme/api",
+		"number":            2,
+	}, http.StatusBadRequest, nil)
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_name": "Unknown",
+		"author_id":         "u1",
+		"repository":        "acme/nope",
+		"number":            1,
+	}, http.StatusNotFound, nil)
+
+	// ручное назначение проверяет команду ревьюверов репозитория
+	env.postJSON("/pullRequest/addReviewer", map[string]any{"pull_request_id": "acme/web#1", "reviewer_id": "u2"},
+		http.StatusConflict, &errBody)
+
+	if errBody.Error.Code != "REVIEWER_NOT_IN_TEAM" {
+		t.Fatalf("expected REVIEWER_NOT_IN_TEAM, got %s", errBody.Error.Code)
+	}
+
+	// стратегия репозитория переопределяет стратегию команды и сбрасывается null
+	var repo struct {
+		Repository struct {
+			ReviewerTeam      string `json:"reviewer_team"`
+			SelectionStrategy string `json:"selection_strategy"`
+		} `json:"repository"`
+	}
+	env.postJSON("/repositories/setOverrides", map[string]any{"name": "acme/api", "selection_strategy": "ROUND_ROBIN"},
+		http.StatusOK, &repo)
+
+	if repo.Repository.SelectionStrategy != "ROUND_ROBIN" {
+		t.Fatalf("expected ROUND_ROBIN override, got %+v", repo.Repository)
+	}
+
+	var preview struct {
+		Assignment struct {
+			Strategy string `json:"strategy"`
+		} `json:"assignment"`
+	}
+	env.postJSON("/pullRequest/previewReviewers?explain=true", map[string]any{"author_id": "u1", "repository": "acme/api"},
+		http.StatusOK, &preview)
+
+	if preview.Assignment.Strategy != "ROUND_ROBIN" {
+		t.Fatalf("expected repository strategy in preview, got %q", preview.Assignment.Strategy)
+	}
+
+	env.postJSON("/repositories/setOverrides", map[string]any{"name": "acme/api", "selection_strategy": nil},
+		http.StatusOK, &repo)
+
+	if repo.Repository.SelectionStrategy != "" {
+		t.Fatalf("expected override to be reset, got %+v", repo.Repository)
+	}
+
+	var list struct {
+		Repositories []struct {
+			Name string `json:"name"`
+		} `json:"repositories"`
+	}
+	env.get("/repositories/list?team_name=backend", http.StatusOK, &list)
+
+	if len(list.Repositories) != 2 || list.Repositories[0].Name != "acme/api" || list.Repositories[1].Name != "acme/web" {
+		t.Fatalf("unexpected repositories: %+v", list.Repositories)
+	}
+
+	env.get("/repositories/get?name=acme/web", http.StatusOK, &repo)
+
+	if repo.Repository.ReviewerTeam != "platform" {
+		t.Fatalf("expected platform reviewer team, got %+v", repo.Repository)
+	}
+}
+
+// ==== Ключи идемпотентности ====
+
+// postIdempotent отправляет POST с заголовком Idempotency-Key и возвращает заголовки и тело ответа.
+func (env *testEnv) postIdempotent(path, key string, reqBody any, expectedStatus int) (http.Header, []byte) {
+	env.t.Helper()
+
+	bodyBytes, err := json.Marshal(reqBody)
+
+	if err != nil {
+		env.t.Fatalf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, env.base+path, bytes.NewReader(bodyBytes))
+
+	if err != nil {
+		env.t.Fatalf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	env.authorize(req)
+
+	resp, err := env.client.Do(req)
+
+	if err != nil {
+		env.t.Fatalf("request failed: %v", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		env.t.Fatalf("failed to read response for %s: %v", path, err)
+	}
+
+	if resp.StatusCode != expectedStatus {
+		env.t.Fatalf("unexpected status for POST %s: got %d, want %d, body=%s",
+			path, resp.StatusCode, expectedStatus, body)
+	}
+
+	return resp.Header, body
+}
+
+func TestEndToEnd_IdempotencyKeys(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "R2", "is_active": true},
+			{"user_id": "u3", "username": "R3", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	create := map[string]any{
+		"pull_request_id":   "pr-1",
+		"pull_request_name": "Retried",
+		"author_id":         "u1",
+	}
+
+	firstHeader, first := env.postIdempotent("/pullRequest/create", "ci-run-1", create, http.StatusCreated)
+
+	if firstHeader.Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first request must not be a replay")
+	}
+
+	// повтор после таймаута получает исходный ответ, а не PR_EXISTS
+	retryHeader, retry := env.postIdempotent("/pullRequest/create", "ci-run-1", create, http.StatusCreated)
+
+	if retryHeader.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected replayed response, headers: %v", retryHeader)
+	}
+
+	if !bytes.Equal(first, retry) {
+		t.Fatalf("replayed body differs:\n%s\n%s", first, retry)
+	}
+
+	if etag := firstHeader.Get("ETag"); etag == "" || retryHeader.Get("ETag") != etag {
+		t.Fatalf("expected replayed ETag %q, got %q", etag, retryHeader.Get("ETag"))
+	}
+
+	// тот же ключ другого вызывающего — другой запрос
+	var other apiKeyResp
+	env.postJSON("/auth/keys/create", map[string]any{"name": "second admin", "role": "ADMIN"},
+		http.StatusCreated, &other)
+
+	otherHeader, _ := env.as(other.Secret).postIdempotent("/pullRequest/create", "ci-run-1", create,
+		http.StatusConflict)
+
+	if otherHeader.Get("Idempotent-Replayed") != "" {
+		t.Fatalf("key of another caller must not be replayed")
+	}
+
+	// отказ в доступе не занимает ключ
+	var reader apiKeyResp
+	env.postJSON("/auth/keys/create", map[string]any{"name": "reader", "role": "READER"},
+		http.StatusCreated, &reader)
+	env.as(reader.Secret).postIdempotent("/pullRequest/merge", "denied", map[string]any{"pull_request_id": "pr-1"},
+		http.StatusForbidden)
+
+	var stored int
+
+	if err := env.db.QueryRow(`SELECT COUNT(*) FROM idempotency_keys WHERE idempotency_key = 'denied'`).
+		Scan(&stored); err != nil {
+		t.Fatalf("count idempotency keys: %v", err)
+	}
+
+	if stored != 0 {
+		t.Fatalf("forbidden request must not be stored, got %d records", stored)
+	}
+
+	// тот же ключ с другим телом
+	create["pull_request_name"] = "Changed"
+	_, body := env.postIdempotent("/pullRequest/create", "ci-run-1", create, http.StatusUnprocessableEntity)
+
+	var errBody errorResp
+
+	if err := json.Unmarshal(body, &errBody); err != nil || errBody.Error.Code != "IDEMPOTENCY_KEY_REUSED" {
+		t.Fatalf("expected IDEMPOTENCY_KEY_REUSED, got %s", body)
+	}
+
+	// без ключа повтор по-прежнему конфликтует
+	env.postJSON("/pullRequest/create", create, http.StatusConflict, nil)
+
+	// сохраняются и ответы с ошибкой клиента
+	env.postIdempotent("/pullRequest/merge", "merge-missing", map[string]any{"pull_request_id": "nope"},
+		http.StatusNotFound)
+	env.postIdempotent("/pullRequest/merge", "merge-missing", map[string]any{"pull_request_id": "nope"},
+		http.StatusNotFound)
+
+	// незавершённый запрос держит ключ, пока не истечёт аренда; брошенный ключ занимает повтор
+	markInFlight := func(startedAgo string) {
+		if _, err := env.db.Exec(
+			`UPDATE idempotency_keys
+			    SET status_code = NULL, response_body = NULL, created_at = NOW() - $1::interval
+			  WHERE idempotency_key = 'merge-missing'`, startedAgo,
+		); err != nil {
+			t.Fatalf("mark key in flight: %v", err)
+		}
+	}
+
+	markInFlight("1 second")
+	_, body = env.postIdempotent("/pullRequest/merge", "merge-missing", map[string]any{"pull_request_id": "nope"},
+		http.StatusConflict)
+
+	if err := json.Unmarshal(body, &errBody); err != nil || errBody.Error.Code != "IDEMPOTENCY_KEY_IN_FLIGHT" {
+		t.Fatalf("expected IDEMPOTENCY_KEY_IN_FLIGHT, got %s", body)
+	}
+
+	markInFlight("2 minutes")
+	staleHeader, _ := env.postIdempotent("/pullRequest/merge", "merge-missing",
+		map[string]any{"pull_request_id": "nope"}, http.StatusNotFound)
+
+	if staleHeader.Get("Idempotent-Replayed") != "" {
+		t.Fatalf("abandoned key must be executed again")
+	}
+
+	// ключи разных организаций не пересекаются
+	env.postJSON("/orgs/create", map[string]any{"slug": "acme", "name": "Acme"}, http.StatusCreated, nil)
+
+	var key apiKeyResp
+	env.postJSON("/auth/keys/create", map[string]any{"name": "acme admin", "role": "ADMIN", "org": "acme"},
+		http.StatusCreated, &key)
+
+	env.as(key.Secret).postIdempotent("/team/add", "ci-run-1", map[string]any{
+		"team_name": "backend",
+		"members":   []map[string]any{{"user_id": "u1", "username": "Author", "is_active": true}},
+	}, http.StatusCreated)
+}
+
+// sendIfMatch отправляет запрос с заголовком If-Match (пусто — без него) и возвращает заголовки
+// и тело ответа.
+func (env *testEnv) sendIfMatch(method, path, ifMatch string, reqBody any, expectedStatus int) (http.Header, []byte) {
+	env.t.Helper()
+
+	var body io.Reader
+
+	if reqBody != nil {
+		bodyBytes, err := json.Marshal(reqBody)
+
+		if err != nil {
+			env.t.Fatalf("failed to marshal request: %v", err)
+		}
+
+		body = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequest(method, env.base+path, body)
+
+	if err != nil {
+		env.t.Fatalf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+
+	env.authorize(req)
+
+	resp, err := env.client.Do(req)
+
+	if err != nil {
+		env.t.Fatalf("request failed: %v", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		env.t.Fatalf("failed to read response for %s: %v", path, err)
+	}
+
+	if resp.StatusCode != expectedStatus {
+		env.t.Fatalf("unexpected status for %s %s: got %d, want %d, body=%s",
+			method, path, resp.StatusCode, expectedStatus, respBody)
+	}
+
+	return resp.Header, respBody
+}
+
+// Тест на оптимистичную блокировку: чтение отдаёт версию в ETag, изменение с устаревшим
+// If-Match отклоняется с 412, а успешное изменение выдаёт новый ETag.
+func TestEndToEnd_OptimisticConcurrency(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "R2", "is_active": true},
+			{"user_id": "u3", "username": "R3", "is_active": true},
+			{"user_id": "u4", "username": "R4", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-1",
+		"pull_request_name": "Contended",
+		"author_id":         "u1",
+	}, http.StatusCreated, nil)
+
+	header, _ := env.sendIfMatch(http.MethodGet, "/pullRequest/get?pull_request_id=pr-1", "", nil, http.StatusOK)
+	readTag := header.Get("ETag")
+
+	if readTag != `"1"` {
+		t.Fatalf("expected ETag \"1\" for new PR, got %q", readTag)
+	}
+
+	// первый бот переназначает ревьюера по прочитанной версии
+	reviewer := mustReviewer(t, env, "pr-1")
+	reassign := map[string]any{"pull_request_id": "pr-1", "old_user_id": reviewer}
+
+	header, _ = env.sendIfMatch(http.MethodPost, "/pullRequest/reassign", readTag, reassign, http.StatusOK)
+	newTag := header.Get("ETag")
+
+	if newTag == "" || newTag == readTag {
+		t.Fatalf("expected new ETag after reassign, got %q", newTag)
+	}
+
+	// второй бот прочитал PR раньше и получает 412, а не перезапись
+	_, body := env.sendIfMatch(http.MethodPost, "/pullRequest/removeReviewer", readTag, map[string]any{
+		"pull_request_id": "pr-1",
+		"user_id":         mustReviewer(t, env, "pr-1"),
+	}, http.StatusPreconditionFailed)
+
+	var errBody errorResp
+
+	if err := json.Unmarshal(body, &errBody); err != nil || errBody.Error.Code != "PRECONDITION_FAILED" {
+		t.Fatalf("expected PRECONDITION_FAILED, got %s", body)
+	}
+
+	// слабый ETag не принимается
+	env.sendIfMatch(http.MethodPost, "/pullRequest/merge", "W/"+newTag,
+		map[string]any{"pull_request_id": "pr-1"}, http.StatusPreconditionFailed)
+
+	header, _ = env.sendIfMatch(http.MethodPost, "/pullRequest/merge", newTag,
+		map[string]any{"pull_request_id": "pr-1"}, http.StatusOK)
+	mergedTag := header.Get("ETag")
+
+	// повторный merge идемпотентен, но устаревшая версия всё равно отклоняется
+	env.sendIfMatch(http.MethodPost, "/pullRequest/merge", newTag,
+		map[string]any{"pull_request_id": "pr-1"}, http.StatusPreconditionFailed)
+	env.sendIfMatch(http.MethodPost, "/pullRequest/merge", mergedTag,
+		map[string]any{"pull_request_id": "pr-1"}, http.StatusOK)
+	env.sendIfMatch(http.MethodPost, "/pullRequest/merge", "*",
+		map[string]any{"pull_request_id": "pr-1"}, http.StatusOK)
+
+	// после merge ревьюверов нельзя менять ни по устаревшей версии, ни в обход проверки сервиса:
+	// статус PR проверяется и под блокировкой строки
+	merged := mustReviewer(t, env, "pr-1")
+
+	for _, tag := range []string{newTag, mergedTag} {
+		_, body = env.sendIfMatch(http.MethodPost, "/pullRequest/reassign", tag,
+			map[string]any{"pull_request_id": "pr-1", "old_user_id": merged}, http.StatusConflict)
+
+		if err := json.Unmarshal(body, &errBody); err != nil || errBody.Error.Code != "PR_MERGED" {
+			t.Fatalf("expected PR_MERGED on reassign after merge, got %s", body)
+		}
+
+		_, body = env.sendIfMatch(http.MethodPost, "/pullRequest/decline", tag,
+			map[string]any{"pull_request_id": "pr-1", "user_id": merged, "reason": "too late"}, http.StatusConflict)
+
+		if err := json.Unmarshal(body, &errBody); err != nil || errBody.Error.Code != "PR_MERGED" {
+			t.Fatalf("expected PR_MERGED on decline after merge, got %s", body)
+		}
+	}
+
+	ctx := domain.WithOrganization(context.Background(), domain.DefaultOrganizationID)
+
+	if _, err := env.prs.ReassignReviewer(ctx, "pr-1", merged, "u4"); !errors.Is(err, domain.ErrPRMerged) {
+		t.Fatalf("expected ErrPRMerged from reassign under lock, got %v", err)
+	}
+
+	if _, err := env.prs.RemoveReviewer(ctx, "pr-1", merged, ""); !errors.Is(err, domain.ErrPRMerged) {
+		t.Fatalf("expected ErrPRMerged from remove under lock, got %v", err)
+	}
+
+	decline := domain.ReviewDecline{PRID: "pr-1", ReviewerID: merged, Reason: "late"}
+
+	if _, err := env.prs.DeclineReview(ctx, decline); !errors.Is(err, domain.ErrPRMerged) {
+		t.Fatalf("expected ErrPRMerged from decline under lock, got %v", err)
+	}
+
+	// настройки команды: версия для If-Match — settings.version, а ETag /team/get зависит от содержимого
+	var team struct {
+		Settings struct {
+			Version int64 `json:"version"`
+		} `json:"settings"`
+	}
+
+	header, body = env.sendIfMatch(http.MethodGet, "/team/get?team_name=backend", "", nil, http.StatusOK)
+	readTeamTag := header.Get("ETag")
+
+	if err := json.Unmarshal(body, &team); err != nil || team.Settings.Version == 0 {
+		t.Fatalf("expected settings version in team, got %s", body)
+	}
+
+	teamTag := fmt.Sprintf(`"%d"`, team.Settings.Version)
+
+	// ETag чтения не подходит для If-Match
+	env.sendIfMatch(http.MethodPost, "/team/setSettings", readTeamTag,
+		map[string]any{"team_name": "backend", "max_reviewers": 1}, http.StatusPreconditionFailed)
+
+	// смена участников меняет ETag команды
+	env.postJSON("/users/setIsActive", map[string]any{"user_id": "u4", "is_active": false}, http.StatusOK, nil)
+	header, _ = env.sendIfMatch(http.MethodGet, "/team/get?team_name=backend", "", nil, http.StatusOK)
+
+	if header.Get("ETag") == readTeamTag {
+		t.Fatalf("team ETag did not change after member update: %q", readTeamTag)
+	}
+
+	header, _ = env.sendIfMatch(http.MethodPost, "/team/setSettings", teamTag,
+		map[string]any{"team_name": "backend", "max_reviewers": 1}, http.StatusOK)
+	updatedTeamTag := header.Get("ETag")
+
+	if updatedTeamTag == "" || updatedTeamTag == teamTag {
+		t.Fatalf("expected new settings ETag after setSettings, got %q", updatedTeamTag)
+	}
+
+	env.sendIfMatch(http.MethodPost, "/team/setSettings", teamTag,
+		map[string]any{"team_name": "backend", "max_reviewers": 3}, http.StatusPreconditionFailed)
+
+	_, body = env.sendIfMatch(http.MethodGet, "/team/get?team_name=backend", "", nil, http.StatusOK)
+
+	if err := json.Unmarshal(body, &team); err != nil || fmt.Sprintf(`"%d"`, team.Settings.Version) != updatedTeamTag {
+		t.Fatalf("settings version changed after rejected update: %s, want %s", body, updatedTeamTag)
+	}
+
+	// без If-Match изменения выполняются как раньше
+	env.postJSON("/team/setSettings", map[string]any{"team_name": "backend", "max_reviewers": 2},
+		http.StatusOK, nil)
+}
+
+// Тест на одновременное создание одного PR: ровно один запрос создаёт PR, остальные получают
+// PR_EXISTS, а не INTERNAL, и у PR остаётся одна история назначения.
+func TestEndToEnd_ConcurrentCreatePR(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Author", "is_active": true},
+			{"user_id": "u2", "username": "R2", "is_active": true},
+			{"user_id": "u3", "username": "R3", "is_active": true},
+			{"user_id": "u4", "username": "R4", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+	env.postJSON("/repositories/add", map[string]any{"name": "acme/api", "code_host": "GITHUB", "team_name": "backend"},
+		http.StatusCreated, nil)
+
+	// проигравшие гонку запросы не сдвигают очередь: курсор сдвигается вместе с вставкой PR
+	env.postJSON("/team/setSettings", map[string]any{"team_name": "backend", "selection_strategy": "ROUND_ROBIN"},
+		http.StatusOK, nil)
+
+	const (
+		rounds   = 10
+		parallel = 8
+	)
+
+	rotation := []string{"u2", "u3", "u4"}
+
+	for round := 0; round < rounds; round++ {
+		req := map[string]any{
+			"pull_request_id":   fmt.Sprintf("pr-race-%d", round),
+			"pull_request_name": "Race",
+			"author_id":         "u1",
+		}
+		prID := req["pull_request_id"].(string)
+
+		// чётные раунды гоняются за номером PR в репозитории
+		if round%2 == 0 {
+			delete(req, "pull_request_id")
+			req["repository"] = "acme/api"
+			req["number"] = round + 1
+			prID = fmt.Sprintf("acme/api#%d", round+1)
+		}
+
+		body, err := json.Marshal(req)
+
+		if err != nil {
+			t.Fatalf("failed to marshal request: %v", err)
+		}
+
+		var (
+			wg      sync.WaitGroup
+			start   = make(chan struct{})
+			results = make(chan error, parallel)
+			created = make(chan struct{}, parallel)
+		)
+
+		for i := 0; i < parallel; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				httpReq, err := http.NewRequest(http.MethodPost, env.base+"/pullRequest/create", bytes.NewReader(body))
+
+				if err != nil {
+					results <- err
+					return
+				}
+
+				httpReq.Header.Set("Content-Type", "application/json")
+				env.authorize(httpReq)
+
+				<-start
+
+				resp, err := env.client.Do(httpReq)
+
+				if err != nil {
+					results <- err
+					return
+				}
+
+				defer func() {
+					_ = resp.Body.Close()
+				}()
+
+				switch resp.StatusCode {
+				case http.StatusCreated:
+					created <- struct{}{}
+				case http.StatusConflict:
+					var errBody errorResp
+
+					if err := json.NewDecoder(resp.Body).Decode(&errBody); err != nil || errBody.Error.Code != "PR_EXISTS" {
+						results <- fmt.Errorf("%s: expected PR_EXISTS, got %+v", prID, errBody)
+					}
+				default:
+					respBody, _ := io.ReadAll(resp.Body)
+					results <- fmt.Errorf("%s: unexpected status %d: %s", prID, resp.StatusCode, respBody)
+				}
+			}()
+		}
+
+		close(start)
+		wg.Wait()
+		close(results)
+		close(created)
+
+		for err := range results {
+			t.Fatal(err)
+		}
+
+		if len(created) != 1 {
+			t.Fatalf("%s: expected exactly one create to succeed, got %d", prID, len(created))
+		}
+
+		pr, err := env.prs.GetByID(domain.WithOrganization(context.Background(), domain.DefaultOrganizationID), prID)
+
+		if err != nil {
+			t.Fatalf("get PR %s: %v", prID, err)
+		}
+
+		var assigned int
+
+		if err := env.db.QueryRow(
+			`SELECT COUNT(*) FROM review_assignment_history WHERE pr_id = $1 AND action = 'ASSIGNED'`, prID,
+		).Scan(&assigned); err != nil {
+			t.Fatalf("count history for %s: %v", prID, err)
+		}
+
+		if assigned != len(pr.AssignedReviewers) {
+			t.Fatalf("%s: expected %d assignments in history, got %d", prID, len(pr.AssignedReviewers), assigned)
+		}
+
+		want := map[string]bool{rotation[(2*round)%3]: true, rotation[(2*round+1)%3]: true}
+
+		if len(pr.AssignedReviewers) != 2 || !want[pr.AssignedReviewers[0]] || !want[pr.AssignedReviewers[1]] {
+			t.Fatalf("%s: expected reviewers %v in rotation order, got %v", prID, want, pr.AssignedReviewers)
+		}
+	}
+}
+
+// Тест на ротацию при замене: отклонённые переназначение и отказ (устаревший If-Match) не сдвигают
+// курсор ROUND_ROBIN, а успешная замена сдвигает его на нового ревьювера.
+func TestEndToEnd_RotationKeptOnFailedReplacement(t *testing.T) {
+	env := setupTestEnv(t)
+	defer env.teardown()
+
+	env.postJSON("/team/add", map[string]any{
+		"team_name": "rotation",
+		"members": []map[string]any{
+			{"user_id": "ro-a", "username": "Author", "is_active": true},
+			{"user_id": "ro-b", "username": "B", "is_active": true},
+			{"user_id": "ro-c", "username": "C", "is_active": true},
+			{"user_id": "ro-d", "username": "D", "is_active": true},
+			{"user_id": "ro-e", "username": "E", "is_active": true},
+		},
+	}, http.StatusCreated, nil)
+
+	env.postJSON("/team/setSettings", map[string]any{
+		"team_name": "rotation", "selection_strategy": "ROUND_ROBIN", "max_reviewers": 1,
+	}, http.StatusOK, nil)
+
+	env.postJSON("/pullRequest/create", map[string]any{
+		"pull_request_id": "pr-rotation", "pull_request_name": "Rotation", "author_id": "ro-a",
+	}, http.StatusCreated, nil)
+
+	cursor := func() string {
+		t.Helper()
+
+		var c sql.NullString
+
+		if err := env.db.QueryRow(`SELECT rr_cursor FROM teams WHERE team_name = 'rotation'`).Scan(&c); err != nil {
+			t.Fatalf("select cursor: %v", err)
+		}
+
+		return c.String
+	}
+
+	reviewer := mustReviewer(t, env, "pr-rotation")
+
+	if got := cursor(); got != reviewer {
+		t.Fatalf("expected cursor at %s after create, got %q", reviewer, got)
+	}
+
+	env.sendIfMatch(http.MethodPost, "/pullRequest/reassign", `"0"`,
+		map[string]any{"pull_request_id": "pr-rotation", "old_user_id": reviewer}, http.StatusPreconditionFailed)
+	env.sendIfMatch(http.MethodPost, "/pullRequest/decline", `"0"`,
+		map[string]any{"pull_request_id": "pr-rotation", "user_id": reviewer, "reason": "busy"},
+		http.StatusPreconditionFailed)
+
+	if got := cursor(); got != reviewer {
+		t.Fatalf("failed replacement moved the cursor from %s to %q", reviewer, got)
+	}
+
+	var reassigned reassignResp
+	env.postJSON("/pullRequest/reassign", map[string]any{
+		"pull_request_id": "pr-rotation", "old_user_id": reviewer,
+	}, http.StatusOK, &reassigned)
+
+	if got := cursor(); got != reassigned.ReplacedBy {
+		t.Fatalf("expected cursor at replacement %s, got %q", reassigned.ReplacedBy, got)
+	}
+}